@@ -0,0 +1,206 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/eastmoney"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/memstore"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/store/sqlite"
+)
+
+// API contract: every endpoint is served under /api/v1 and, for existing scripts and the UI,
+// under the unversioned /api alias. Responses are the typed structs below (documented in
+// openapi.go); every non-2xx /api/v1 response is an apiError, while the aliases keep the
+// pre-v1 error shapes (see apialias.go). Breaking changes go to /api/v2.
+
+const apiV1Prefix = "/api/v1"
+
+// handleAPI registers h at /api/v1<path> and at its /api<path> alias.
+func handleAPI(mux *http.ServeMux, path string, h http.HandlerFunc) {
+	mux.HandleFunc(apiV1Prefix+path, h)
+	mux.HandleFunc("/api"+path, h)
+}
+
+// apiError is the body of every error response.
+type apiError struct {
+	Error apiErrorBody `json:"error"`
+}
+
+type apiErrorBody struct {
+	// Code is a stable machine-readable identifier derived from the status (see errorCode).
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, apiError{Error: apiErrorBody{Code: errorCode(status), Message: msg}})
+}
+
+func writeMethodNotAllowed(w http.ResponseWriter) {
+	writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+}
+
+func errorCode(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return "bad_request"
+	case http.StatusUnauthorized:
+		return "unauthorized"
+	case http.StatusForbidden:
+		return "forbidden"
+	case http.StatusNotFound:
+		return "not_found"
+	case http.StatusMethodNotAllowed:
+		return "method_not_allowed"
+	case http.StatusConflict:
+		return "conflict"
+	case http.StatusBadGateway:
+		return "upstream_failed"
+	case http.StatusServiceUnavailable:
+		return "upstream_disabled"
+	default:
+		return "internal"
+	}
+}
+
+type healthResponse struct {
+	OK bool `json:"ok"`
+}
+
+type versionResponse struct {
+	LastCommitTime string `json:"last_commit_time"`
+	ReadOnly       bool   `json:"read_only"`
+	// User and Role are set when web.auth is enabled.
+	User string `json:"user,omitempty"`
+	Role string `json:"role,omitempty"`
+}
+
+type okResponse struct {
+	OK bool  `json:"ok"`
+	ID int64 `json:"id,omitempty"`
+}
+
+type watchGroupsResponse struct {
+	Groups []sqlite.WatchGroup `json:"groups"`
+}
+
+type intradayResponse[T any] struct {
+	TradeDate string `json:"trade_date"`
+	Code      string `json:"code,omitempty"`
+	Points    []T    `json:"points"`
+}
+
+type barsResponse struct {
+	Period  string       `json:"period"`
+	Dataset string       `json:"dataset"`
+	Key     string       `json:"key"`
+	Field   string       `json:"field"`
+	Bars    []sqlite.Bar `json:"bars"`
+}
+
+type dailyJobStartResponse struct {
+	OK        bool   `json:"ok"`
+	TradeDate string `json:"trade_date"`
+}
+
+type backupResponse struct {
+	OK        bool   `json:"ok"`
+	Path      string `json:"path"`
+	ElapsedMS int64  `json:"elapsed_ms"`
+	SizeBytes int64  `json:"size_bytes,omitempty"`
+	// Warning is set when the backup was written but rotating old ones failed.
+	Warning string `json:"warning,omitempty"`
+}
+
+type dailyRunsResponse struct {
+	// TradeDate and Completeness are only set when runs were requested for one date.
+	TradeDate    string             `json:"trade_date,omitempty"`
+	Runs         []sqlite.DailyRun  `json:"runs"`
+	Completeness *dailyCompleteness `json:"completeness,omitempty"`
+}
+
+type dailyCompleteness struct {
+	Total    int                   `json:"total"`
+	OK       int                   `json:"ok"`
+	Failed   []sqlite.DailyRunItem `json:"failed"`
+	Complete bool                  `json:"complete"`
+}
+
+type auctionRankResponse struct {
+	TradeDate string              `json:"trade_date"`
+	Phase     string              `json:"phase"`
+	Source    string              `json:"source"`
+	Sort      string              `json:"sort"`
+	TSUTC     string              `json:"ts_utc"`
+	Rows      []sqlite.AuctionRow `json:"rows"`
+}
+
+type velocityResponse struct {
+	TSUTC  string                `json:"ts_utc"`
+	Window string                `json:"window"`
+	Rows   []sqlite.FlowVelocity `json:"rows"`
+}
+
+type boardInfo struct {
+	Code  string  `json:"code"`
+	Name  string  `json:"name"`
+	Value float64 `json:"value"`
+	Pct   float64 `json:"pct"`
+	Price float64 `json:"price"`
+}
+
+type boardsResponse struct {
+	Rows []boardInfo `json:"rows"`
+	// FromLive is set when the rows were fetched from Eastmoney for this request.
+	FromLive bool `json:"from_live"`
+}
+
+type constituentsResponse struct {
+	Total int                   `json:"total"`
+	Rows  []eastmoney.QuoteItem `json:"rows"`
+	TSUTC time.Time             `json:"ts_utc"`
+	// Cached is set when the fetch failed and an earlier result is served; Warning holds the error.
+	Cached  bool   `json:"cached,omitempty"`
+	Warning string `json:"warning,omitempty"`
+}
+
+type boardDailyResponse struct {
+	Board  string                   `json:"board"`
+	Name   string                   `json:"name"`
+	Points []sqlite.BoardDailyPoint `json:"points"`
+	FID    string                   `json:"fid"`
+	Type   string                   `json:"type"`
+	// Warning is set when refreshing from Eastmoney failed; Points are what the database has.
+	Warning string `json:"warning,omitempty"`
+}
+
+// trendResponse is the intraday trend of a board, stock or secid; the key fields not used by
+// the endpoint are omitted.
+type trendResponse struct {
+	Board  string                 `json:"board,omitempty"`
+	Code   string                 `json:"code,omitempty"`
+	SecID  string                 `json:"secid,omitempty"`
+	Points []eastmoney.TrendPoint `json:"points"`
+	TSUTC  time.Time              `json:"ts_utc"`
+	Cached bool                   `json:"cached,omitempty"`
+}
+
+type searchResponse struct {
+	Query   string         `json:"q"`
+	Results []searchResult `json:"results"`
+	// Warning is set while the security master is still empty (first refresh pending or failed).
+	Warning string `json:"warning,omitempty"`
+}
+
+type searchResult struct {
+	sqlite.Security
+	// Symbol is the watchlist form (600519.SH); empty for boards.
+	Symbol string `json:"symbol,omitempty"`
+}
+
+// Point types of /intraday, named here for the OpenAPI document.
+type (
+	fundflowIntraday   = intradayResponse[memstore.FundflowPoint]
+	northboundIntraday = intradayResponse[memstore.NorthboundPoint]
+)
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/config"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/eastmoney"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/runtimecfg"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/store"
+)
+
+// handleAPIRoutes returns the paths passed to handleAPI in the package sources.
+func handleAPIRoutes(t *testing.T) []string {
+	t.Helper()
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fset := token.NewFileSet()
+	var routes []string
+	for _, name := range files {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, name, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) != 3 {
+				return true
+			}
+			if id, ok := call.Fun.(*ast.Ident); !ok || id.Name != "handleAPI" {
+				return true
+			}
+			lit, ok := call.Args[1].(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				t.Fatalf("%s: handleAPI with a non-literal path", fset.Position(call.Pos()))
+			}
+			p, _ := strconv.Unquote(lit.Value)
+			routes = append(routes, p)
+			return true
+		})
+	}
+	return routes
+}
+
+func TestOpenAPICoversRoutes(t *testing.T) {
+	paths := openAPISpec()["paths"].(map[string]map[string]any)
+	routes := handleAPIRoutes(t)
+	if len(routes) < 20 {
+		t.Fatalf("found only %d handleAPI routes: %v", len(routes), routes)
+	}
+	registered := make(map[string]bool)
+	for _, r := range routes {
+		if r == "/" { // catch-all 404
+			continue
+		}
+		spec := r
+		if strings.HasSuffix(r, "/") { // subtree routes take the rest of the path as a parameter
+			spec = r + "{dataset}"
+		}
+		registered[spec] = true
+		if paths[spec] == nil {
+			t.Errorf("route %s is missing from the OpenAPI document", r)
+		}
+	}
+	for p := range paths {
+		if !registered[p] {
+			t.Errorf("OpenAPI path %s has no handler", p)
+		}
+	}
+}
+
+func newTestWebServer(t *testing.T, web config.WebConfig) http.Handler {
+	t.Helper()
+	cfg := config.Config{DBPath: filepath.Join(t.TempDir(), "aof.db"), Web: web}
+	st, err := store.Open(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { st.Close() })
+	if _, err := st.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	h, err := newWebServer(runtimecfg.NewStatic(cfg), st, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func TestAPIErrorShapes(t *testing.T) {
+	h := newTestWebServer(t, config.WebConfig{})
+	cases := []struct {
+		method, path string
+		status       int
+	}{
+		{"POST", "/realtime", http.StatusMethodNotAllowed},
+		{"GET", "/board/trend", http.StatusBadRequest},
+		{"GET", "/stock/detail", http.StatusBadRequest},
+		{"GET", "/bars?period=2m", http.StatusBadRequest},
+		{"GET", "/daily/job", http.StatusNotFound},
+		{"GET", "/no/such/endpoint", http.StatusNotFound},
+	}
+	for _, tc := range cases {
+		serve := func(prefix string) *httptest.ResponseRecorder {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(tc.method, prefix+tc.path, nil))
+			if w.Code != tc.status {
+				t.Errorf("%s %s%s: status %d, want %d", tc.method, prefix, tc.path, w.Code, tc.status)
+			}
+			return w
+		}
+
+		var v1 apiError
+		w := serve(apiV1Prefix)
+		if err := json.Unmarshal(w.Body.Bytes(), &v1); err != nil || v1.Error.Code != errorCode(tc.status) || v1.Error.Message == "" {
+			t.Errorf("%s /api/v1%s: body %s is not an error envelope", tc.method, tc.path, w.Body)
+		}
+
+		var alias map[string]any
+		w = serve("/api")
+		if err := json.Unmarshal(w.Body.Bytes(), &alias); err != nil || len(alias) != 1 || alias["error"] == nil {
+			t.Errorf("%s /api%s: body %s", tc.method, tc.path, w.Body)
+			continue
+		}
+		if msg, _ := alias["error"].(string); tc.path != "/no/such/endpoint" && msg != v1.Error.Message {
+			t.Errorf("%s /api%s: error %v, want %q", tc.method, tc.path, alias["error"], v1.Error.Message)
+		}
+	}
+}
+
+func TestAliasShapes(t *testing.T) {
+	mux := http.NewServeMux()
+	partial := func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, boardDailyResponse{Board: "BK0457", Warning: "fetch failed"})
+	}
+	handleAPI(mux, "/board/daily", partial)
+	handleAPI(mux, "/stock/detail", partial)
+	// guardRequests errors happen outside the mux and are rewritten too.
+	h := aliasShapes(guardRequests(mux, nil, true))
+
+	get := func(method, path string) map[string]any {
+		t.Helper()
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		var out map[string]any
+		if err := json.Unmarshal(w.Body.Bytes(), &out); err != nil {
+			t.Fatalf("%s %s: %v (%s)", method, path, err, w.Body)
+		}
+		return out
+	}
+	if out := get("GET", "/api/board/daily"); out["error"] != "fetch failed" || out["warning"] != nil || out["board"] != "BK0457" {
+		t.Fatalf("alias partial = %v", out)
+	}
+	if out := get("GET", "/api/v1/board/daily"); out["warning"] != "fetch failed" || out["error"] != nil {
+		t.Fatalf("v1 partial = %v", out)
+	}
+	// Stock detail has always used "warning".
+	if out := get("GET", "/api/stock/detail"); out["warning"] != "fetch failed" {
+		t.Fatalf("stock detail = %v", out)
+	}
+	if out := get("POST", "/api/board/daily"); out["error"] != "server is in read-only mode" {
+		t.Fatalf("alias read-only = %v", out)
+	}
+	if out := get("POST", "/api/v1/board/daily"); out["error"].(map[string]any)["code"] != "forbidden" {
+		t.Fatalf("v1 read-only = %v", out)
+	}
+}
+
+// TestAliasLegacyErrors pins the pre-v1 bodies of upstream failures on the aliases.
+func TestAliasLegacyErrors(t *testing.T) {
+	serve := func(h http.Handler, path string, ctx context.Context) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", path, nil).WithContext(ctx))
+		return w
+	}
+
+	// A canceled request fails the upstream fetch without touching the network: 502.
+	h := newTestWebServer(t, config.WebConfig{})
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	w := serve(h, "/api/secid/trend?secid=1.000001", canceled)
+	want := "{\n  \"error\": \"context canceled\",\n  \"secid\": \"1.000001\"\n}\n"
+	if w.Code != http.StatusBadGateway || w.Body.String() != want {
+		t.Fatalf("alias secid trend: %d %q, want 502 %q", w.Code, w.Body, want)
+	}
+	w = serve(h, "/api/v1/secid/trend?secid=1.000001", canceled)
+	var v1 apiError
+	if err := json.Unmarshal(w.Body.Bytes(), &v1); err != nil || w.Code != http.StatusBadGateway ||
+		v1.Error.Code != "upstream_failed" || v1.Error.Message != "context canceled" {
+		t.Fatalf("v1 secid trend: %d %s", w.Code, w.Body)
+	}
+
+	// Read-only mode refuses upstream fetches; the constituents error keeps all its old fields.
+	h = newTestWebServer(t, config.WebConfig{ReadOnly: true})
+	w = serve(h, "/api/board/constituents?board=BK0457&pz=20", context.Background())
+	var got map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil || w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("alias constituents: %d %s", w.Code, w.Body)
+	}
+	ts, _ := got["ts_utc"].(string)
+	if _, err := time.Parse(time.RFC3339Nano, ts); err != nil {
+		t.Fatalf("ts_utc %v: %v", got["ts_utc"], err)
+	}
+	delete(got, "ts_utc")
+	wantFields := map[string]any{
+		"error":   eastmoney.ErrOffline.Error(),
+		"message": "board constituents fetch failed",
+		"board":   "BK0457",
+		"pn":      float64(1),
+		"pz":      float64(20),
+	}
+	if !reflect.DeepEqual(got, wantFields) {
+		t.Fatalf("alias constituents = %v, want %v", got, wantFields)
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// The unversioned /api aliases keep the response shapes scripts saw before /api/v1: an error is
+// {"error": "<message>"} instead of the apiError envelope, and the partial results listed in
+// aliasPartialAsError report the failed refresh in "error" rather than "warning". Handlers always
+// write the v1 shape; aliasShapes rewrites it on the way out.
+
+// A few failures carried extra fields before v1 (the board or secid, the constituents paging);
+// handlers report those with writeLegacyError so the alias answers with the old body verbatim.
+
+// aliasPartialAsError are the alias routes whose partial results used "error" before v1.
+var aliasPartialAsError = map[string]bool{
+	"/api/board/constituents": true,
+	"/api/board/daily":        true,
+}
+
+func isAPIAlias(p string) bool {
+	return strings.HasPrefix(p, "/api/") && p != apiV1Prefix && !strings.HasPrefix(p, apiV1Prefix+"/")
+}
+
+// aliasShapes rewrites JSON responses on the /api aliases (including errors from guardRequests)
+// to their pre-v1 shapes.
+func aliasShapes(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isAPIAlias(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		aw := &aliasWriter{ResponseWriter: w, partial: aliasPartialAsError[r.URL.Path]}
+		next.ServeHTTP(aw, r)
+		aw.finish()
+	})
+}
+
+// aliasWriter buffers the JSON bodies it may rewrite; everything else (streams, WebSocket
+// upgrades, exports, successful responses) passes straight through.
+type aliasWriter struct {
+	http.ResponseWriter
+	partial   bool
+	legacy    map[string]any // set by writeLegacyError
+	status    int
+	buffering bool
+	buf       bytes.Buffer
+}
+
+func (w *aliasWriter) WriteHeader(status int) {
+	if w.status != 0 {
+		return
+	}
+	w.status = status
+	isJSON := strings.HasPrefix(w.Header().Get("Content-Type"), "application/json")
+	w.buffering = isJSON && (status >= 400 || w.partial)
+	if !w.buffering {
+		w.ResponseWriter.WriteHeader(status)
+	}
+}
+
+func (w *aliasWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.buffering {
+		return w.buf.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *aliasWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok && !w.buffering {
+		f.Flush()
+	}
+}
+
+func (w *aliasWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("hijacking not supported")
+	}
+	return h.Hijack()
+}
+
+func (w *aliasWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+// finish writes the buffered body, rewritten if it has the v1 shape.
+func (w *aliasWriter) finish() {
+	if !w.buffering {
+		return
+	}
+	body := w.buf.Bytes()
+	if w.legacy != nil && w.status >= 400 {
+		if out, err := json.MarshalIndent(w.legacy, "", "  "); err == nil {
+			body = append(out, '\n')
+		}
+	} else if out, ok := aliasBody(body, w.status >= 400); ok {
+		body = out
+	}
+	w.Header().Del("Content-Length")
+	w.ResponseWriter.WriteHeader(w.status)
+	_, _ = w.ResponseWriter.Write(body)
+}
+
+// writeLegacyError writes msg as an apiError; on an /api alias the response body is legacy instead,
+// the route's pre-v1 error. Handlers receive the aliasWriter directly (guardRequests and the mux
+// pass w through), so no other wrapper may sit between aliasShapes and the handlers.
+func writeLegacyError(w http.ResponseWriter, status int, msg string, legacy map[string]any) {
+	if aw, ok := w.(*aliasWriter); ok {
+		aw.legacy = legacy
+	}
+	writeError(w, status, msg)
+}
+
+// aliasBody converts an apiError (isErr) or a "warning" field to the pre-v1 shape.
+func aliasBody(body []byte, isErr bool) ([]byte, bool) {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(body, &obj); err != nil {
+		return nil, false
+	}
+	if isErr {
+		var e apiErrorBody
+		if err := json.Unmarshal(obj["error"], &e); err != nil || e.Message == "" {
+			return nil, false
+		}
+		msg, _ := json.Marshal(e.Message)
+		obj = map[string]json.RawMessage{"error": msg}
+	} else {
+		warning, ok := obj["warning"]
+		if !ok {
+			return nil, false
+		}
+		delete(obj, "warning")
+		obj["error"] = warning
+	}
+	out, err := json.MarshalIndent(obj, "", "  ")
+	if err != nil {
+		return nil, false
+	}
+	return append(out, '\n'), true
+}
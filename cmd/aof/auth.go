@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/auth"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/eastmoney"
+)
+
+// guardRequests enforces web.auth and web.read_only in front of every handler. Reads (GET/HEAD)
+// need any role, everything else needs admin and is refused outright in read-only mode.
+// /api/health (and /api/v1/health) stays open for load balancers and uptime checks.
+// Browsers resend basic-auth credentials on requests another site triggers, so writes whose
+// Origin or Sec-Fetch-Site shows a different origin are refused before anything else (CSRF).
+func guardRequests(next http.Handler, authn *auth.Authenticator, readOnly bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/health" || r.URL.Path == apiV1Prefix+"/health" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		read := r.Method == http.MethodGet || r.Method == http.MethodHead
+		if !read && crossSite(r) {
+			log.Printf("cross-site request refused: %s %s from %s (origin %q)", r.Method, r.URL.Path, r.RemoteAddr, r.Header.Get("Origin"))
+			writeError(w, http.StatusForbidden, "cross-site request refused")
+			return
+		}
+		if authn != nil {
+			p, ok := authn.Check(r)
+			if !ok {
+				if r.Header.Get("Authorization") != "" {
+					log.Printf("auth failed: %s %s from %s", r.Method, r.URL.Path, r.RemoteAddr)
+				}
+				w.Header().Set("WWW-Authenticate", `Basic realm="aof", charset="UTF-8"`)
+				writeError(w, http.StatusUnauthorized, "authentication required")
+				return
+			}
+			if !read && !p.CanWrite() {
+				writeError(w, http.StatusForbidden, "admin role required")
+				return
+			}
+			r = r.WithContext(auth.WithPrincipal(r.Context(), p))
+		}
+		if readOnly && !read {
+			writeError(w, http.StatusForbidden, "server is in read-only mode")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// crossSite reports whether a browser sent r on behalf of another origin. Requests without
+// either header (curl, scripts, older browsers on same-origin GETs) are not cross-site.
+func crossSite(r *http.Request) bool {
+	switch r.Header.Get("Sec-Fetch-Site") {
+	case "", "same-origin", "none":
+	default:
+		return true
+	}
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return false
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" { // includes the opaque "null" origin
+		return true
+	}
+	return !strings.EqualFold(u.Host, r.Host)
+}
+
+// upstreamStatus is the status for a failed Eastmoney fetch: 503 when fetches are disabled
+// (read-only mode), else 502.
+func upstreamStatus(err error) int {
+	if errors.Is(err, eastmoney.ErrOffline) {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusBadGateway
+}
+
+// runAuthCommand implements `aof auth hash-password|new-token`, printing values for web.auth.
+func runAuthCommand(args []string) error {
+	fs := flag.NewFlagSet("auth", flag.ExitOnError)
+	_ = fs.Parse(args)
+
+	switch fs.Arg(0) {
+	case "hash-password":
+		// Read from stdin so the password doesn't end up in shell history.
+		fmt.Fprint(os.Stderr, "password: ")
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return err
+		}
+		password := strings.TrimRight(line, "\r\n")
+		if password == "" {
+			return fmt.Errorf("empty password")
+		}
+		hash, err := auth.HashPassword(password)
+		if err != nil {
+			return err
+		}
+		fmt.Println(hash)
+	case "new-token":
+		token, sum, err := auth.NewToken()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("token:  %s\n", token)
+		fmt.Printf("sha256: %s\n", sum)
+	default:
+		usage()
+		os.Exit(2)
+	}
+	return nil
+}
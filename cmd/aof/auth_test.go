@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/auth"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/config"
+)
+
+func TestGuardRequests(t *testing.T) {
+	hash, err := auth.HashPassword("s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, sum, err := auth.NewToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	authn, err := auth.New(config.WebAuth{
+		Enabled: true,
+		Users:   []config.WebUser{{Name: "alice", PasswordHash: hash, Role: auth.RoleAdmin}},
+		Tokens:  []config.WebToken{{Name: "grafana", SHA256: sum, Role: auth.RoleViewer}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The handlers answer with the principal guardRequests put on the context ("" without auth).
+	mux := http.NewServeMux()
+	whoami := func(w http.ResponseWriter, r *http.Request) {
+		p, _ := auth.FromContext(r.Context())
+		_, _ = w.Write([]byte(p.Name))
+	}
+	handleAPI(mux, "/health", whoami)
+	handleAPI(mux, "/settings", whoami)
+	servers := map[string]http.Handler{
+		"auth":           guardRequests(mux, authn, false),
+		"auth read-only": guardRequests(mux, authn, true),
+		"read-only":      guardRequests(mux, nil, true),
+		"open":           guardRequests(mux, nil, false),
+	}
+
+	const (
+		anon   = ""
+		viewer = "viewer"
+		admin  = "admin"
+		wrong  = "wrong"
+		// A form or fetch from another site: the browser attaches the cached credentials.
+		crossAdmin = "admin from another site"
+		crossAnon  = "anon from another site"
+		sameAdmin  = "admin from the dashboard"
+	)
+	cases := []struct {
+		server, method, path, who string
+		status                    int
+		body                      string
+	}{
+		{"auth", "GET", "/api/v1/settings", anon, http.StatusUnauthorized, ""},
+		{"auth", "GET", "/api/v1/settings", wrong, http.StatusUnauthorized, ""},
+		{"auth", "GET", "/api/v1/settings", viewer, http.StatusOK, "grafana"},
+		{"auth", "HEAD", "/api/v1/settings", viewer, http.StatusOK, "grafana"},
+		{"auth", "POST", "/api/v1/settings", viewer, http.StatusForbidden, ""},
+		{"auth", "GET", "/api/v1/settings", admin, http.StatusOK, "alice"},
+		{"auth", "POST", "/api/v1/settings", admin, http.StatusOK, "alice"},
+		{"auth", "POST", "/api/settings", anon, http.StatusUnauthorized, ""},
+		{"auth", "GET", "/api/health", anon, http.StatusOK, ""},
+		{"auth", "GET", "/api/v1/health", wrong, http.StatusOK, ""},
+		{"auth read-only", "GET", "/api/v1/settings", viewer, http.StatusOK, "grafana"},
+		{"auth read-only", "POST", "/api/v1/settings", admin, http.StatusForbidden, ""},
+		{"auth read-only", "POST", "/api/v1/settings", anon, http.StatusUnauthorized, ""},
+		{"read-only", "GET", "/api/v1/settings", anon, http.StatusOK, ""},
+		{"read-only", "DELETE", "/api/v1/settings", anon, http.StatusForbidden, ""},
+		{"open", "POST", "/api/v1/settings", anon, http.StatusOK, ""},
+		{"auth", "POST", "/api/v1/settings", crossAdmin, http.StatusForbidden, ""},
+		{"auth", "GET", "/api/v1/settings", crossAdmin, http.StatusOK, "alice"},
+		{"auth", "POST", "/api/v1/settings", sameAdmin, http.StatusOK, "alice"},
+		{"open", "POST", "/api/v1/settings", crossAnon, http.StatusForbidden, ""},
+	}
+	for _, tc := range cases {
+		r := httptest.NewRequest(tc.method, tc.path, nil)
+		switch tc.who {
+		case viewer:
+			r.Header.Set("Authorization", "Bearer "+token)
+		case admin:
+			r.SetBasicAuth("alice", "s3cret")
+		case wrong:
+			r.SetBasicAuth("alice", "nope")
+		case crossAdmin:
+			r.SetBasicAuth("alice", "s3cret")
+			r.Header.Set("Origin", "https://evil.example")
+			r.Header.Set("Sec-Fetch-Site", "cross-site")
+		case crossAnon:
+			r.Header.Set("Origin", "https://evil.example") // no Sec-Fetch-Site: older browsers
+		case sameAdmin:
+			r.SetBasicAuth("alice", "s3cret")
+			r.Header.Set("Origin", "http://"+r.Host)
+			r.Header.Set("Sec-Fetch-Site", "same-origin")
+		}
+		w := httptest.NewRecorder()
+		servers[tc.server].ServeHTTP(w, r)
+
+		name := tc.server + " " + tc.method + " " + tc.path + " as " + tc.who
+		if w.Code != tc.status {
+			t.Errorf("%s: status %d, want %d (%s)", name, w.Code, tc.status, w.Body)
+			continue
+		}
+		challenge := w.Header().Get("WWW-Authenticate")
+		if (tc.status == http.StatusUnauthorized) != (challenge != "") {
+			t.Errorf("%s: WWW-Authenticate %q", name, challenge)
+		}
+		if tc.status == http.StatusOK {
+			if got := w.Body.String(); got != tc.body {
+				t.Errorf("%s: principal %q, want %q", name, got, tc.body)
+			}
+			continue
+		}
+		var e apiError
+		if err := json.Unmarshal(w.Body.Bytes(), &e); err != nil || e.Error.Code != errorCode(tc.status) {
+			t.Errorf("%s: error body %s", name, w.Body)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/collector"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/config"
+)
+
+// dailyRunner is the part of *collector.Collector a dailyJob drives.
+type dailyRunner interface {
+	RunDaily(ctx context.Context, date time.Time, trigger string) (collector.DailyReport, error)
+	RetryDaily(ctx context.Context, date time.Time, rep collector.DailyReport) (collector.DailyReport, error)
+}
+
+// dailyJob runs the after-close daily snapshot inside a long-running process and keeps
+// the outcome of the last run for the web UI.
+type dailyJob struct {
+	c dailyRunner
+
+	mu         sync.Mutex
+	running    bool
+	trigger    string
+	attempts   int
+	startedAt  time.Time
+	finishedAt time.Time
+	nextRunAt  time.Time
+	report     *collector.DailyReport
+}
+
+type dailyJobStatus struct {
+	Running    bool                   `json:"running"`
+	Trigger    string                 `json:"trigger,omitempty"`
+	Attempts   int                    `json:"attempts"`
+	StartedAt  string                 `json:"started_at"`
+	FinishedAt string                 `json:"finished_at"`
+	NextRunAt  string                 `json:"next_run_at"`
+	Report     *collector.DailyReport `json:"report,omitempty"`
+}
+
+func newDailyJob(c dailyRunner) *dailyJob {
+	return &dailyJob{c: c}
+}
+
+// Run executes a full daily run for date, then retries failed items up to cfg.RetryAttempts times.
+// It returns false without doing anything if a run is already in progress.
+func (j *dailyJob) Run(ctx context.Context, date time.Time, cfg config.DailyJobConfig, trigger string) bool {
+	j.mu.Lock()
+	if j.running {
+		j.mu.Unlock()
+		return false
+	}
+	j.running = true
+	j.trigger = trigger
+	j.attempts = 1
+	j.startedAt = time.Now().UTC()
+	j.finishedAt = time.Time{}
+	j.report = nil
+	j.mu.Unlock()
+
+	rep, err := j.c.RunDaily(ctx, date, trigger)
+	j.setReport(rep)
+	retries := *cfg.RetryAttempts
+	for attempt := 1; err != nil && attempt <= retries && len(rep.Failed) > 0; attempt++ {
+		log.Printf("daily job: %v; retry %d/%d in %ds", err, attempt, retries, cfg.RetryDelaySeconds)
+		select {
+		case <-ctx.Done():
+			attempt = retries
+			continue
+		case <-time.After(time.Duration(cfg.RetryDelaySeconds) * time.Second):
+		}
+		rep, err = j.c.RetryDaily(ctx, date, rep)
+		j.mu.Lock()
+		j.attempts++
+		j.mu.Unlock()
+		j.setReport(rep)
+	}
+	if err != nil {
+		log.Printf("daily job finished with failures: %v", err)
+	}
+
+	j.mu.Lock()
+	j.running = false
+	j.finishedAt = time.Now().UTC()
+	j.mu.Unlock()
+	return true
+}
+
+func (j *dailyJob) setReport(rep collector.DailyReport) {
+	j.mu.Lock()
+	j.report = &rep
+	j.mu.Unlock()
+}
+
+func (j *dailyJob) setNextRun(t time.Time) {
+	j.mu.Lock()
+	j.nextRunAt = t
+	j.mu.Unlock()
+}
+
+func (j *dailyJob) Status() dailyJobStatus {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	var rep *collector.DailyReport
+	if j.report != nil {
+		tmp := *j.report
+		tmp.Failed = append([]collector.DailyFailure(nil), j.report.Failed...)
+		rep = &tmp
+	}
+	return dailyJobStatus{
+		Running:    j.running,
+		Trigger:    j.trigger,
+		Attempts:   j.attempts,
+		StartedAt:  formatRFC3339Nano(j.startedAt),
+		FinishedAt: formatRFC3339Nano(j.finishedAt),
+		NextRunAt:  formatRFC3339Nano(j.nextRunAt),
+		Report:     rep,
+	}
+}
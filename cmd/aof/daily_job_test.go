@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/collector"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/config"
+)
+
+func TestDailyDue(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skip(err)
+	}
+	at := func(day, hm string) time.Time {
+		v, err := time.ParseInLocation("2006-01-02 15:04", day+" "+hm, loc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	cases := []struct {
+		name    string
+		now     time.Time
+		lastRun string
+		want    bool
+	}{
+		{"weekday before run_at", at("2024-01-05", "15:20"), "", false},
+		{"weekday after run_at", at("2024-01-05", "15:31"), "", true},
+		{"already ran today", at("2024-01-05", "16:00"), "2024-01-05", false},
+		{"ran yesterday", at("2024-01-05", "16:00"), "2024-01-04", true},
+		{"saturday", at("2024-01-06", "16:00"), "", false},
+		{"sunday", at("2024-01-07", "16:00"), "2024-01-05", false},
+	}
+	for _, tc := range cases {
+		runAt := nextRunTimeToday(tc.now, "15:30")
+		if got := dailyDue(tc.now, runAt, tc.lastRun); got != tc.want {
+			t.Errorf("%s: dailyDue=%v want %v", tc.name, got, tc.want)
+		}
+	}
+
+	// Friday after the run: next is Monday; Friday morning: today.
+	fri := at("2024-01-05", "16:00")
+	if got := nextDailyRunAt(fri, nextRunTimeToday(fri, "15:30"), true); !got.Equal(at("2024-01-08", "15:30")) {
+		t.Fatalf("next after friday run=%v", got)
+	}
+	morning := at("2024-01-05", "09:00")
+	if got := nextDailyRunAt(morning, nextRunTimeToday(morning, "15:30"), false); !got.Equal(at("2024-01-05", "15:30")) {
+		t.Fatalf("next friday morning=%v", got)
+	}
+	if got := nextRunTimeToday(morning, "bad"); got.Hour() != 3 || got.Minute() != 10 {
+		t.Fatalf("invalid run_at=%v", got)
+	}
+}
+
+// blockingRunner fails one item on the first run and succeeds on retry; RunDaily blocks until release.
+type blockingRunner struct {
+	started chan struct{}
+	release chan struct{}
+	retries int
+}
+
+func (r *blockingRunner) RunDaily(ctx context.Context, date time.Time, trigger string) (collector.DailyReport, error) {
+	r.started <- struct{}{}
+	<-r.release
+	rep := collector.DailyReport{Total: 2, OK: 1, Failed: []collector.DailyFailure{
+		{DailyItem: collector.DailyItem{Dataset: "margin"}, Err: "timeout"},
+	}}
+	return rep, errors.New("1 item failed")
+}
+
+func (r *blockingRunner) RetryDaily(ctx context.Context, date time.Time, rep collector.DailyReport) (collector.DailyReport, error) {
+	r.retries++
+	rep.OK, rep.Failed = rep.Total, nil
+	return rep, nil
+}
+
+func TestDailyJobRunGuard(t *testing.T) {
+	r := &blockingRunner{started: make(chan struct{}), release: make(chan struct{})}
+	job := newDailyJob(r)
+	retries := 2
+	cfg := config.DailyJobConfig{RetryAttempts: &retries}
+
+	done := make(chan bool)
+	go func() { done <- job.Run(context.Background(), time.Now(), cfg, "schedule") }()
+	<-r.started
+
+	if !job.Status().Running {
+		t.Fatal("status not running during a run")
+	}
+	if job.Run(context.Background(), time.Now(), cfg, "manual") {
+		t.Fatal("second run started while the first was in progress")
+	}
+	close(r.release)
+	if !<-done {
+		t.Fatal("first run reported not started")
+	}
+
+	st := job.Status()
+	if st.Running || st.Trigger != "schedule" || st.Attempts != 2 || r.retries != 1 {
+		t.Fatalf("status=%+v retries=%d", st, r.retries)
+	}
+	if st.Report == nil || len(st.Report.Failed) != 0 || st.Report.OK != 2 {
+		t.Fatalf("report=%+v", st.Report)
+	}
+
+	// The guard is released once the run finishes.
+	go func() { done <- job.Run(context.Background(), time.Now(), cfg, "manual") }()
+	<-r.started
+	if !<-done {
+		t.Fatal("run after completion refused")
+	}
+}
+
+func TestDailyJobRetriesDisabled(t *testing.T) {
+	cfg := config.Config{DBPath: "aof.db"}
+	zero := 0
+	cfg.DailyJob.RetryAttempts = &zero
+	if err := config.NormalizeAndValidate(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	if *cfg.DailyJob.RetryAttempts != 0 {
+		t.Fatalf("retry_attempts: 0 became %d", *cfg.DailyJob.RetryAttempts)
+	}
+	unset := config.Config{DBPath: "aof.db"}
+	if err := config.NormalizeAndValidate(&unset); err != nil || *unset.DailyJob.RetryAttempts != 2 {
+		t.Fatalf("default retry_attempts err=%v", err)
+	}
+
+	r := &blockingRunner{started: make(chan struct{}), release: make(chan struct{})}
+	close(r.release)
+	job := newDailyJob(r)
+	done := make(chan bool)
+	go func() { done <- job.Run(context.Background(), time.Now(), cfg.DailyJob, "manual") }()
+	<-r.started
+	<-done
+	if st := job.Status(); st.Attempts != 1 || r.retries != 0 || st.Report == nil || len(st.Report.Failed) != 1 {
+		t.Fatalf("status=%+v retries=%d", st, r.retries)
+	}
+}
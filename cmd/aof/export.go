@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/config"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/export"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/store"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/store/sqlite"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/symbol"
+)
+
+// exportRequest is one history export, from /api/v1/export/<dataset> or `aof export`.
+type exportRequest struct {
+	sqlite.ExportQuery
+	Format string // csv | xlsx
+	ZH     bool   // Chinese column headers
+}
+
+func (e *exportRequest) validate() error {
+	if e.Format == "" {
+		e.Format = "csv"
+	}
+	if e.Format != "csv" && e.Format != "xlsx" {
+		return fmt.Errorf("format must be csv or xlsx")
+	}
+	for _, d := range []string{e.From, e.To} {
+		if d == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", d); err != nil {
+			return fmt.Errorf("dates must be YYYY-MM-DD: %q", d)
+		}
+	}
+	if e.From != "" && e.To != "" && e.From > e.To {
+		return fmt.Errorf("from %s is after to %s", e.From, e.To)
+	}
+	if e.BoardType != "" && e.BoardType != "industry" && e.BoardType != "concept" {
+		return fmt.Errorf("type must be industry or concept")
+	}
+	if e.Dataset == "fundflow" {
+		// fundflow_daily keys stocks by bare code; accept 600519.SH as well.
+		for i, c := range e.Codes {
+			if code, err := symbol.CodeOnly(c); err == nil {
+				e.Codes[i] = code
+			}
+		}
+	}
+	return nil
+}
+
+// filename is e.g. fundflow_2024-01-01_2024-03-31.xlsx.
+func (e exportRequest) filename() string {
+	parts := []string{e.Dataset}
+	if e.From != "" || e.To != "" {
+		parts = append(parts, e.From, e.To)
+	}
+	return strings.Join(parts, "_") + "." + e.Format
+}
+
+// splitCodes splits comma or whitespace separated codes, dropping empties and duplicates.
+func splitCodes(s string) []string {
+	var out []string
+	seen := make(map[string]struct{})
+	for _, c := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' || r == '\n' }) {
+		if _, dup := seen[c]; !dup {
+			seen[c] = struct{}{}
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func parseExportRequest(dataset string, q url.Values) exportRequest {
+	return exportRequest{
+		ExportQuery: sqlite.ExportQuery{
+			Dataset:   dataset,
+			Codes:     splitCodes(strings.Join(q["codes"], ",")),
+			FID:       strings.TrimSpace(q.Get("fid")),
+			BoardType: strings.TrimSpace(q.Get("type")),
+			From:      strings.TrimSpace(q.Get("from")),
+			To:        strings.TrimSpace(q.Get("to")),
+		},
+		Format: strings.ToLower(strings.TrimSpace(q.Get("format"))),
+		ZH:     q.Get("headers") == "zh",
+	}
+}
+
+// newExportHandler serves GET /api/v1/export/<dataset>?format=csv|xlsx&codes=&from=&to=&headers=zh.
+func newExportHandler(st store.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeMethodNotAllowed(w)
+			return
+		}
+		dataset := r.URL.Path[strings.LastIndex(r.URL.Path, "/export/")+len("/export/"):]
+		e := parseExportRequest(dataset, r.URL.Query())
+		if err := e.validate(); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		t, err := st.QueryExport(e.ExportQuery)
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, sqlite.ErrInvalidExport) {
+				status = http.StatusBadRequest
+			}
+			writeError(w, status, err.Error())
+			return
+		}
+		// Buffer the file so a write error can still be reported as JSON.
+		var buf bytes.Buffer
+		if err := export.Write(&buf, e.Format, t, e.ZH, e.Dataset); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", export.ContentType(e.Format))
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": e.filename()}))
+		_, _ = w.Write(buf.Bytes())
+	}
+}
+
+// runExportCommand implements `aof export`, writing the same files as /api/v1/export.
+func runExportCommand(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	cfgPath := fs.String("config", "configs/config.yaml", "config path (YAML)")
+	dataset := fs.String("dataset", "", "one of "+strings.Join(sqlite.ExportDatasets(), ", "))
+	codes := fs.String("codes", "", "comma-separated sources, board types or codes (default: all)")
+	from := fs.String("from", "", "first trade date (YYYY-MM-DD)")
+	to := fs.String("to", "", "last trade date (YYYY-MM-DD)")
+	fid := fs.String("fid", "", "flow field for every dataset but fundflow/northbound (default f62)")
+	boardType := fs.String("type", "", "board_daily: industry or concept (default both)")
+	format := fs.String("format", "", "csv or xlsx (default: from -out extension, else csv)")
+	zh := fs.Bool("zh", false, "Chinese column headers")
+	out := fs.String("out", "", "output file (default: stdout)")
+	_ = fs.Parse(args)
+
+	e := exportRequest{
+		ExportQuery: sqlite.ExportQuery{
+			Dataset: *dataset, Codes: splitCodes(*codes), FID: *fid, BoardType: *boardType, From: *from, To: *to,
+		},
+		Format: strings.ToLower(*format),
+		ZH:     *zh,
+	}
+	if e.Format == "" && strings.HasSuffix(strings.ToLower(*out), ".xlsx") {
+		e.Format = "xlsx"
+	}
+	if err := e.validate(); err != nil {
+		return err
+	}
+
+	cfg, err := config.Load(*cfgPath)
+	if err != nil {
+		return err
+	}
+	st, err := store.Open(cfg)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+	t, err := st.QueryExport(e.ExportQuery)
+	if err != nil {
+		return err
+	}
+
+	if *out == "" {
+		return export.Write(os.Stdout, e.Format, t, e.ZH, e.Dataset)
+	}
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	if err := export.Write(f, e.Format, t, e.ZH, e.Dataset); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	log.Printf("exported %d rows to %s", len(t.Rows), *out)
+	return nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/config"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/market"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/memstore"
+)
+
+// datasetIntervals maps every dataset key the collector is configured to fetch to its fetch interval.
+func datasetIntervals(cfg config.Config) map[string]time.Duration {
+	sec := func(n, def int) time.Duration {
+		if n <= 0 {
+			n = def
+		}
+		return time.Duration(n) * time.Second
+	}
+	rt := sec(cfg.Realtime.IntervalSeconds, 10)
+	out := map[string]time.Duration{
+		memstore.DatasetKeyNorthbound:               rt,
+		memstore.DatasetKeyToplist(cfg.Toplist.FID): rt,
+	}
+	if len(cfg.Watchlist) > 0 {
+		out[memstore.DatasetKeyFundflow] = rt
+	}
+	if cfg.Industry.Enabled {
+		d := sec(cfg.Industry.IntervalSeconds, 60)
+		out[memstore.DatasetKeyBoard("industry", cfg.Industry.FID)] = d
+		out[memstore.DatasetKeyAgg("industry_sum", cfg.Industry.FID)] = d
+	}
+	if cfg.Concept.Enabled {
+		out[memstore.DatasetKeyBoard("concept", cfg.Concept.FID)] = sec(cfg.Concept.IntervalSeconds, 60)
+	}
+	if cfg.MarketAgg.Enabled {
+		out[memstore.DatasetKeyAgg("allstocks_sum", cfg.MarketAgg.FID)] = sec(cfg.MarketAgg.IntervalSeconds, 60)
+	}
+	return out
+}
+
+// isStale reports whether a dataset fetched every interval missed its refresh at now:
+// never fetched, more than two intervals old, or its last fetch attempt failed.
+func isStale(m memstore.DatasetMeta, interval time.Duration, now time.Time) bool {
+	age := m.Age(now)
+	return age < 0 || age > 2*interval || m.Failing()
+}
+
+// staleDatasets lists the configured dataset keys that are stale in meta. Outside trading hours
+// nothing updates upstream, so the last fetch is as good as it gets and nothing is reported.
+func staleDatasets(meta map[string]memstore.DatasetMeta, cfg config.Config, now time.Time) []string {
+	if !market.IsCNTradingTime(now) {
+		return nil
+	}
+	var out []string
+	for key, interval := range datasetIntervals(cfg) {
+		if isStale(meta[key], interval, now) {
+			out = append(out, key)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
@@ -2,13 +2,14 @@ package main
 
 import (
 	"context"
-	"database/sql"
 	"log"
 	"time"
 
 	"github.com/pcdogyu/A-Stock-Order-Flow/internal/collector"
 	"github.com/pcdogyu/A-Stock-Order-Flow/internal/config"
-	"github.com/pcdogyu/A-Stock-Order-Flow/internal/store/sqlite"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/market"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/memstore"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/store"
 )
 
 type cfgProvider interface {
@@ -40,7 +41,31 @@ func runPersistLoop(ctx context.Context, cfgp cfgProvider, c *collector.Collecto
 	}
 }
 
-func runCleanupLoop(ctx context.Context, cfgp cfgProvider, db *sql.DB) {
+// runFollowLoop keeps the memory of a read-only server in step with the snapshots another process
+// (aof rt, or a collecting aof web on the same database) persists. lastID was already applied.
+func runFollowLoop(ctx context.Context, cfgp cfgProvider, st store.Store, mem *memstore.Store, lastID int64) {
+	for {
+		snap, ok, err := st.LoadLatestRTSnapshot()
+		if err != nil {
+			log.Printf("follow db err: %v", err)
+		} else if ok && snap.ID != lastID {
+			applyRTSnapshot(mem, snap)
+			lastID = snap.ID
+		}
+
+		interval := cfgp.Get().Persist.IntervalSeconds
+		if interval <= 0 {
+			interval = 60
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Duration(interval) * time.Second):
+		}
+	}
+}
+
+func runCleanupLoop(ctx context.Context, cfgp cfgProvider, st store.Store) {
 	loc, _ := time.LoadLocation("Asia/Shanghai")
 	var lastRunDay string
 
@@ -63,10 +88,11 @@ func runCleanupLoop(ctx context.Context, cfgp cfgProvider, db *sql.DB) {
 		now := time.Now().In(loc)
 		today := now.Format("2006-01-02")
 		if lastRunDay != today && now.After(nextRunTimeToday(now, cfg.Cleanup.RunAt)) {
-			if err := sqlite.CleanupOldData(db, time.Now().UTC(), cfg.RetentionDays); err != nil {
+			if err := collector.Housekeeping(st, cfg, time.Now().UTC()); err != nil {
 				log.Printf("cleanup err: %v", err)
 			} else {
-				log.Printf("cleanup ok: retention_days=%d", cfg.RetentionDays)
+				log.Printf("cleanup ok: retention_days=%d raw_days=%d bars_1m_days=%d bars_5m_days=%d",
+					cfg.RetentionDays, cfg.Retention.RawDays, cfg.Retention.Bars1mDays, cfg.Retention.Bars5mDays)
 			}
 			lastRunDay = today
 		}
@@ -80,6 +106,123 @@ func runCleanupLoop(ctx context.Context, cfgp cfgProvider, db *sql.DB) {
 	}
 }
 
+// runBackupLoop writes a rotated online backup once a day at backup.run_at when enabled.
+func runBackupLoop(ctx context.Context, cfgp cfgProvider, st store.Store) {
+	loc, _ := time.LoadLocation("Asia/Shanghai")
+	var lastRunDay string
+
+	for {
+		cfg := cfgp.Get()
+		now := time.Now().In(loc)
+		today := now.Format("2006-01-02")
+		if *cfg.Backup.Enabled && lastRunDay != today && now.After(nextRunTimeToday(now, cfg.Backup.RunAt)) {
+			if path, err := collector.Backup(st, cfg.Backup.Dir, cfg.Backup.Keep, time.Now().UTC()); err != nil {
+				log.Printf("backup err: %v", err)
+			} else {
+				log.Printf("backup ok: %s (keep=%d)", path, cfg.Backup.Keep)
+			}
+			lastRunDay = today
+		}
+
+		// Tick at 1-minute granularity; this is a once-per-day job.
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(1 * time.Minute):
+		}
+	}
+}
+
+// runSecuritiesLoop refreshes the security master once a day at securities.run_at, and on start
+// while it is empty (retrying every 15 minutes until a refresh writes something).
+func runSecuritiesLoop(ctx context.Context, cfgp cfgProvider, st store.Store, c *collector.Collector) {
+	loc, _ := time.LoadLocation("Asia/Shanghai")
+	var lastRunDay string
+	var retryAt time.Time
+	if n, ts, err := st.QuerySecuritiesUpdated(); err == nil && n > 0 {
+		if t, err := time.Parse(time.RFC3339Nano, ts); err == nil {
+			lastRunDay = t.In(loc).Format("2006-01-02")
+		}
+	}
+
+	for {
+		cfg := cfgp.Get()
+		now := time.Now().In(loc)
+		today := now.Format("2006-01-02")
+		due := lastRunDay != today && (lastRunDay == "" || now.After(nextRunTimeToday(now, cfg.Securities.RunAt)))
+		if *cfg.Securities.Enabled && due && now.After(retryAt) {
+			n, err := c.RefreshSecurities(ctx)
+			if err != nil {
+				log.Printf("securities refresh err: %v", err)
+			}
+			if n > 0 {
+				log.Printf("securities refresh ok: %d rows", n)
+				lastRunDay = today
+			} else {
+				retryAt = now.Add(15 * time.Minute)
+			}
+		}
+
+		// Tick at 1-minute granularity; this is a once-per-day job.
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(1 * time.Minute):
+		}
+	}
+}
+
+// runDailyLoop runs the after-close daily snapshot once per trading day at daily_job.run_at.
+func runDailyLoop(ctx context.Context, cfgp cfgProvider, job *dailyJob) {
+	loc, _ := time.LoadLocation("Asia/Shanghai")
+	var lastRunDay string
+
+	for {
+		cfg := cfgp.Get()
+		enabled := cfg.DailyJob.Enabled == nil || *cfg.DailyJob.Enabled
+
+		now := time.Now().In(loc)
+		today := now.Format("2006-01-02")
+		runAt := nextRunTimeToday(now, cfg.DailyJob.RunAt)
+		if enabled {
+			job.setNextRun(nextDailyRunAt(now, runAt, lastRunDay == today))
+		} else {
+			job.setNextRun(time.Time{})
+		}
+
+		if enabled && dailyDue(now, runAt, lastRunDay) {
+			if job.Run(ctx, now, cfg.DailyJob, "schedule") {
+				lastRunDay = today
+			}
+		}
+
+		// Tick at 1-minute granularity; this is a once-per-day job.
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(1 * time.Minute):
+		}
+	}
+}
+
+// dailyDue reports whether the scheduled daily run should start: a trading day, past run_at,
+// and not yet run today.
+func dailyDue(now, runAt time.Time, lastRunDay string) bool {
+	return lastRunDay != now.Format("2006-01-02") && market.IsCNTradingDay(now) && now.After(runAt)
+}
+
+// nextDailyRunAt returns the next trading-day run time after now (for display only).
+func nextDailyRunAt(now, runAt time.Time, doneToday bool) time.Time {
+	next := runAt
+	if doneToday || now.After(runAt) {
+		next = next.AddDate(0, 0, 1)
+	}
+	for !market.IsCNTradingDay(next) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
 func nextRunTimeToday(now time.Time, runAt string) time.Time {
 	// runAt: "HH:MM" Asia/Shanghai
 	h, m := 3, 10
@@ -7,12 +7,14 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/pcdogyu/A-Stock-Order-Flow/internal/config"
 	"github.com/pcdogyu/A-Stock-Order-Flow/internal/collector"
 	"github.com/pcdogyu/A-Stock-Order-Flow/internal/memstore"
 	"github.com/pcdogyu/A-Stock-Order-Flow/internal/runtimecfg"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/store"
 	"github.com/pcdogyu/A-Stock-Order-Flow/internal/store/sqlite"
 )
 
@@ -33,11 +35,11 @@ func main() {
 
 		cfg, err := config.Load(*cfgPath)
 		fatalIf(err)
-		db, err := sqlite.Open(cfg.DBPath)
+		st, err := store.Open(cfg)
 		fatalIf(err)
-		defer db.Close()
-		fatalIf(sqlite.Migrate(db))
-		log.Printf("db initialized: %s", cfg.DBPath)
+		defer st.Close()
+		fatalIf(migrateDB(st))
+		log.Printf("db initialized: %s", dbLabel(cfg))
 	case "rt":
 		fs := flag.NewFlagSet("rt", flag.ExitOnError)
 		cfgPath := fs.String("config", "configs/config.yaml", "config path (YAML)")
@@ -45,30 +47,34 @@ func main() {
 
 		cfg, err := config.Load(*cfgPath)
 		fatalIf(err)
-		db, err := sqlite.Open(cfg.DBPath)
+		st, err := store.Open(cfg)
 		fatalIf(err)
-		defer db.Close()
-		fatalIf(sqlite.Migrate(db))
+		defer st.Close()
+		fatalIf(migrateDB(st))
 
 		ctx := context.Background()
 		mem := memstore.New()
 		static := runtimecfg.NewStatic(cfg)
-		c := collector.New(static, db, mem)
-		go runCleanupLoop(ctx, static, db)
+		c := collector.New(static, st, mem)
+		go runCleanupLoop(ctx, static, st)
+		go runBackupLoop(ctx, static, st)
 		go runPersistLoop(ctx, static, c)
+		go runDailyLoop(ctx, static, newDailyJob(c))
+		go runSecuritiesLoop(ctx, static, st, c)
 		fatalIf(c.RunRealtime(ctx))
 	case "daily":
 		fs := flag.NewFlagSet("daily", flag.ExitOnError)
 		cfgPath := fs.String("config", "configs/config.yaml", "config path (YAML)")
 		dateStr := fs.String("date", "", "trade date (YYYY-MM-DD), default: Asia/Shanghai today")
+		retryFailed := fs.Bool("retry-failed", false, "only re-run items whose last recorded run for the date failed")
 		_ = fs.Parse(os.Args[2:])
 
 		cfg, err := config.Load(*cfgPath)
 		fatalIf(err)
-		db, err := sqlite.Open(cfg.DBPath)
+		st, err := store.Open(cfg)
 		fatalIf(err)
-		defer db.Close()
-		fatalIf(sqlite.Migrate(db))
+		defer st.Close()
+		fatalIf(migrateDB(st))
 
 		var d time.Time
 		if *dateStr == "" {
@@ -80,8 +86,17 @@ func main() {
 		}
 
 		ctx := context.Background()
-		c := collector.New(runtimecfg.NewStatic(cfg), db, memstore.New())
-		fatalIf(c.RunDaily(ctx, d))
+		c := collector.New(runtimecfg.NewStatic(cfg), st, memstore.New())
+		var rep collector.DailyReport
+		if *retryFailed {
+			rep, err = c.RetryFailedDaily(ctx, d, "retry-failed")
+		} else {
+			rep, err = c.RunDaily(ctx, d, "cli")
+		}
+		for _, f := range rep.Failed {
+			log.Printf("daily failed: dataset=%s key=%s: %s", f.Dataset, f.Key, f.Err)
+		}
+		fatalIf(err)
 	case "web":
 		fs := flag.NewFlagSet("web", flag.ExitOnError)
 		cfgPath := fs.String("config", "configs/config.yaml", "config path (YAML)")
@@ -91,37 +106,190 @@ func main() {
 		mgr, err := runtimecfg.Load(*cfgPath)
 		fatalIf(err)
 		cfg := mgr.Get()
-		db, err := sqlite.Open(cfg.DBPath)
+		st, err := store.Open(cfg)
 		fatalIf(err)
-		defer db.Close()
-		fatalIf(sqlite.Migrate(db))
+		defer st.Close()
+		if cfg.Web.ReadOnly {
+			// The database belongs to whoever collects into it; only report a schema lag.
+			status, err := st.MigrationStatus()
+			fatalIf(err)
+			for _, m := range status {
+				if m.AppliedAt == "" {
+					log.Printf("read-only mode: migration %d (%s) is pending; run `aof migrate up` from the collector", m.Version, m.Name)
+				}
+			}
+		} else {
+			fatalIf(migrateDB(st))
+		}
 
 		ctx := context.Background()
 		mem := memstore.New()
-		c := collector.New(mgr, db, mem)
-		go func() {
-			if err := c.RunRealtime(ctx); err != nil {
-				log.Printf("collector stopped: %v", err)
-			}
-		}()
-		go runCleanupLoop(ctx, mgr, db)
-		go runPersistLoop(ctx, mgr, c)
+		c := collector.New(mgr, st, mem)
+		job := newDailyJob(c)
+		if cfg.Web.ReadOnly {
+			log.Printf("read-only mode: not collecting, serving %s", dbLabel(cfg))
+		} else {
+			go func() {
+				if err := c.RunRealtime(ctx); err != nil {
+					log.Printf("collector stopped: %v", err)
+				}
+			}()
+			go runCleanupLoop(ctx, mgr, st)
+			go runBackupLoop(ctx, mgr, st)
+			go runPersistLoop(ctx, mgr, c)
+			go runDailyLoop(ctx, mgr, job)
+			go runSecuritiesLoop(ctx, mgr, st, c)
+		}
 
-		srv := newWebServer(mgr, db, mem)
+		srv, err := newWebServer(mgr, st, mem, job)
+		fatalIf(err)
 		log.Printf("web listening on http://%s", *addr)
 		fatalIf(http.ListenAndServe(*addr, srv))
+	case "restore-archive":
+		fs := flag.NewFlagSet("restore-archive", flag.ExitOnError)
+		cfgPath := fs.String("config", "configs/config.yaml", "config path (YAML)")
+		dateStr := fs.String("date", "", "archived trade date (YYYY-MM-DD)")
+		dir := fs.String("dir", "", "archive directory (default: archive.dir from config)")
+		dbPath := fs.String("db", "", "target SQLite file (default: <dir>/<date>.db; pass db_path to load into the working DB)")
+		_ = fs.Parse(os.Args[2:])
+
+		cfg, err := config.Load(*cfgPath)
+		fatalIf(err)
+		if _, err := time.Parse("2006-01-02", *dateStr); err != nil {
+			fatalIf(fmt.Errorf("-date must be YYYY-MM-DD: %q", *dateStr))
+		}
+		if *dir == "" {
+			*dir = cfg.Archive.Dir
+		}
+		if *dbPath == "" {
+			*dbPath = filepath.Join(*dir, *dateStr+".db")
+		}
+		db, err := sqlite.Open(*dbPath)
+		fatalIf(err)
+		defer db.Close()
+		backup, err := sqlite.MigrateWithBackup(db, *dbPath)
+		if backup != "" {
+			log.Printf("db backup before migration: %s", backup)
+		}
+		fatalIf(err)
+		counts, err := sqlite.RestoreArchive(db, *dir, *dateStr)
+		for table, n := range counts {
+			log.Printf("restored %s: %d rows", table, n)
+		}
+		fatalIf(err)
+		log.Printf("archive %s restored into %s", *dateStr, *dbPath)
+	case "backup":
+		fs := flag.NewFlagSet("backup", flag.ExitOnError)
+		cfgPath := fs.String("config", "configs/config.yaml", "config path (YAML)")
+		out := fs.String("out", "", "output file (default: <backup.dir>/aof-<utc>.db, rotated by backup.keep)")
+		_ = fs.Parse(os.Args[2:])
+
+		cfg, err := config.Load(*cfgPath)
+		fatalIf(err)
+		st, err := store.Open(cfg)
+		fatalIf(err)
+		defer st.Close()
+
+		path := *out
+		if path == "" {
+			path, err = collector.Backup(st, cfg.Backup.Dir, cfg.Backup.Keep, time.Now().UTC())
+		} else {
+			err = st.Backup(path)
+		}
+		fatalIf(err)
+		log.Printf("backup written: %s", path)
+	case "restore":
+		fs := flag.NewFlagSet("restore", flag.ExitOnError)
+		cfgPath := fs.String("config", "configs/config.yaml", "config path (YAML)")
+		in := fs.String("in", "", "backup file to restore into db_path")
+		_ = fs.Parse(os.Args[2:])
+
+		cfg, err := config.Load(*cfgPath)
+		fatalIf(err)
+		if cfg.DBDriver != "sqlite" {
+			fatalIf(fmt.Errorf("restore is only supported for db_driver sqlite; use pg_restore for postgres"))
+		}
+		if *in == "" {
+			fatalIf(fmt.Errorf("-in is required"))
+		}
+		version, err := sqlite.CheckBackup(*in)
+		if err != nil {
+			fatalIf(fmt.Errorf("check backup %s: %w", *in, err))
+		}
+		previous, err := sqlite.RestoreFrom(cfg.DBPath, *in)
+		if previous != "" {
+			log.Printf("previous db saved: %s", previous)
+		}
+		fatalIf(err)
+		log.Printf("restored %s (schema v%d) into %s", *in, version, cfg.DBPath)
+	case "auth":
+		fatalIf(runAuthCommand(os.Args[2:]))
+	case "export":
+		fatalIf(runExportCommand(os.Args[2:]))
+	case "migrate":
+		fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+		cfgPath := fs.String("config", "configs/config.yaml", "config path (YAML)")
+		_ = fs.Parse(os.Args[2:])
+		action := fs.Arg(0)
+
+		cfg, err := config.Load(*cfgPath)
+		fatalIf(err)
+		st, err := store.Open(cfg)
+		fatalIf(err)
+		defer st.Close()
+
+		switch action {
+		case "status", "":
+			status, err := st.MigrationStatus()
+			fatalIf(err)
+			for _, m := range status {
+				applied := m.AppliedAt
+				if applied == "" {
+					applied = "pending"
+				}
+				fmt.Printf("%4d  %-40s %s\n", m.Version, m.Name, applied)
+			}
+		case "up":
+			fatalIf(migrateDB(st))
+		default:
+			usage()
+			os.Exit(2)
+		}
 	default:
 		usage()
 		os.Exit(2)
 	}
 }
 
+// migrateDB applies pending schema migrations; an existing SQLite file is backed up first.
+func migrateDB(st store.Store) error {
+	backup, err := st.Migrate()
+	if backup != "" {
+		log.Printf("db backup before migration: %s", backup)
+	}
+	return err
+}
+
+// dbLabel names the configured database for logs without printing DSN credentials.
+func dbLabel(cfg config.Config) string {
+	if cfg.DBDriver == "postgres" {
+		return "postgres"
+	}
+	return cfg.DBPath
+}
+
 func usage() {
 	fmt.Fprintln(os.Stderr, "Usage:")
 	fmt.Fprintln(os.Stderr, "  aof init-db -config configs/config.yaml")
 	fmt.Fprintln(os.Stderr, "  aof rt      -config configs/config.yaml")
-	fmt.Fprintln(os.Stderr, "  aof daily   -config configs/config.yaml [-date YYYY-MM-DD]")
+	fmt.Fprintln(os.Stderr, "  aof daily   -config configs/config.yaml [-date YYYY-MM-DD] [-retry-failed]")
 	fmt.Fprintln(os.Stderr, "  aof web     -config configs/config.yaml [-addr 127.0.0.1:8000]")
+	fmt.Fprintln(os.Stderr, "  aof migrate -config configs/config.yaml status|up")
+	fmt.Fprintln(os.Stderr, "  aof backup  -config configs/config.yaml [-out file.db]")
+	fmt.Fprintln(os.Stderr, "  aof restore -config configs/config.yaml -in file.db")
+	fmt.Fprintln(os.Stderr, "  aof auth    hash-password|new-token")
+	fmt.Fprintln(os.Stderr, "  aof export  -config configs/config.yaml -dataset fundflow [-codes 600519,000001] [-from YYYY-MM-DD] [-to YYYY-MM-DD] [-format csv|xlsx] [-zh] [-out file]")
+	fmt.Fprintln(os.Stderr, "  aof restore-archive -config configs/config.yaml -date YYYY-MM-DD [-dir archive] [-db out.db]")
 }
 
 func fatalIf(err error) {
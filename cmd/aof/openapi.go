@@ -0,0 +1,374 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/export"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/runtimecfg"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/store/sqlite"
+)
+
+// The OpenAPI 3.0 document served at /api/v1/openapi.json is generated from apiOps: schemas come
+// from the Go response types by reflection, so they can't drift from what writeJSON encodes.
+// Add an entry here when adding an endpoint.
+
+type apiParam struct {
+	Name     string
+	Desc     string
+	Required bool
+}
+
+type apiOp struct {
+	Method  string
+	Path    string // relative to /api/v1
+	Summary string
+	Params  []apiParam
+	Body    any // request body (JSON), nil if none
+	// Response is a value of the success body type; several values document alternatives (oneOf).
+	Response []any
+	Status   int      // success status, default 200
+	Produces []string // success content types, default application/json
+	Public   bool     // no credentials needed even with web.auth enabled
+}
+
+func optParam(name, desc string) apiParam { return apiParam{Name: name, Desc: desc} }
+func reqParam(name, desc string) apiParam { return apiParam{Name: name, Desc: desc, Required: true} }
+
+func bodies(v ...any) []any { return v }
+
+var (
+	pLimit   = optParam("limit", "max rows")
+	pBoardTp = optParam("type", "industry (default) | concept")
+	pFID     = optParam("fid", "Eastmoney flow field, default f62 (main net inflow)")
+	pKind    = optParam("kind", "daily (default) | rt (intraday)")
+	pSource  = optParam("source", "watchlist | toplist (default: both)")
+	pBoard   = reqParam("board", "board code, e.g. BK0457")
+	pRefresh = optParam("refresh", "1 to fetch from Eastmoney even if stored data looks complete")
+)
+
+var apiOps = []apiOp{
+	{Method: "GET", Path: "/health", Summary: "Liveness check", Response: bodies(healthResponse{}), Public: true},
+	{Method: "GET", Path: "/version", Summary: "Build, mode and caller", Response: bodies(versionResponse{})},
+	{Method: "GET", Path: "/openapi.json", Summary: "This document", Response: bodies(map[string]any{})},
+	{Method: "GET", Path: "/realtime", Summary: "Latest realtime snapshot with watch group sums", Response: bodies(realtimeView{})},
+	{Method: "GET", Path: "/stream", Summary: "Realtime updates as Server-Sent Events (snapshot, then update events)",
+		Produces: []string{"text/event-stream"}},
+	{Method: "GET", Path: "/ws", Summary: "Realtime updates over WebSocket with per-client subscriptions",
+		Status: http.StatusSwitchingProtocols},
+
+	{Method: "GET", Path: "/watchgroups", Summary: "List watch groups",
+		Params: []apiParam{optParam("id", "only this group"), optParam("tag", "only groups with this tag")}, Response: bodies(watchGroupsResponse{})},
+	{Method: "POST", Path: "/watchgroups", Summary: "Create a watch group", Body: sqlite.WatchGroup{}, Response: bodies(okResponse{})},
+	{Method: "PUT", Path: "/watchgroups", Summary: "Replace a watch group",
+		Params: []apiParam{reqParam("id", "group id")}, Body: sqlite.WatchGroup{}, Response: bodies(okResponse{})},
+	{Method: "DELETE", Path: "/watchgroups", Summary: "Delete a watch group",
+		Params: []apiParam{reqParam("id", "group id")}, Response: bodies(okResponse{})},
+
+	{Method: "GET", Path: "/config", Summary: "Runtime config", Response: bodies(configView{})},
+	{Method: "POST", Path: "/config", Summary: "Patch the runtime config (persisted to the YAML file)",
+		Body: runtimecfg.Patch{}, Response: bodies(configView{})},
+
+	{Method: "GET", Path: "/history/market_agg", Summary: "Market aggregate history",
+		Params:   []apiParam{pKind, reqParam("source", "aggregate source, e.g. industry_sum"), reqParam("fid", "flow field"), pLimit},
+		Response: bodies([]sqlite.MarketAggDailyPoint{}, []sqlite.MarketAggRTPoint{})},
+	{Method: "GET", Path: "/history/board_sum", Summary: "Sum over all boards of a type",
+		Params:   []apiParam{pKind, pBoardTp, pFID, pLimit},
+		Response: bodies([]sqlite.BoardSumDailyPoint{}, []sqlite.BoardSumRTPoint{})},
+	{Method: "GET", Path: "/history/board_price_sum", Summary: "Today's intraday sum of board prices",
+		Params: []apiParam{pBoardTp, pFID, pLimit}, Response: bodies([]sqlite.BoardSumRTPoint{})},
+	{Method: "GET", Path: "/intraday", Summary: "Today's in-memory intraday series",
+		Params:   []apiParam{reqParam("dataset", "fundflow | northbound"), optParam("code", "stock code, required for fundflow")},
+		Response: bodies(fundflowIntraday{}, northboundIntraday{})},
+	{Method: "GET", Path: "/bars", Summary: "Rolled-up OHLC bars of rt series",
+		Params: []apiParam{optParam("period", "1m | 5m (default)"), reqParam("dataset", "board | fundflow | agg | northbound"),
+			reqParam("key", "series key, e.g. industry_sum:f62"), optParam("field", "value column"), optParam("days", "look-back in days, default 5")},
+		Response: bodies(barsResponse{})},
+
+	{Method: "GET", Path: "/daily/job", Summary: "After-close daily job status", Response: bodies(dailyJobStatus{})},
+	{Method: "POST", Path: "/daily/job", Summary: "Start the daily job now",
+		Params: []apiParam{optParam("date", "trade date YYYY-MM-DD, default today")}, Response: bodies(dailyJobStartResponse{}), Status: http.StatusAccepted},
+	{Method: "GET", Path: "/daily/runs", Summary: "Daily run log, with per-item completeness for one date",
+		Params: []apiParam{optParam("date", "trade date YYYY-MM-DD"), pLimit}, Response: bodies(dailyRunsResponse{})},
+	{Method: "POST", Path: "/admin/backup", Summary: "Online database backup", Response: bodies(backupResponse{})},
+
+	{Method: "GET", Path: "/auction/rank", Summary: "Call auction ranking",
+		Params: []apiParam{optParam("phase", "open (default) | close"), optParam("date", "trade date, default latest"), pSource,
+			optParam("sort", "flow (default) or another ranking column"), pLimit},
+		Response: bodies(auctionRankResponse{})},
+	{Method: "GET", Path: "/fundflow/velocity", Summary: "Intraday flow velocity ranking",
+		Params: []apiParam{optParam("window", "duration between 1m and 4h, default 5m"), pSource,
+			optParam("sort", "accel | decel | inflow | outflow"), pLimit},
+		Response: bodies(velocityResponse{})},
+
+	{Method: "GET", Path: "/boards", Summary: "Latest board list",
+		Params: []apiParam{pBoardTp, pFID, pLimit, pRefresh}, Response: bodies(boardsResponse{})},
+	{Method: "GET", Path: "/board/constituents", Summary: "Board constituents with today's move",
+		Params: []apiParam{pBoard, optParam("pn", "page, default 1"), optParam("pz", "page size, default 50")}, Response: bodies(constituentsResponse{})},
+	{Method: "GET", Path: "/board/daily", Summary: "Board daily flow history",
+		Params: []apiParam{pBoard, pBoardTp, pFID, pLimit, pRefresh}, Response: bodies(boardDailyResponse{})},
+	{Method: "GET", Path: "/board/daily/batch", Summary: "Board daily batch load status",
+		Params: []apiParam{optParam("type", "concept (default) | industry")}, Response: bodies(boardDailyStatus{})},
+	{Method: "POST", Path: "/board/daily/batch", Summary: "Start loading daily history of all boards of a type",
+		Params:   []apiParam{optParam("type", "concept (default) | industry"), pLimit},
+		Response: bodies(boardDailyStatus{}), Status: http.StatusAccepted},
+	{Method: "GET", Path: "/board/trend", Summary: "Board intraday trend", Params: []apiParam{pBoard}, Response: bodies(trendResponse{})},
+	{Method: "GET", Path: "/stock/trend", Summary: "Stock intraday trend",
+		Params: []apiParam{reqParam("code", "stock code, e.g. 600519")}, Response: bodies(trendResponse{})},
+	{Method: "GET", Path: "/search", Summary: "Search stocks, indices, ETFs and boards by code prefix, name or pinyin initials",
+		Params: []apiParam{
+			reqParam("q", "e.g. 600519, 茅台 or gzmt"),
+			optParam("kind", "comma-separated stock | index | etf | industry | concept (default all)"),
+			optParam("limit", "max results, default 20, max 100"),
+		},
+		Response: bodies(searchResponse{})},
+	{Method: "GET", Path: "/stock/detail", Summary: "Stock drill-down: live flow, intraday price, daily flow and margin history, boards, toplist days",
+		Params: []apiParam{
+			reqParam("code", "stock code, e.g. 600519 or 600519.SH"),
+			optParam("days", "days of history, default 60, max 250"), pRefresh,
+		},
+		Response: bodies(stockDetailResponse{})},
+	{Method: "GET", Path: "/secid/trend", Summary: "Intraday trend by Eastmoney secid",
+		Params: []apiParam{reqParam("secid", "e.g. 1.000001")}, Response: bodies(trendResponse{})},
+
+	{Method: "GET", Path: "/export/{dataset}", Summary: "Daily or intraday history as a CSV or Excel file",
+		Params: []apiParam{
+			reqParam("dataset", "market_agg | board_sum | board_daily | fundflow | northbound, "+
+				"or intraday market_agg_rt | board_sum_rt | board_price_sum_rt"),
+			optParam("format", "csv (default) | xlsx"),
+			optParam("codes", "comma-separated sources (market_agg*), board types (board_*sum*) or codes; default all"),
+			optParam("from", "first trade date YYYY-MM-DD"), optParam("to", "last trade date YYYY-MM-DD"),
+			pFID, optParam("type", "board_daily: industry | concept (default both)"),
+			optParam("headers", "zh for Chinese column headers"),
+		},
+		Produces: []string{export.ContentType("csv"), export.ContentType("xlsx")}},
+}
+
+func openAPISpec() map[string]any {
+	sb := &schemaBuilder{schemas: make(map[string]any), names: make(map[reflect.Type]string)}
+	errRef := sb.schemaOf(reflect.TypeOf(apiError{}))
+
+	paths := make(map[string]map[string]any)
+	for _, op := range apiOps {
+		status := op.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		ok := map[string]any{"description": http.StatusText(status)}
+		if len(op.Response) > 0 || len(op.Produces) > 0 {
+			ctypes := op.Produces
+			if len(ctypes) == 0 {
+				ctypes = []string{"application/json"}
+			}
+			schema := map[string]any{"type": "string", "format": "binary"} // files and streams
+			if len(op.Response) == 1 {
+				schema = sb.schemaOf(reflect.TypeOf(op.Response[0]))
+			} else if len(op.Response) > 1 {
+				var alts []any
+				for _, v := range op.Response {
+					alts = append(alts, sb.schemaOf(reflect.TypeOf(v)))
+				}
+				schema = map[string]any{"oneOf": alts}
+			}
+			content := make(map[string]any)
+			for _, ct := range ctypes {
+				content[ct] = map[string]any{"schema": schema}
+			}
+			ok["content"] = content
+		}
+		o := map[string]any{
+			"summary":     op.Summary,
+			"operationId": operationID(op),
+			"responses": map[string]any{
+				strconv.Itoa(status): ok,
+				"default": map[string]any{
+					"description": "Error",
+					"content":     map[string]any{"application/json": map[string]any{"schema": errRef}},
+				},
+			},
+		}
+		if len(op.Params) > 0 {
+			var params []any
+			for _, p := range op.Params {
+				in := "query"
+				if strings.Contains(op.Path, "{"+p.Name+"}") {
+					in = "path"
+				}
+				params = append(params, map[string]any{
+					"name": p.Name, "in": in, "description": p.Desc, "required": p.Required,
+					"schema": map[string]any{"type": "string"},
+				})
+			}
+			o["parameters"] = params
+		}
+		if op.Body != nil {
+			o["requestBody"] = map[string]any{
+				"required": true,
+				"content":  map[string]any{"application/json": map[string]any{"schema": sb.schemaOf(reflect.TypeOf(op.Body))}},
+			}
+		}
+		if op.Public {
+			o["security"] = []any{}
+		}
+		if paths[op.Path] == nil {
+			paths[op.Path] = make(map[string]any)
+		}
+		paths[op.Path][strings.ToLower(op.Method)] = o
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "A-Stock-Order-Flow API",
+			"version": "1",
+			"description": "Also served without the /v1 prefix under /api. Errors use the ApiError schema " +
+				"(on the unversioned aliases they keep their pre-v1 bodies, {\"error\": \"<message>\"} plus any " +
+				"route-specific fields such as board or secid; read-only mode answers upstream fetches with 503). " +
+				"Credentials are required only when web.auth is enabled; non-GET requests need the admin role.",
+		},
+		"servers": []any{map[string]any{"url": apiV1Prefix}},
+		"paths":   paths,
+		"components": map[string]any{
+			"schemas": sb.schemas,
+			"securitySchemes": map[string]any{
+				"basic":  map[string]any{"type": "http", "scheme": "basic"},
+				"bearer": map[string]any{"type": "http", "scheme": "bearer"},
+			},
+		},
+		"security": []any{map[string]any{"basic": []any{}}, map[string]any{"bearer": []any{}}},
+	}
+}
+
+// operationID is e.g. getBoardDailyBatch for GET /board/daily/batch.
+func operationID(op apiOp) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(op.Method))
+	for _, part := range strings.FieldsFunc(op.Path, func(r rune) bool { return strings.ContainsRune("/_.{}", r) }) {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+// schemaBuilder maps Go types to JSON schemas the way encoding/json encodes them. Named structs
+// become components/schemas entries referenced by $ref.
+type schemaBuilder struct {
+	schemas map[string]any
+	names   map[reflect.Type]string
+}
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	rawType  = reflect.TypeOf(json.RawMessage{})
+)
+
+func (sb *schemaBuilder) schemaOf(t reflect.Type) map[string]any {
+	switch t {
+	case timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case rawType:
+		return map[string]any{}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return sb.schemaOf(t.Elem())
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]any{"type": "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return map[string]any{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "format": "byte"}
+		}
+		return map[string]any{"type": "array", "items": sb.schemaOf(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": sb.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return sb.structSchema(t)
+		}
+		name, ok := sb.names[t]
+		if !ok {
+			name = sb.schemaName(t)
+			sb.names[t] = name
+			sb.schemas[name] = map[string]any{} // placeholder for recursive types
+			sb.schemas[name] = sb.structSchema(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + name}
+	default:
+		return map[string]any{} // interfaces: any JSON value
+	}
+}
+
+func (sb *schemaBuilder) structSchema(t reflect.Type) map[string]any {
+	props := make(map[string]any)
+	var required []string
+	sb.addFields(t, props, &required)
+	out := map[string]any{"type": "object", "properties": props}
+	if len(required) > 0 {
+		sort.Strings(required)
+		out["required"] = required
+	}
+	return out
+}
+
+func (sb *schemaBuilder) addFields(t reflect.Type, props map[string]any, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		ft := f.Type
+		if f.Anonymous && name == "" {
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				sb.addFields(ft, props, required) // promoted fields, as encoding/json does
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		s := sb.schemaOf(f.Type)
+		if strings.Contains(","+opts+",", ",string,") {
+			s = map[string]any{"type": "string"}
+		}
+		props[name] = s
+		if !strings.Contains(","+opts+",", ",omitempty,") && f.Type.Kind() != reflect.Pointer {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// schemaName is the exported form of the type name, e.g. IntradayResponse_FundflowPoint for
+// intradayResponse[memstore.FundflowPoint], qualified by package on collisions.
+func (sb *schemaBuilder) schemaName(t reflect.Type) string {
+	name := t.Name()
+	if i := strings.IndexByte(name, '['); i >= 0 {
+		arg := strings.TrimSuffix(name[i+1:], "]")
+		name = name[:i] + "_" + arg[strings.LastIndexByte(arg, '.')+1:]
+	}
+	name = strings.ToUpper(name[:1]) + name[1:]
+	if _, taken := sb.schemas[name]; taken {
+		name = path.Base(t.PkgPath()) + "_" + name
+	}
+	return name
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/memstore"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/store"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/store/sqlite"
+)
+
+// seedSeriesFromDB replays today's persisted rt snapshots into the in-memory series so intraday
+// charts stay memory-first after a restart (persisted samples are coarser than live ticks).
+func seedSeriesFromDB(st store.Store, mem *memstore.Store) {
+	if st == nil || mem == nil {
+		return
+	}
+	loc, _ := time.LoadLocation("Asia/Shanghai")
+	now := time.Now().In(loc)
+	start := sqlite.FixedRFC3339Nano(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc).UTC())
+	end := sqlite.FixedRFC3339Nano(now.UTC())
+
+	replay := func(table string, apply func(ts time.Time, tsStr string) error) {
+		list, err := st.QueryRTTimestamps(table, start, end)
+		if err != nil {
+			log.Printf("seed series %s: %v", table, err)
+			return
+		}
+		for _, s := range list {
+			ts, err := time.Parse(time.RFC3339Nano, s)
+			if err != nil {
+				continue
+			}
+			if err := apply(ts, s); err != nil {
+				log.Printf("seed series %s: %v", table, err)
+				return
+			}
+		}
+	}
+
+	replay("northbound_rt", func(ts time.Time, s string) error {
+		nb, err := st.QueryNorthboundRTAt(s)
+		if err == nil && nb != nil {
+			mem.SetNorthbound(ts, *nb)
+		}
+		return err
+	})
+	replay("fundflow_rt", func(ts time.Time, s string) error {
+		rows, err := st.QueryFundflowRTAt(s)
+		if err == nil {
+			mem.SetFundflow(ts, rows)
+		}
+		return err
+	})
+	replay("board_rt", func(ts time.Time, s string) error {
+		boards, err := st.QueryBoardsRTAt(s)
+		for key, rows := range boards {
+			if bt, fid, ok := split2Key(key); ok {
+				mem.SetBoard(ts, bt, fid, rows)
+			}
+		}
+		return err
+	})
+	replay("market_agg_rt", func(ts time.Time, s string) error {
+		agg, err := st.QueryMarketAggRTAt(s)
+		for key, v := range agg {
+			if source, fid, ok := split2Key(key); ok {
+				mem.SetAgg(ts, source, fid, v)
+			}
+		}
+		return err
+	})
+}
+
+// persistCadence thins an in-memory series (fetch cadence, e.g. 5s) to the last point of every
+// step, the cadence SQLite rows are written at, so limit spans the same time window either way.
+func persistCadence[T any](pts []T, tsOf func(T) time.Time, step time.Duration) []T {
+	if step <= 0 || len(pts) < 2 {
+		return pts
+	}
+	out := make([]T, 0, len(pts))
+	for i, p := range pts {
+		if i+1 < len(pts) && tsOf(pts[i+1]).Truncate(step).Equal(tsOf(p).Truncate(step)) {
+			continue
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+// memFirst returns the newest limit points, taking today's in-memory series (mem, oldest first)
+// and topping up from SQLite only for older samples when memory holds fewer than limit.
+func memFirst[T any](mem []T, limit int, tsOf func(T) string, older func() ([]T, error)) ([]T, error) {
+	if len(mem) >= limit {
+		return mem[len(mem)-limit:], nil
+	}
+	rows, err := older()
+	if err != nil {
+		return nil, err
+	}
+	if len(mem) == 0 {
+		return rows, nil
+	}
+	first := tsOf(mem[0])
+	out := make([]T, 0, limit)
+	for _, r := range rows {
+		if tsOf(r) < first {
+			out = append(out, r)
+		}
+	}
+	out = append(out, mem...)
+	if len(out) > limit {
+		out = out[len(out)-limit:]
+	}
+	return out, nil
+}
+
+// inSession keeps points within [start, end].
+func inSession[T any](pts []T, tsOf func(T) time.Time, start, end time.Time) []T {
+	out := make([]T, 0, len(pts))
+	for _, p := range pts {
+		if ts := tsOf(p); !ts.Before(start) && !ts.After(end) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
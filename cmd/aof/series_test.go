@@ -0,0 +1,49 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/memstore"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/store/sqlite"
+)
+
+func TestPersistCadenceKeepsTimeWindow(t *testing.T) {
+	// 09:30-11:30 at the 5s fetch cadence.
+	start := time.Date(2024, 1, 2, 1, 30, 0, 0, time.UTC)
+	var pts []memstore.Point
+	for ts := start; !ts.After(start.Add(2 * time.Hour)); ts = ts.Add(5 * time.Second) {
+		pts = append(pts, memstore.Point{TSUTC: ts, Value: float64(ts.Sub(start) / time.Second)})
+	}
+	tsOf := func(p memstore.Point) time.Time { return p.TSUTC }
+
+	thin := persistCadence(pts, tsOf, time.Minute)
+	if len(thin) != 121 {
+		t.Fatalf("thinned to %d points", len(thin))
+	}
+	// The last sample of every minute is kept, including the newest point.
+	if !thin[0].TSUTC.Equal(start.Add(55*time.Second)) || !thin[len(thin)-1].TSUTC.Equal(pts[len(pts)-1].TSUTC) {
+		t.Fatalf("first=%v last=%v", thin[0].TSUTC, thin[len(thin)-1].TSUTC)
+	}
+
+	var live []sqlite.MarketAggRTPoint
+	for _, p := range thin {
+		live = append(live, sqlite.MarketAggRTPoint{TSUTC: sqlite.FixedRFC3339Nano(p.TSUTC), Value: p.Value})
+	}
+	rows, err := memFirst(live, 60, func(p sqlite.MarketAggRTPoint) string { return p.TSUTC }, func() ([]sqlite.MarketAggRTPoint, error) {
+		t.Fatal("memory covers the limit; SQLite must not be read")
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, _ := time.Parse(time.RFC3339Nano, rows[0].TSUTC)
+	last, _ := time.Parse(time.RFC3339Nano, rows[len(rows)-1].TSUTC)
+	if len(rows) != 60 || last.Sub(first) < 58*time.Minute {
+		t.Fatalf("limit=60 spans %v over %d rows", last.Sub(first), len(rows))
+	}
+
+	if got := persistCadence(pts, tsOf, 0); len(got) != len(pts) {
+		t.Fatalf("step 0 changed the series: %d", len(got))
+	}
+}
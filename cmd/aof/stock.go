@@ -0,0 +1,412 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/eastmoney"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/memstore"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/store"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/store/sqlite"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/symbol"
+)
+
+// stockDetailResponse is one stock's drill-down: live flow and price plus its daily history,
+// boards and toplist appearances. Sections that could not be loaded are empty and explained in
+// Warning.
+type stockDetailResponse struct {
+	Code  string `json:"code"`
+	SecID string `json:"secid"`
+	Name  string `json:"name,omitempty"`
+	// Realtime is the current cumulative fund flow; from the collector when the stock is watched.
+	Realtime        *eastmoney.FundflowRT       `json:"realtime,omitempty"`
+	RealtimeUpdated *time.Time                  `json:"realtime_updated,omitempty"`
+	Trend           []eastmoney.TrendPoint      `json:"trend"`
+	FundflowDaily   []sqlite.FundflowDailyPoint `json:"fundflow_daily"`
+	MarginDaily     []sqlite.MarginDailyPoint   `json:"margin_daily"`
+	Boards          []stockBoard                `json:"boards"`
+	// Toplist lists the days (within the requested days) the stock was on a collected toplist.
+	Toplist []sqlite.ToplistAppearance `json:"toplist"`
+	TSUTC   time.Time                  `json:"ts_utc"`
+	Warning string                     `json:"warning,omitempty"`
+}
+
+type stockBoard struct {
+	Code  string  `json:"code"`
+	Name  string  `json:"name"`
+	Type  string  `json:"type,omitempty"` // industry | concept when the board is collected, else empty
+	Price float64 `json:"price"`
+	Pct   float64 `json:"pct"`
+}
+
+// ttlCache keeps on-demand fetch results for a while so repeated drill-downs don't hit Eastmoney.
+type ttlCache[T any] struct {
+	mu    sync.Mutex
+	ttl   time.Duration
+	byKey map[string]ttlEntry[T]
+}
+
+type ttlEntry[T any] struct {
+	val T
+	at  time.Time
+}
+
+func newTTLCache[T any](ttl time.Duration) *ttlCache[T] {
+	return &ttlCache[T]{ttl: ttl, byKey: make(map[string]ttlEntry[T])}
+}
+
+func (c *ttlCache[T]) Get(key string) (T, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.byKey[key]
+	if !ok || time.Since(e.at) > c.ttl {
+		var zero T
+		return zero, false
+	}
+	return e.val, true
+}
+
+func (c *ttlCache[T]) Set(key string, v T) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	for k, e := range c.byKey {
+		if now.Sub(e.at) > c.ttl {
+			delete(c.byKey, k)
+		}
+	}
+	c.byKey[key] = ttlEntry[T]{val: v, at: now}
+}
+
+// stockDetail assembles /api/stock/detail. History comes from SQLite; when fundflow_daily or
+// margin_daily hold fewer days than asked, the series is fetched once per historyTTL (again if a
+// longer history is asked) and upserted, so the next request (and exports) read it from the database.
+type stockDetail struct {
+	st     store.Store
+	mem    *memstore.Store
+	em     *eastmoney.Client
+	trends *boardTrendCache
+
+	realtime *ttlCache[eastmoney.FundflowRT]
+	boards   *ttlCache[[]eastmoney.TopItem]
+	fetched  *ttlCache[historyFetch] // by "fundflow:<code>" / "margin:<code>"
+}
+
+// historyFetch is the outcome of one upstream history fetch of Days days.
+type historyFetch struct {
+	Days int
+	Err  error
+}
+
+// recentFetch returns the fetch of key within historyTTL if it asked for at least days days;
+// asking for a longer history fetches again.
+func (d *stockDetail) recentFetch(key string, days int) (historyFetch, bool) {
+	f, ok := d.fetched.Get(key)
+	return f, ok && f.Days >= days
+}
+
+const historyTTL = 30 * time.Minute
+
+func newStockDetail(st store.Store, mem *memstore.Store, em *eastmoney.Client, trends *boardTrendCache) *stockDetail {
+	return &stockDetail{
+		st:       st,
+		mem:      mem,
+		em:       em,
+		trends:   trends,
+		realtime: newTTLCache[eastmoney.FundflowRT](25 * time.Second),
+		boards:   newTTLCache[[]eastmoney.TopItem](time.Hour),
+		fetched:  newTTLCache[historyFetch](historyTTL),
+	}
+}
+
+// GET /api/stock/detail?code=600519&days=60
+func (d *stockDetail) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w)
+		return
+	}
+	q := r.URL.Query()
+	code, err := symbol.CodeOnly(q.Get("code"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "code is required (e.g. 600519)")
+		return
+	}
+	secid, err := symbol.ToEastmoneySecIDFromCode(strings.TrimSpace(q.Get("code")))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	days := 60
+	if v := strings.TrimSpace(q.Get("days")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, "days must be a positive integer")
+			return
+		}
+		days = n
+	}
+	if days > 250 {
+		days = 250
+	}
+	writeJSON(w, http.StatusOK, d.load(r.Context(), code, secid, days, q.Get("refresh") == "1"))
+}
+
+func (d *stockDetail) load(ctx context.Context, code, secid string, days int, refresh bool) stockDetailResponse {
+	out := stockDetailResponse{
+		Code:          code,
+		SecID:         secid,
+		Trend:         []eastmoney.TrendPoint{},
+		FundflowDaily: []sqlite.FundflowDailyPoint{},
+		MarginDaily:   []sqlite.MarginDailyPoint{},
+		Boards:        []stockBoard{},
+		Toplist:       []sqlite.ToplistAppearance{},
+		TSUTC:         time.Now().UTC(),
+	}
+	var (
+		mu       sync.Mutex
+		warnings []string
+		names    [3]string // realtime, fundflow_daily, margin_daily
+		wg       sync.WaitGroup
+	)
+	warn := func(section string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		warnings = append(warnings, section+": "+err.Error())
+	}
+	run := func(f func()) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			f()
+		}()
+	}
+
+	run(func() {
+		rt, updated, err := d.loadRealtime(ctx, code, secid, refresh)
+		if err != nil {
+			warn("realtime", err)
+			return
+		}
+		out.Realtime, out.RealtimeUpdated, names[0] = &rt, updated, rt.Name
+	})
+	run(func() {
+		if !refresh {
+			if cached, ok := d.trends.Get(secid); ok {
+				out.Trend = cached.points
+				return
+			}
+		}
+		points, err := d.em.StockTrends1D(ctx, secid)
+		if err != nil {
+			warn("trend", err)
+			return
+		}
+		d.trends.Set(secid, points)
+		out.Trend = points
+	})
+	run(func() {
+		points, name, err := d.loadFundflowDaily(ctx, code, secid, days, refresh)
+		if err != nil {
+			warn("fundflow_daily", err)
+		}
+		if points != nil {
+			out.FundflowDaily = points
+		}
+		names[1] = name
+	})
+	run(func() {
+		points, name, err := d.loadMarginDaily(ctx, code, days, refresh)
+		if err != nil {
+			warn("margin_daily", err)
+		}
+		if points != nil {
+			out.MarginDaily = points
+		}
+		names[2] = name
+	})
+	run(func() {
+		boards, err := d.loadBoards(ctx, secid, refresh)
+		if err != nil {
+			warn("boards", err)
+			return
+		}
+		out.Boards = boards
+	})
+	run(func() {
+		since := sqlite.FixedRFC3339Nano(time.Now().UTC().AddDate(0, 0, -days))
+		rows, err := d.st.QueryToplistAppearances(code, since)
+		if err != nil {
+			warn("toplist", err)
+			return
+		}
+		if rows != nil {
+			out.Toplist = rows
+		}
+	})
+	wg.Wait()
+
+	for _, n := range names {
+		if n != "" {
+			out.Name = n
+			break
+		}
+	}
+	sort.Strings(warnings)
+	out.Warning = strings.Join(warnings, "; ")
+	return out
+}
+
+// loadRealtime prefers the collector's row (watchlist/toplist stocks) and fetches others directly.
+func (d *stockDetail) loadRealtime(ctx context.Context, code, secid string, refresh bool) (eastmoney.FundflowRT, *time.Time, error) {
+	snap := d.mem.SnapshotLatest()
+	for _, ff := range snap.Fundflow {
+		if ff.Code == code {
+			if at, ok := snap.FundflowUpdated[code]; ok {
+				return ff, &at, nil
+			}
+			return ff, nil, nil
+		}
+	}
+	if !refresh {
+		if rt, ok := d.realtime.Get(secid); ok {
+			return rt, nil, nil
+		}
+	}
+	rows, err := d.em.FundflowRealtime(ctx, []string{secid})
+	if err != nil {
+		return eastmoney.FundflowRT{}, nil, err
+	}
+	if len(rows) == 0 {
+		return eastmoney.FundflowRT{}, nil, fmt.Errorf("no realtime row for %s", secid)
+	}
+	d.realtime.Set(secid, rows[0])
+	return rows[0], nil, nil
+}
+
+func (d *stockDetail) loadFundflowDaily(ctx context.Context, code, secid string, days int, refresh bool) ([]sqlite.FundflowDailyPoint, string, error) {
+	points, name, err := d.st.QueryFundflowDailyByCode(code, days)
+	if err != nil {
+		return nil, "", err
+	}
+	key := "fundflow:" + code
+	if len(points) >= days && !refresh {
+		return points, name, nil
+	}
+	if f, ok := d.recentFetch(key, days); ok && !refresh {
+		return points, name, f.Err
+	}
+	rows, err := d.em.FundflowDailySeries(ctx, secid, days)
+	d.fetched.Set(key, historyFetch{Days: days, Err: err})
+	if err != nil {
+		return points, name, err
+	}
+	byDate := make(map[string]sqlite.FundflowDailyPoint, len(points)+len(rows))
+	for _, p := range points {
+		byDate[p.TradeDate] = p
+	}
+	loc, _ := time.LoadLocation("Asia/Shanghai")
+	today := time.Now().In(loc).Format("2006-01-02")
+	for _, r := range rows {
+		byDate[r.TradeDate] = sqlite.FundflowDailyPoint{
+			TradeDate: r.TradeDate, NetMain: r.NetMain, NetXL: r.NetXL, NetL: r.NetL, NetM: r.NetM, NetS: r.NetS,
+		}
+		if r.Name != "" {
+			name = r.Name
+		}
+		// Today's row is still moving; the daily job records it after the close.
+		if r.TradeDate == today {
+			continue
+		}
+		if err := d.st.UpsertFundflowDaily(r.TradeDate, r); err != nil {
+			log.Printf("stock detail: upsert fundflow_daily %s %s: %v", code, r.TradeDate, err)
+		}
+	}
+	return lastDays(byDate, days), name, nil
+}
+
+func (d *stockDetail) loadMarginDaily(ctx context.Context, code string, days int, refresh bool) ([]sqlite.MarginDailyPoint, string, error) {
+	points, err := d.st.QueryMarginDailyByCode(code, days)
+	if err != nil {
+		return nil, "", err
+	}
+	key := "margin:" + code
+	if len(points) >= days && !refresh {
+		return points, "", nil
+	}
+	if f, ok := d.recentFetch(key, days); ok && !refresh {
+		return points, "", f.Err
+	}
+	rows, err := d.em.MarginSeriesByCode(ctx, code, days)
+	d.fetched.Set(key, historyFetch{Days: days, Err: err})
+	if err != nil {
+		return points, "", err
+	}
+	var name string
+	byDate := make(map[string]sqlite.MarginDailyPoint, len(points)+len(rows))
+	for _, p := range points {
+		byDate[p.TradeDate] = p
+	}
+	for _, r := range rows {
+		byDate[r.TradeDate] = sqlite.MarginDailyPoint{
+			TradeDate: r.TradeDate, RZYE: r.RZYE, RZMRE: r.RZMRE, RZCHE: r.RZCHE, RZJME: r.RZJME,
+			RQYE: r.RQYE, RQMCL: r.RQMCL, RQCHL: r.RQCHL, RQJMG: r.RQJMG, RZRQYE: r.RZRQYE,
+		}
+		name = r.Name
+		if err := d.st.UpsertMarginDaily(r.TradeDate, r); err != nil {
+			log.Printf("stock detail: upsert margin_daily %s %s: %v", code, r.TradeDate, err)
+		}
+	}
+	return lastDays(byDate, days), name, nil
+}
+
+// loadBoards lists the boards containing secid, typed by the board lists the collector keeps.
+func (d *stockDetail) loadBoards(ctx context.Context, secid string, refresh bool) ([]stockBoard, error) {
+	items, ok := d.boards.Get(secid)
+	if !ok || refresh {
+		var err error
+		items, err = d.em.StockBoards(ctx, secid)
+		if err != nil {
+			return nil, err
+		}
+		d.boards.Set(secid, items)
+	}
+	types := make(map[string]string)
+	for key, rows := range d.mem.SnapshotLatest().BoardsByKey {
+		tp, _, _ := strings.Cut(key, ":")
+		for _, b := range rows {
+			types[b.Code] = tp
+		}
+	}
+	out := make([]stockBoard, 0, len(items))
+	for _, it := range items {
+		out = append(out, stockBoard{Code: it.Code, Name: it.Name, Type: types[it.Code], Price: it.Price, Pct: it.Pct})
+	}
+	// Collected boards (industry first, then concept) before the rest, keeping upstream order.
+	rank := map[string]int{"industry": 0, "concept": 1, "": 2}
+	sort.SliceStable(out, func(i, j int) bool { return rank[out[i].Type] < rank[out[j].Type] })
+	return out, nil
+}
+
+// lastDays returns the newest n values of a trade_date keyed map, oldest first.
+func lastDays[T any](byDate map[string]T, n int) []T {
+	dates := make([]string, 0, len(byDate))
+	for d := range byDate {
+		dates = append(dates, d)
+	}
+	sort.Strings(dates)
+	if len(dates) > n {
+		dates = dates[len(dates)-n:]
+	}
+	out := make([]T, 0, len(dates))
+	for _, d := range dates {
+		out = append(out, byDate[d])
+	}
+	return out
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRecentFetchDepth(t *testing.T) {
+	d := newStockDetail(nil, nil, nil, nil)
+	if _, ok := d.recentFetch("fundflow:600519", 60); ok {
+		t.Fatal("fetch reported before any")
+	}
+	d.fetched.Set("fundflow:600519", historyFetch{Days: 60, Err: errors.New("timeout")})
+	if f, ok := d.recentFetch("fundflow:600519", 30); !ok || f.Err == nil {
+		t.Fatalf("shorter history: %+v, %v", f, ok)
+	}
+	if _, ok := d.recentFetch("fundflow:600519", 60); !ok {
+		t.Fatal("same depth not served from the cache")
+	}
+	if _, ok := d.recentFetch("fundflow:600519", 120); ok {
+		t.Fatal("longer history served from a 60-day fetch")
+	}
+	if _, ok := d.recentFetch("margin:600519", 30); ok {
+		t.Fatal("margin served from the fundflow fetch")
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/memstore"
+)
+
+// streamEpoch tags event ids so a client resuming against a restarted process
+// (whose sequence numbers started over) gets a full snapshot instead of a wrong backlog.
+var streamEpoch = strconv.FormatInt(time.Now().Unix(), 36)
+
+// newStreamHandler serves memstore updates as Server-Sent Events:
+//
+//	event: snapshot  full memstore.Snapshot (on connect, or when the requested id can't be resumed)
+//	event: update    memstore.Event with only the changed rows of one dataset
+//
+// Ids are "<epoch>-<seq>"; EventSource sends the last one back as Last-Event-ID on reconnect.
+func newStreamHandler(mem *memstore.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeMethodNotAllowed(w)
+			return
+		}
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			writeError(w, http.StatusInternalServerError, "streaming unsupported")
+			return
+		}
+
+		lastID := r.Header.Get("Last-Event-ID")
+		if lastID == "" {
+			lastID = r.URL.Query().Get("last_event_id")
+		}
+		var after uint64
+		if epoch, seq, ok := strings.Cut(lastID, "-"); ok && epoch == streamEpoch {
+			after, _ = strconv.ParseUint(seq, 10, 64)
+		}
+
+		sub, backlog, complete := mem.Subscribe(after, 256)
+		defer sub.Close()
+
+		h := w.Header()
+		h.Set("Content-Type", "text/event-stream")
+		h.Set("Cache-Control", "no-store")
+		h.Set("Connection", "keep-alive")
+		h.Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+
+		send := func(event string, seq uint64, v any) bool {
+			b, err := json.Marshal(v)
+			if err != nil {
+				return true
+			}
+			if _, err := fmt.Fprintf(w, "id: %s-%d\nevent: %s\ndata: %s\n\n", streamEpoch, seq, event, b); err != nil {
+				return false
+			}
+			return true
+		}
+
+		// Events published between taking the snapshot and subscribing are both in the snapshot
+		// and on the channel; skip those not newer than what was already sent.
+		var sent uint64
+		if complete {
+			for _, e := range backlog {
+				if !send("update", e.Seq, e) {
+					return
+				}
+				sent = e.Seq
+			}
+		} else {
+			snap := mem.SnapshotLatest()
+			if !send("snapshot", snap.Seq, snap) {
+				return
+			}
+			sent = snap.Seq
+		}
+		flusher.Flush()
+
+		ping := time.NewTicker(15 * time.Second)
+		defer ping.Stop()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-ping.C:
+				if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+					return
+				}
+				flusher.Flush()
+			case e, ok := <-sub.C:
+				if !ok {
+					// Dropped for falling behind; the client reconnects and resumes from its last id.
+					return
+				}
+				if e.Seq <= sent {
+					continue
+				}
+				if !send("update", e.Seq, e) {
+					return
+				}
+				sent = e.Seq
+				flusher.Flush()
+			}
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/eastmoney"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/memstore"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/store/sqlite"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/symbol"
+)
+
+// realtimeView is /api/realtime: the snapshot plus per-group aggregates and staleness.
+type realtimeView struct {
+	memstore.Snapshot
+	Groups []groupFlow `json:"groups,omitempty"`
+	// Stale lists dataset keys (see memstore.DatasetMeta) that missed their refresh interval.
+	Stale []string `json:"stale,omitempty"`
+}
+
+// groupFlow sums today's net inflow over the members of a watch group.
+// Covered counts members present in the snapshot; the rest contribute nothing.
+type groupFlow struct {
+	ID      int64    `json:"id"`
+	Name    string   `json:"name"`
+	Tags    []string `json:"tags"`
+	Members int      `json:"members"`
+	Covered int      `json:"covered"`
+	NetMain float64  `json:"net_main"`
+	NetXL   float64  `json:"net_xl"`
+	NetL    float64  `json:"net_l"`
+	NetM    float64  `json:"net_m"`
+	NetS    float64  `json:"net_s"`
+}
+
+// groupFlows computes groupFlow for each group. Members are matched by secid, so an index and a
+// stock sharing a code (000001.SH and 000001.SZ) stay apart. Rows without a secid (seeded from
+// SQLite) fall back to matching the bare code.
+func groupFlows(groups []sqlite.WatchGroup, ff []eastmoney.FundflowRT) []groupFlow {
+	bySecID := make(map[string]eastmoney.FundflowRT, len(ff))
+	byCode := make(map[string]eastmoney.FundflowRT)
+	for _, r := range ff {
+		if r.RawSecID != "" {
+			bySecID[r.RawSecID] = r
+		} else {
+			byCode[r.Code] = r
+		}
+	}
+	out := make([]groupFlow, 0, len(groups))
+	for _, g := range groups {
+		gf := groupFlow{ID: g.ID, Name: g.Name, Tags: g.Tags, Members: len(g.Symbols)}
+		for _, sym := range g.Symbols {
+			secid, err := symbol.ToEastmoneySecID(sym)
+			if err != nil {
+				continue
+			}
+			r, ok := bySecID[secid]
+			if !ok {
+				code, _ := symbol.CodeOnly(sym)
+				if r, ok = byCode[code]; !ok {
+					continue
+				}
+			}
+			gf.Covered++
+			gf.NetMain += r.NetMain
+			gf.NetXL += r.NetXL
+			gf.NetL += r.NetL
+			gf.NetM += r.NetM
+			gf.NetS += r.NetS
+		}
+		out = append(out, gf)
+	}
+	return out
+}
+
+// parseWatchGroup decodes a group from a request body and normalizes it:
+// symbols are upper-cased, de-duplicated and must map to an Eastmoney secid; tags are trimmed.
+func parseWatchGroup(body []byte) (sqlite.WatchGroup, error) {
+	var g sqlite.WatchGroup
+	if err := json.Unmarshal(body, &g); err != nil {
+		return g, err
+	}
+	g.Name = strings.TrimSpace(g.Name)
+	if g.Name == "" {
+		return g, fmt.Errorf("name is required")
+	}
+	g.Note = strings.TrimSpace(g.Note)
+
+	tags := make([]string, 0, len(g.Tags))
+	for _, t := range g.Tags {
+		t = strings.TrimSpace(strings.ReplaceAll(t, ",", " "))
+		if t != "" {
+			tags = append(tags, t)
+		}
+	}
+	g.Tags = tags
+
+	syms := make([]string, 0, len(g.Symbols))
+	seen := make(map[string]struct{}, len(g.Symbols))
+	for _, s := range g.Symbols {
+		s = strings.ToUpper(strings.TrimSpace(s))
+		if s == "" {
+			continue
+		}
+		if _, err := symbol.ToEastmoneySecID(s); err != nil {
+			return g, err
+		}
+		if _, dup := seen[s]; dup {
+			continue
+		}
+		seen[s] = struct{}{}
+		syms = append(syms, s)
+	}
+	g.Symbols = syms
+	return g, nil
+}
+
+func hasTag(g sqlite.WatchGroup, tag string) bool {
+	for _, t := range g.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/eastmoney"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/store/sqlite"
+)
+
+func TestParseWatchGroup(t *testing.T) {
+	g, err := parseWatchGroup([]byte(`{"name": " holdings ", "note": " n ", "tags": ["a,b", " ", "c"],
+		"symbols": ["600519.sh", " 600519.SH", "", "000001.SZ"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := sqlite.WatchGroup{Name: "holdings", Note: "n", Tags: []string{"a b", "c"}, Symbols: []string{"600519.SH", "000001.SZ"}}
+	if !reflect.DeepEqual(g, want) {
+		t.Fatalf("got %+v, want %+v", g, want)
+	}
+
+	for body, msg := range map[string]string{
+		`{"name": " "}`:                           "name is required",
+		`{"name": "x", "symbols": ["600519"]}`:    "symbol must be like",
+		`{"name": "x", "symbols": ["600519.HK"]}`: "unknown market suffix",
+		`{"name": 1}`:                             "cannot unmarshal",
+	} {
+		if _, err := parseWatchGroup([]byte(body)); err == nil || !strings.Contains(err.Error(), msg) {
+			t.Errorf("%s: err=%v, want %q", body, err, msg)
+		}
+	}
+}
+
+func TestGroupFlowsBySecID(t *testing.T) {
+	groups := []sqlite.WatchGroup{
+		{ID: 1, Name: "index", Symbols: []string{"000001.SH"}},
+		{ID: 2, Name: "banks", Symbols: []string{"000001.SZ", "600036.SH", "601398.SH"}},
+	}
+	ff := []eastmoney.FundflowRT{
+		{Code: "000001", RawSecID: "1.000001", NetMain: 1000},
+		{Code: "000001", RawSecID: "0.000001", NetMain: 10, NetXL: 1},
+		{Code: "600036", NetMain: 5}, // seeded from SQLite: no secid
+	}
+	out := groupFlows(groups, ff)
+	if len(out) != 2 {
+		t.Fatalf("out=%+v", out)
+	}
+	if g := out[0]; g.Members != 1 || g.Covered != 1 || g.NetMain != 1000 {
+		t.Fatalf("index=%+v", g)
+	}
+	if g := out[1]; g.Members != 3 || g.Covered != 2 || g.NetMain != 15 || g.NetXL != 1 {
+		t.Fatalf("banks=%+v", g)
+	}
+}
@@ -2,9 +2,9 @@ package main
 
 import (
 	"context"
-	"database/sql"
 	"embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
@@ -12,15 +12,19 @@ import (
 	"net/http"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/auth"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/collector"
 	"github.com/pcdogyu/A-Stock-Order-Flow/internal/config"
 	"github.com/pcdogyu/A-Stock-Order-Flow/internal/eastmoney"
 	"github.com/pcdogyu/A-Stock-Order-Flow/internal/market"
 	"github.com/pcdogyu/A-Stock-Order-Flow/internal/memstore"
 	"github.com/pcdogyu/A-Stock-Order-Flow/internal/runtimecfg"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/store"
 	"github.com/pcdogyu/A-Stock-Order-Flow/internal/store/sqlite"
 	"github.com/pcdogyu/A-Stock-Order-Flow/internal/symbol"
 )
@@ -28,12 +32,29 @@ import (
 //go:embed web/static/*
 var webFS embed.FS
 
-func newWebServer(mgr *runtimecfg.Manager, db *sql.DB, mem *memstore.Store) http.Handler {
+func newWebServer(mgr *runtimecfg.Manager, st store.Store, mem *memstore.Store, job *dailyJob) (http.Handler, error) {
 	if mem == nil {
 		mem = memstore.New()
 	}
+	webCfg := mgr.Get().Web
+	var authn *auth.Authenticator
+	if webCfg.Auth.Enabled {
+		a, err := auth.New(webCfg.Auth)
+		if err != nil {
+			return nil, err
+		}
+		authn = a
+	}
 	em := eastmoney.NewClient()
-	seedMemFromDB(db, mem)
+	if webCfg.ReadOnly {
+		em = eastmoney.NewOfflineClient()
+	}
+	seedSeriesFromDB(st, mem)
+	seededID := seedMemFromDB(st, mem)
+	if webCfg.ReadOnly {
+		// Nothing collects in this process; follow the snapshots another one persists.
+		go runFollowLoop(context.Background(), mgr, st, mem, seededID)
+	}
 	var rtMu sync.Mutex
 	var rtCache memstore.Snapshot
 	var rtCacheAt time.Time
@@ -44,29 +65,50 @@ func newWebServer(mgr *runtimecfg.Manager, db *sql.DB, mem *memstore.Store) http
 	lastCommit := resolveLastCommitTime()
 	mux := http.NewServeMux()
 
-	mux.HandleFunc("/api/health", func(w http.ResponseWriter, r *http.Request) {
-		writeJSON(w, http.StatusOK, map[string]any{"ok": true})
+	handleAPI(mux, "/health", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, healthResponse{OK: true})
 	})
-	mux.HandleFunc("/api/version", func(w http.ResponseWriter, r *http.Request) {
+	spec, err := json.Marshal(openAPISpec())
+	if err != nil {
+		return nil, err
+	}
+	handleAPI(mux, "/openapi.json", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
-			w.WriteHeader(http.StatusMethodNotAllowed)
+			writeMethodNotAllowed(w)
 			return
 		}
-		writeJSON(w, http.StatusOK, map[string]any{"last_commit_time": lastCommit})
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		_, _ = w.Write(spec)
 	})
-
-	mux.HandleFunc("/api/realtime", func(w http.ResponseWriter, r *http.Request) {
+	handleAPI(mux, "/version", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
-			w.WriteHeader(http.StatusMethodNotAllowed)
+			writeMethodNotAllowed(w)
 			return
 		}
-		rtMu.Lock()
-		useCache := time.Since(rtCacheAt) < 10*time.Second && !isSnapshotEmpty(rtCache)
-		snap := rtCache
-		rtMu.Unlock()
+		out := versionResponse{LastCommitTime: lastCommit, ReadOnly: webCfg.ReadOnly}
+		if p, ok := auth.FromContext(r.Context()); ok {
+			out.User = p.Name
+			out.Role = p.Role
+		}
+		writeJSON(w, http.StatusOK, out)
+	})
 
-		if !useCache {
-			if dbSnap, ok, err := sqlite.LoadLatestRTSnapshot(db); err == nil && ok {
+	handleAPI(mux, "/realtime", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeMethodNotAllowed(w)
+			return
+		}
+		// Memory is seeded from SQLite on start and updated on every fetch, so it is never older
+		// than the persisted snapshot; SQLite is only a fallback when memory is still empty.
+		snap := mem.SnapshotLatest()
+		if isSnapshotEmpty(snap) {
+			rtMu.Lock()
+			useCache := time.Since(rtCacheAt) < 10*time.Second && !isSnapshotEmpty(rtCache)
+			cached := rtCache
+			rtMu.Unlock()
+			if useCache {
+				snap = cached
+			} else if dbSnap, ok, err := st.LoadLatestRTSnapshot(); err == nil && ok {
 				ts, err := time.Parse(time.RFC3339Nano, dbSnap.TSUTC)
 				if err != nil {
 					ts = time.Now().UTC()
@@ -83,42 +125,139 @@ func newWebServer(mgr *runtimecfg.Manager, db *sql.DB, mem *memstore.Store) http
 				rtCache = snap
 				rtCacheAt = time.Now()
 				rtMu.Unlock()
+			}
+		}
+		view := realtimeView{Snapshot: snap, Stale: staleDatasets(snap.Meta, mgr.Get(), time.Now())}
+		if groups, err := st.QueryWatchGroups(); err == nil {
+			view.Groups = groupFlows(groups, snap.Fundflow)
+		} else {
+			log.Printf("watch groups err: %v", err)
+		}
+		writeJSON(w, http.StatusOK, view)
+	})
+
+	// Watch groups (named watchlists stored in SQLite; members are collected with the config watchlist):
+	// GET    /api/watchgroups[?tag=xxx]
+	// POST   /api/watchgroups            {"name":"持仓","tags":["core"],"note":"","symbols":["600519.SH"]}
+	// PUT    /api/watchgroups?id=1       (same body; replaces the group)
+	// DELETE /api/watchgroups?id=1
+	handleAPI(mux, "/watchgroups", func(w http.ResponseWriter, r *http.Request) {
+		var id int64
+		if s := strings.TrimSpace(r.URL.Query().Get("id")); s != "" {
+			v, err := strconv.ParseInt(s, 10, 64)
+			if err != nil || v <= 0 {
+				writeError(w, http.StatusBadRequest, "invalid id")
+				return
+			}
+			id = v
+		}
+		switch r.Method {
+		case http.MethodGet:
+			groups, err := st.QueryWatchGroups()
+			if err != nil {
+				writeError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			tag := strings.TrimSpace(r.URL.Query().Get("tag"))
+			out := make([]sqlite.WatchGroup, 0, len(groups))
+			for _, g := range groups {
+				if (id == 0 || g.ID == id) && (tag == "" || hasTag(g, tag)) {
+					out = append(out, g)
+				}
+			}
+			writeJSON(w, http.StatusOK, watchGroupsResponse{Groups: out})
+		case http.MethodPost, http.MethodPut:
+			body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+			if err != nil {
+				writeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			g, err := parseWatchGroup(body)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			if r.Method == http.MethodPost {
+				id, err = st.CreateWatchGroup(g)
+			} else if id == 0 {
+				writeError(w, http.StatusBadRequest, "id is required")
+				return
 			} else {
-				snap = mem.SnapshotLatest()
+				g.ID = id
+				err = st.UpdateWatchGroup(g)
 			}
+			if errors.Is(err, sqlite.ErrNotFound) {
+				writeError(w, http.StatusNotFound, "group not found")
+				return
+			}
+			if err != nil {
+				writeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			writeJSON(w, http.StatusOK, okResponse{OK: true, ID: id})
+		case http.MethodDelete:
+			if id == 0 {
+				writeError(w, http.StatusBadRequest, "id is required")
+				return
+			}
+			if err := st.DeleteWatchGroup(id); err != nil {
+				status := http.StatusInternalServerError
+				if errors.Is(err, sqlite.ErrNotFound) {
+					status = http.StatusNotFound
+				}
+				writeError(w, status, err.Error())
+				return
+			}
+			writeJSON(w, http.StatusOK, okResponse{OK: true})
+		default:
+			writeMethodNotAllowed(w)
 		}
-		writeJSON(w, http.StatusOK, snap)
 	})
 
-	mux.HandleFunc("/api/config", func(w http.ResponseWriter, r *http.Request) {
+	// Realtime updates pushed as Server-Sent Events (see stream.go):
+	// GET /api/stream   (EventSource; resumes from Last-Event-ID)
+	handleAPI(mux, "/stream", newStreamHandler(mem))
+
+	// Realtime updates over WebSocket with per-client subscriptions (protocol in ws.go):
+	// GET /api/ws
+	handleAPI(mux, "/ws", newWSHandler(mem))
+
+	handleAPI(mux, "/config", func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodGet:
 			writeJSON(w, http.StatusOK, toConfigView(mgr.Get()))
 		case http.MethodPost:
 			body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
 			if err != nil {
-				writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+				writeError(w, http.StatusBadRequest, err.Error())
 				return
 			}
 			var p runtimecfg.Patch
 			if err := json.Unmarshal(body, &p); err != nil {
-				writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+				writeError(w, http.StatusBadRequest, err.Error())
 				return
 			}
 			cfg, err := mgr.Update(p)
 			if err != nil {
-				writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+				writeError(w, http.StatusBadRequest, err.Error())
 				return
 			}
 			writeJSON(w, http.StatusOK, toConfigView(cfg))
 		default:
-			w.WriteHeader(http.StatusMethodNotAllowed)
+			writeMethodNotAllowed(w)
 		}
 	})
 
-	mux.HandleFunc("/api/history/market_agg", func(w http.ResponseWriter, r *http.Request) {
+	// Memory series are thinned to the persist interval so a history limit covers the same time
+	// window as the SQLite rows it falls back to.
+	persistStep := func() time.Duration {
+		return time.Duration(mgr.Get().Persist.IntervalSeconds) * time.Second
+	}
+	boardSumTS := func(p memstore.BoardSumPoint) time.Time { return p.TSUTC }
+
+	handleAPI(mux, "/history/market_agg", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
-			w.WriteHeader(http.StatusMethodNotAllowed)
+			writeMethodNotAllowed(w)
 			return
 		}
 		kind := r.URL.Query().Get("kind")
@@ -128,30 +267,36 @@ func newWebServer(mgr *runtimecfg.Manager, db *sql.DB, mem *memstore.Store) http
 		source := r.URL.Query().Get("source")
 		fid := r.URL.Query().Get("fid")
 		if source == "" || fid == "" {
-			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "source and fid are required"})
+			writeError(w, http.StatusBadRequest, "source and fid are required")
 			return
 		}
 		limit := parseLimit(r.URL.Query().Get("limit"), 200, 2000)
 		if kind == "rt" {
-			rows, err := sqlite.QueryMarketAggRT(db, source, fid, limit)
+			var live []sqlite.MarketAggRTPoint
+			for _, p := range persistCadence(mem.AggSeries(source, fid), func(p memstore.Point) time.Time { return p.TSUTC }, persistStep()) {
+				live = append(live, sqlite.MarketAggRTPoint{TSUTC: sqlite.FixedRFC3339Nano(p.TSUTC), Value: p.Value})
+			}
+			rows, err := memFirst(live, limit, func(p sqlite.MarketAggRTPoint) string { return p.TSUTC }, func() ([]sqlite.MarketAggRTPoint, error) {
+				return st.QueryMarketAggRT(source, fid, limit)
+			})
 			if err != nil {
-				writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+				writeError(w, http.StatusInternalServerError, err.Error())
 				return
 			}
 			writeJSON(w, http.StatusOK, rows)
 			return
 		}
-		rows, err := sqlite.QueryMarketAggDaily(db, source, fid, limit)
+		rows, err := st.QueryMarketAggDaily(source, fid, limit)
 		if err != nil {
-			writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+			writeError(w, http.StatusInternalServerError, err.Error())
 			return
 		}
 		writeJSON(w, http.StatusOK, rows)
 	})
 
-	mux.HandleFunc("/api/history/board_sum", func(w http.ResponseWriter, r *http.Request) {
+	handleAPI(mux, "/history/board_sum", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
-			w.WriteHeader(http.StatusMethodNotAllowed)
+			writeMethodNotAllowed(w)
 			return
 		}
 		kind := r.URL.Query().Get("kind")
@@ -171,27 +316,98 @@ func newWebServer(mgr *runtimecfg.Manager, db *sql.DB, mem *memstore.Store) http
 		}
 		limit := parseLimit(r.URL.Query().Get("limit"), 200, 2000)
 		if kind == "rt" {
-			rows, err := sqlite.QueryBoardSumRT(db, tp, fid, limit)
+			var live []sqlite.BoardSumRTPoint
+			for _, p := range persistCadence(mem.BoardSumSeries(tp, fid), boardSumTS, persistStep()) {
+				live = append(live, sqlite.BoardSumRTPoint{TSUTC: sqlite.FixedRFC3339Nano(p.TSUTC), Value: p.Value})
+			}
+			rows, err := memFirst(live, limit, func(p sqlite.BoardSumRTPoint) string { return p.TSUTC }, func() ([]sqlite.BoardSumRTPoint, error) {
+				return st.QueryBoardSumRT(tp, fid, limit)
+			})
 			if err != nil {
-				writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+				writeError(w, http.StatusInternalServerError, err.Error())
 				return
 			}
 			writeJSON(w, http.StatusOK, rows)
 			return
 		}
-		rows, err := sqlite.QueryBoardSumDaily(db, tp, fid, limit)
+		rows, err := st.QueryBoardSumDaily(tp, fid, limit)
 		if err != nil {
-			writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+			writeError(w, http.StatusInternalServerError, err.Error())
 			return
 		}
 		writeJSON(w, http.StatusOK, rows)
 	})
 
+	// Today's in-memory intraday series (reset at the first sample of a new trade date):
+	// GET /api/intraday?dataset=fundflow&code=600519
+	// GET /api/intraday?dataset=northbound
+	handleAPI(mux, "/intraday", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeMethodNotAllowed(w)
+			return
+		}
+		switch r.URL.Query().Get("dataset") {
+		case "fundflow":
+			code := strings.TrimSpace(r.URL.Query().Get("code"))
+			if code == "" {
+				writeError(w, http.StatusBadRequest, "code is required (e.g. 600519)")
+				return
+			}
+			writeJSON(w, http.StatusOK, fundflowIntraday{TradeDate: mem.SeriesDate(), Code: code, Points: mem.FundflowSeries(code)})
+		case "northbound":
+			writeJSON(w, http.StatusOK, northboundIntraday{TradeDate: mem.SeriesDate(), Points: mem.NorthboundSeries()})
+		default:
+			writeError(w, http.StatusBadRequest, "dataset must be fundflow or northbound")
+		}
+	})
+
+	// Rolled-up OHLC bars of rt series (kept longer than raw rows, see retention in config):
+	// GET /api/bars?period=5m&dataset=agg&key=industry_sum:f62&field=value&days=30
+	// keys: board type:fid:code, fundflow code (fields net_main..net_s), agg source:fid, northbound sh|sz
+	handleAPI(mux, "/bars", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeMethodNotAllowed(w)
+			return
+		}
+		q := r.URL.Query()
+		period := q.Get("period")
+		if period == "" {
+			period = "5m"
+		}
+		if _, ok := sqlite.BarPeriods[period]; !ok {
+			writeError(w, http.StatusBadRequest, "period must be 1m or 5m")
+			return
+		}
+		dataset, key, field := q.Get("dataset"), strings.TrimSpace(q.Get("key")), q.Get("field")
+		if dataset == "" || key == "" {
+			writeError(w, http.StatusBadRequest, "dataset and key are required")
+			return
+		}
+		if field == "" {
+			field = "value"
+			switch dataset {
+			case "fundflow":
+				field = "net_main"
+			case "northbound":
+				field = "day_net_amt_in"
+			}
+		}
+		days := parseLimit(q.Get("days"), 5, 3650)
+		now := time.Now().UTC()
+		bars, err := st.QueryBars(period, dataset, key, field,
+			sqlite.FixedRFC3339Nano(now.AddDate(0, 0, -days)), sqlite.FixedRFC3339Nano(now))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, barsResponse{Period: period, Dataset: dataset, Key: key, Field: field, Bars: bars})
+	})
+
 	// Board price sum (intraday, from board_rt.price):
 	// GET /api/history/board_price_sum?type=industry|concept&fid=f62&limit=1200
-	mux.HandleFunc("/api/history/board_price_sum", func(w http.ResponseWriter, r *http.Request) {
+	handleAPI(mux, "/history/board_price_sum", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
-			w.WriteHeader(http.StatusMethodNotAllowed)
+			writeMethodNotAllowed(w)
 			return
 		}
 		tp := r.URL.Query().Get("type")
@@ -211,19 +427,233 @@ func newWebServer(mgr *runtimecfg.Manager, db *sql.DB, mem *memstore.Store) http
 		now := time.Now().In(loc)
 		start := time.Date(now.Year(), now.Month(), now.Day(), 9, 30, 0, 0, loc).UTC()
 		end := time.Date(now.Year(), now.Month(), now.Day(), 15, 0, 0, 0, loc).UTC()
-		rows, err := sqlite.QueryBoardPriceSumRT(db, tp, fid, sqlite.FixedRFC3339Nano(start), sqlite.FixedRFC3339Nano(end), limit)
+		if mem.SeriesDate() == now.Format("2006-01-02") {
+			pts := persistCadence(inSession(mem.BoardSumSeries(tp, fid), boardSumTS, start, end), boardSumTS, persistStep())
+			if len(pts) > 0 {
+				if len(pts) > limit {
+					pts = pts[:limit]
+				}
+				rows := make([]sqlite.BoardSumRTPoint, 0, len(pts))
+				for _, p := range pts {
+					rows = append(rows, sqlite.BoardSumRTPoint{TSUTC: sqlite.FixedRFC3339Nano(p.TSUTC), Value: p.Price})
+				}
+				writeJSON(w, http.StatusOK, rows)
+				return
+			}
+		}
+		rows, err := st.QueryBoardPriceSumRT(tp, fid, sqlite.FixedRFC3339Nano(start), sqlite.FixedRFC3339Nano(end), limit)
 		if err != nil {
-			writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+			writeError(w, http.StatusInternalServerError, err.Error())
 			return
 		}
 		writeJSON(w, http.StatusOK, rows)
 	})
 
+	// After-close daily snapshot job (in-process):
+	// GET  /api/daily/job
+	// POST /api/daily/job?date=YYYY-MM-DD   (run now; default: Asia/Shanghai today)
+	handleAPI(mux, "/daily/job", func(w http.ResponseWriter, r *http.Request) {
+		if job == nil {
+			writeError(w, http.StatusNotFound, "daily job not available")
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, job.Status())
+		case http.MethodPost:
+			loc, _ := time.LoadLocation("Asia/Shanghai")
+			d := time.Now().In(loc)
+			if s := strings.TrimSpace(r.URL.Query().Get("date")); s != "" {
+				t, err := time.ParseInLocation("2006-01-02", s, loc)
+				if err != nil {
+					writeError(w, http.StatusBadRequest, err.Error())
+					return
+				}
+				d = t
+			}
+			if job.Status().Running {
+				writeError(w, http.StatusConflict, "daily job already running")
+				return
+			}
+			go job.Run(context.Background(), d, mgr.Get().DailyJob, "manual")
+			writeJSON(w, http.StatusAccepted, dailyJobStartResponse{OK: true, TradeDate: d.Format("2006-01-02")})
+		default:
+			writeMethodNotAllowed(w)
+		}
+	})
+
+	// Online database backup (same as `aof backup`, rotated by backup.keep):
+	// POST /api/admin/backup
+	var backupMu sync.Mutex
+	handleAPI(mux, "/admin/backup", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeMethodNotAllowed(w)
+			return
+		}
+		if !backupMu.TryLock() {
+			writeError(w, http.StatusConflict, "backup already running")
+			return
+		}
+		defer backupMu.Unlock()
+		cfg := mgr.Get()
+		start := time.Now()
+		path, err := collector.Backup(st, cfg.Backup.Dir, cfg.Backup.Keep, start.UTC())
+		if err != nil && path == "" {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		resp := backupResponse{OK: true, Path: path, ElapsedMS: time.Since(start).Milliseconds()}
+		if fi, statErr := os.Stat(path); statErr == nil {
+			resp.SizeBytes = fi.Size()
+		}
+		if err != nil {
+			resp.Warning = err.Error()
+		}
+		writeJSON(w, http.StatusOK, resp)
+	})
+
+	// Daily run log / completeness:
+	// GET /api/daily/runs?limit=20              (recent runs)
+	// GET /api/daily/runs?date=YYYY-MM-DD       (runs of a trade date + per-item completeness)
+	handleAPI(mux, "/daily/runs", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeMethodNotAllowed(w)
+			return
+		}
+		date := strings.TrimSpace(r.URL.Query().Get("date"))
+		limit := parseLimit(r.URL.Query().Get("limit"), 20, 500)
+		runs, err := st.QueryDailyRuns(date, limit)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if date == "" {
+			writeJSON(w, http.StatusOK, dailyRunsResponse{Runs: runs})
+			return
+		}
+		for i := range runs {
+			items, err := st.QueryDailyRunItems(runs[i].ID)
+			if err != nil {
+				writeError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			runs[i].Items = items
+		}
+		status, err := st.QueryDailyItemStatus(date)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		var ok int
+		failed := make([]sqlite.DailyRunItem, 0)
+		for _, it := range status {
+			if it.Status == sqlite.DailyItemOK {
+				ok++
+			} else {
+				failed = append(failed, it)
+			}
+		}
+		writeJSON(w, http.StatusOK, dailyRunsResponse{
+			TradeDate: date,
+			Runs:      runs,
+			Completeness: &dailyCompleteness{
+				Total:    len(status),
+				OK:       ok,
+				Failed:   failed,
+				Complete: len(status) > 0 && len(failed) == 0,
+			},
+		})
+	})
+
+	// Call auction ranking ("auction main flow"):
+	// GET /api/auction/rank?phase=open|close&date=YYYY-MM-DD&source=watchlist|toplist&sort=flow&limit=50
+	handleAPI(mux, "/auction/rank", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeMethodNotAllowed(w)
+			return
+		}
+		phase := r.URL.Query().Get("phase")
+		if phase == "" {
+			phase = market.PhaseOpenAuction
+		}
+		if phase != market.PhaseOpenAuction && phase != market.PhaseCloseAuction {
+			writeError(w, http.StatusBadRequest, "phase must be open or close")
+			return
+		}
+		source := r.URL.Query().Get("source")
+		if source != "" && source != "watchlist" && source != "toplist" {
+			writeError(w, http.StatusBadRequest, "source must be watchlist or toplist")
+			return
+		}
+		sortBy := r.URL.Query().Get("sort")
+		if sortBy == "" {
+			sortBy = "flow"
+		}
+		limit := parseLimit(r.URL.Query().Get("limit"), 50, 500)
+		date := strings.TrimSpace(r.URL.Query().Get("date"))
+		if date == "" {
+			d, err := st.QueryAuctionLatestTradeDate(phase)
+			if err != nil {
+				writeError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			date = d
+		}
+		ts, rows, err := st.QueryAuctionRank(date, phase, source, sortBy, limit)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, auctionRankResponse{
+			TradeDate: date,
+			Phase:     phase,
+			Source:    source,
+			Sort:      sortBy,
+			TSUTC:     ts,
+			Rows:      rows,
+		})
+	})
+
+	// Intraday flow velocity from persisted per-interval deltas (latest trade date):
+	// GET /api/fundflow/velocity?window=5m&source=watchlist|toplist&sort=accel|decel|inflow|outflow&limit=50
+	handleAPI(mux, "/fundflow/velocity", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeMethodNotAllowed(w)
+			return
+		}
+		window := 5 * time.Minute
+		if s := strings.TrimSpace(r.URL.Query().Get("window")); s != "" {
+			d, err := time.ParseDuration(s)
+			if err != nil || d < time.Minute || d > 4*time.Hour {
+				writeError(w, http.StatusBadRequest, "window must be a duration between 1m and 4h")
+				return
+			}
+			window = d
+		}
+		source := r.URL.Query().Get("source")
+		if source != "" && source != "watchlist" && source != "toplist" {
+			writeError(w, http.StatusBadRequest, "source must be watchlist or toplist")
+			return
+		}
+		sort := r.URL.Query().Get("sort")
+		limit := parseLimit(r.URL.Query().Get("limit"), 50, 500)
+		ts, rows, err := st.QueryFundflowVelocity(window, source, sort, limit)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, velocityResponse{
+			TSUTC:  ts,
+			Window: window.String(),
+			Rows:   rows,
+		})
+	})
+
 	// Board list from in-memory snapshot:
 	// GET /api/boards?type=industry|concept&fid=f62&limit=50
-	mux.HandleFunc("/api/boards", func(w http.ResponseWriter, r *http.Request) {
+	handleAPI(mux, "/boards", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
-			w.WriteHeader(http.StatusMethodNotAllowed)
+			writeMethodNotAllowed(w)
 			return
 		}
 		tp := r.URL.Query().Get("type")
@@ -256,16 +686,11 @@ func newWebServer(mgr *runtimecfg.Manager, db *sql.DB, mem *memstore.Store) http
 		if interval <= 0 {
 			interval = 60 * time.Second
 		}
-		stale := true
-		if ts := mem.BoardTS(tp, fid); !ts.IsZero() {
-			if now.Sub(ts) <= 2*interval {
-				stale = false
-			}
-		}
+		stale := isStale(mem.Meta(memstore.DatasetKeyBoard(tp, fid)), interval, now)
 
 		rows := []eastmoney.TopItem(nil)
 		if !market.IsCNTradingTime(now) {
-			if _, dbRows, err := sqlite.QueryBoardRTLatest(db, tp, fid, limit); err == nil && len(dbRows) > 0 {
+			if _, dbRows, err := st.QueryBoardRTLatest(tp, fid, limit); err == nil && len(dbRows) > 0 {
 				rows = dbRows
 			}
 		}
@@ -275,7 +700,7 @@ func newWebServer(mgr *runtimecfg.Manager, db *sql.DB, mem *memstore.Store) http
 			rows = snap.BoardsByKey[key]
 		}
 		if len(rows) == 0 {
-			if _, dbRows, err := sqlite.QueryBoardRTLatest(db, tp, fid, limit); err == nil && len(dbRows) > 0 {
+			if _, dbRows, err := st.QueryBoardRTLatest(tp, fid, limit); err == nil && len(dbRows) > 0 {
 				rows = dbRows
 			}
 		}
@@ -306,30 +731,23 @@ func newWebServer(mgr *runtimecfg.Manager, db *sql.DB, mem *memstore.Store) http
 		if len(rows) > limit {
 			rows = rows[:limit]
 		}
-		type boardInfo struct {
-			Code  string  `json:"code"`
-			Name  string  `json:"name"`
-			Value float64 `json:"value"`
-			Pct   float64 `json:"pct"`
-			Price float64 `json:"price"`
-		}
 		out := make([]boardInfo, 0, len(rows))
 		for _, it := range rows {
 			out = append(out, boardInfo{Code: it.Code, Name: it.Name, Value: it.Value, Pct: it.Pct, Price: it.Price})
 		}
-		writeJSON(w, http.StatusOK, map[string]any{"rows": out, "from_live": fromLive})
+		writeJSON(w, http.StatusOK, boardsResponse{Rows: out, FromLive: fromLive})
 	})
 
 	// Board constituents (stocks with today's move):
 	// GET /api/board/constituents?board=BK0457&pn=1&pz=50
-	mux.HandleFunc("/api/board/constituents", func(w http.ResponseWriter, r *http.Request) {
+	handleAPI(mux, "/board/constituents", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
-			w.WriteHeader(http.StatusMethodNotAllowed)
+			writeMethodNotAllowed(w)
 			return
 		}
 		board := r.URL.Query().Get("board")
 		if board == "" {
-			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "board is required (e.g. BK0457)"})
+			writeError(w, http.StatusBadRequest, "board is required (e.g. BK0457)")
 			return
 		}
 		pn := parseLimit(r.URL.Query().Get("pn"), 1, 1000000)
@@ -338,7 +756,7 @@ func newWebServer(mgr *runtimecfg.Manager, db *sql.DB, mem *memstore.Store) http
 		if err != nil {
 			select {
 			case <-r.Context().Done():
-				writeJSON(w, http.StatusBadGateway, map[string]any{"error": r.Context().Err().Error()})
+				writeError(w, http.StatusBadGateway, r.Context().Err().Error())
 				return
 			case <-time.After(300 * time.Millisecond):
 			}
@@ -346,16 +764,16 @@ func newWebServer(mgr *runtimecfg.Manager, db *sql.DB, mem *memstore.Store) http
 		}
 		if err != nil {
 			if cached, ok := boardCache.Get(board, pn, pz); ok {
-				writeJSON(w, http.StatusOK, map[string]any{
-					"total":  cached.total,
-					"rows":   cached.rows,
-					"ts_utc": cached.tsUTC,
-					"cached": true,
-					"error":  err.Error(),
+				writeJSON(w, http.StatusOK, constituentsResponse{
+					Total:   cached.total,
+					Rows:    cached.rows,
+					TSUTC:   cached.tsUTC,
+					Cached:  true,
+					Warning: err.Error(),
 				})
 				return
 			}
-			writeJSON(w, http.StatusBadGateway, map[string]any{
+			writeLegacyError(w, upstreamStatus(err), "board constituents fetch failed: "+err.Error(), map[string]any{
 				"error":   err.Error(),
 				"board":   board,
 				"pn":      pn,
@@ -366,19 +784,19 @@ func newWebServer(mgr *runtimecfg.Manager, db *sql.DB, mem *memstore.Store) http
 			return
 		}
 		boardCache.Set(board, pn, pz, total, rows)
-		writeJSON(w, http.StatusOK, map[string]any{"total": total, "rows": rows, "ts_utc": time.Now().UTC()})
+		writeJSON(w, http.StatusOK, constituentsResponse{Total: total, Rows: rows, TSUTC: time.Now().UTC()})
 	})
 
 	// Board daily history (fundflow):
 	// GET /api/board/daily?board=BK0457&type=industry&fid=f62&limit=90&refresh=1
-	mux.HandleFunc("/api/board/daily", func(w http.ResponseWriter, r *http.Request) {
+	handleAPI(mux, "/board/daily", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
-			w.WriteHeader(http.StatusMethodNotAllowed)
+			writeMethodNotAllowed(w)
 			return
 		}
 		board := strings.TrimSpace(r.URL.Query().Get("board"))
 		if board == "" {
-			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "board is required (e.g. BK0457)"})
+			writeError(w, http.StatusBadRequest, "board is required (e.g. BK0457)")
 			return
 		}
 		tp := r.URL.Query().Get("type")
@@ -407,9 +825,9 @@ func newWebServer(mgr *runtimecfg.Manager, db *sql.DB, mem *memstore.Store) http
 			}
 		}
 
-		points, name, err := sqlite.QueryBoardDailyByCode(db, tp, fid, board, limit)
+		points, name, err := st.QueryBoardDailyByCode(tp, fid, board, limit)
 		if err != nil {
-			writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+			writeError(w, http.StatusInternalServerError, err.Error())
 			return
 		}
 		needFetch := len(points) < limit || refresh == "1" || refresh == "true"
@@ -437,22 +855,22 @@ func newWebServer(mgr *runtimecfg.Manager, db *sql.DB, mem *memstore.Store) http
 						name = row.Name
 					}
 				}
-				if err := sqlite.UpsertBoardDailySeries(db, tp, fid, series); err != nil {
+				if err := st.UpsertBoardDailySeries(tp, fid, series); err != nil {
 					fetchErr = err
 				}
 			}
-			points, name, _ = sqlite.QueryBoardDailyByCode(db, tp, fid, board, limit)
+			points, name, _ = st.QueryBoardDailyByCode(tp, fid, board, limit)
 		}
 
-		out := map[string]any{
-			"board":  board,
-			"name":   name,
-			"points": points,
-			"fid":    fid,
-			"type":   tp,
+		out := boardDailyResponse{
+			Board:  board,
+			Name:   name,
+			Points: points,
+			FID:    fid,
+			Type:   tp,
 		}
 		if fetchErr != nil {
-			out["error"] = fetchErr.Error()
+			out.Warning = fetchErr.Error()
 		}
 		writeJSON(w, http.StatusOK, out)
 	})
@@ -460,7 +878,7 @@ func newWebServer(mgr *runtimecfg.Manager, db *sql.DB, mem *memstore.Store) http
 	// Batch load board daily history into SQLite (long running).
 	// POST /api/board/daily/batch?type=concept&limit=360
 	// GET  /api/board/daily/batch?type=concept
-	mux.HandleFunc("/api/board/daily/batch", func(w http.ResponseWriter, r *http.Request) {
+	handleAPI(mux, "/board/daily/batch", func(w http.ResponseWriter, r *http.Request) {
 		tp := r.URL.Query().Get("type")
 		if tp == "" {
 			tp = "concept"
@@ -483,7 +901,7 @@ func newWebServer(mgr *runtimecfg.Manager, db *sql.DB, mem *memstore.Store) http
 				bcfg = cfg.Industry
 			}
 			if bcfg.FS == "" {
-				writeJSON(w, http.StatusBadRequest, map[string]any{"error": "board fs not configured"})
+				writeError(w, http.StatusBadRequest, "board fs not configured")
 				return
 			}
 			fid := bcfg.FID
@@ -491,34 +909,34 @@ func newWebServer(mgr *runtimecfg.Manager, db *sql.DB, mem *memstore.Store) http
 				fid = "f62"
 			}
 			ok := boardDailyBatch.Start(tp, func(job *boardDailyJob) {
-				runBoardDailyBatch(job, em, db, tp, bcfg.FS, fid, limit)
+				runBoardDailyBatch(job, em, st, tp, bcfg.FS, fid, limit)
 			})
 			if !ok {
-				writeJSON(w, http.StatusConflict, map[string]any{"error": "batch already running"})
+				writeError(w, http.StatusConflict, "batch already running")
 				return
 			}
 			writeJSON(w, http.StatusAccepted, boardDailyBatch.Status(tp))
 			return
 		default:
-			w.WriteHeader(http.StatusMethodNotAllowed)
+			writeMethodNotAllowed(w)
 			return
 		}
 	})
 
 	// Board intraday trend (today):
 	// GET /api/board/trend?board=BK0457
-	mux.HandleFunc("/api/board/trend", func(w http.ResponseWriter, r *http.Request) {
+	handleAPI(mux, "/board/trend", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
-			w.WriteHeader(http.StatusMethodNotAllowed)
+			writeMethodNotAllowed(w)
 			return
 		}
 		board := strings.TrimSpace(r.URL.Query().Get("board"))
 		if board == "" {
-			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "board is required (e.g. BK0457)"})
+			writeError(w, http.StatusBadRequest, "board is required (e.g. BK0457)")
 			return
 		}
 		if cached, ok := boardTrendCache.Get(board); ok {
-			writeJSON(w, http.StatusOK, map[string]any{"board": board, "points": cached.points, "ts_utc": cached.tsUTC, "cached": true})
+			writeJSON(w, http.StatusOK, trendResponse{Board: board, Points: cached.points, TSUTC: cached.tsUTC, Cached: true})
 			return
 		}
 		// Prefer SQLite intraday series (from board_rt) to avoid flaky external endpoints.
@@ -527,7 +945,7 @@ func newWebServer(mgr *runtimecfg.Manager, db *sql.DB, mem *memstore.Store) http
 		trendDay := now
 		hm := now.Hour()*60 + now.Minute()
 		if hm < 9*60+30 {
-			if ts, err := sqlite.QueryBoardRTLatestTimestampByCode(db, board); err == nil && ts != "" {
+			if ts, err := st.QueryBoardRTLatestTimestampByCode(board); err == nil && ts != "" {
 				if t, err := time.Parse(time.RFC3339Nano, ts); err == nil {
 					trendDay = t.In(loc)
 				}
@@ -535,7 +953,17 @@ func newWebServer(mgr *runtimecfg.Manager, db *sql.DB, mem *memstore.Store) http
 		}
 		start := time.Date(trendDay.Year(), trendDay.Month(), trendDay.Day(), 9, 30, 0, 0, loc).UTC()
 		end := time.Date(trendDay.Year(), trendDay.Month(), trendDay.Day(), 15, 0, 0, 0, loc).UTC()
-		rows, err := sqlite.QueryBoardRTSeriesByCode(db, board, sqlite.FixedRFC3339Nano(start), sqlite.FixedRFC3339Nano(end), 1200)
+		// Today's series is served from memory; SQLite only covers older days (e.g. before the open).
+		var rows []sqlite.BoardRTPoint
+		var err error
+		if mem.SeriesDate() == trendDay.Format("2006-01-02") {
+			for _, p := range inSession(mem.BoardSeries(board), func(p memstore.BoardPoint) time.Time { return p.TSUTC }, start, end) {
+				rows = append(rows, sqlite.BoardRTPoint{TSUTC: sqlite.FixedRFC3339Nano(p.TSUTC), Value: p.Price})
+			}
+		}
+		if len(rows) == 0 {
+			rows, err = st.QueryBoardRTSeriesByCode(board, sqlite.FixedRFC3339Nano(start), sqlite.FixedRFC3339Nano(end), 1200)
+		}
 		if err == nil && len(rows) >= 2 {
 			// Some board types (notably concept) may have a stale/flat "price" in clist snapshots.
 			// If the intraday series looks stale (flat or too few distinct values), prefer the dedicated trend endpoint.
@@ -576,68 +1004,127 @@ func newWebServer(mgr *runtimecfg.Manager, db *sql.DB, mem *memstore.Store) http
 				}
 			}
 			boardTrendCache.Set(board, points)
-			writeJSON(w, http.StatusOK, map[string]any{"board": board, "points": points, "ts_utc": time.Now().UTC()})
+			writeJSON(w, http.StatusOK, trendResponse{Board: board, Points: points, TSUTC: time.Now().UTC()})
 			return
 		}
 
 	fetchFromRemote:
 		points, err := em.BoardTrends1D(r.Context(), board)
 		if err != nil {
-			writeJSON(w, http.StatusBadGateway, map[string]any{"error": err.Error(), "board": board})
+			writeLegacyError(w, upstreamStatus(err), err.Error(), map[string]any{"error": err.Error(), "board": board})
 			return
 		}
 		boardTrendCache.Set(board, points)
-		writeJSON(w, http.StatusOK, map[string]any{"board": board, "points": points, "ts_utc": time.Now().UTC()})
+		writeJSON(w, http.StatusOK, trendResponse{Board: board, Points: points, TSUTC: time.Now().UTC()})
 	})
 
 	// Stock intraday trend (today):
 	// GET /api/stock/trend?code=600519
-	mux.HandleFunc("/api/stock/trend", func(w http.ResponseWriter, r *http.Request) {
+	handleAPI(mux, "/stock/trend", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
-			w.WriteHeader(http.StatusMethodNotAllowed)
+			writeMethodNotAllowed(w)
 			return
 		}
 		code := strings.TrimSpace(r.URL.Query().Get("code"))
 		if code == "" {
-			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "code is required (e.g. 600519)"})
+			writeError(w, http.StatusBadRequest, "code is required (e.g. 600519)")
 			return
 		}
 		secid, err := symbol.ToEastmoneySecIDFromCode(code)
 		if err != nil {
-			writeJSON(w, http.StatusBadRequest, map[string]any{"error": err.Error()})
+			writeError(w, http.StatusBadRequest, err.Error())
 			return
 		}
 		points, err := em.StockTrends1D(r.Context(), secid)
 		if err != nil {
-			writeJSON(w, http.StatusBadGateway, map[string]any{"error": err.Error()})
+			writeError(w, upstreamStatus(err), err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, trendResponse{Code: code, SecID: secid, Points: points, TSUTC: time.Now().UTC()})
+	})
+
+	// Security search over the local security master (code prefix, name, pinyin initials):
+	// GET /api/search?q=gzmt&kind=stock,etf&limit=20
+	handleAPI(mux, "/search", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeMethodNotAllowed(w)
+			return
+		}
+		q := strings.TrimSpace(r.URL.Query().Get("q"))
+		if q == "" {
+			writeError(w, http.StatusBadRequest, "q is required (code, name or pinyin initials, e.g. gzmt)")
+			return
+		}
+		kinds := splitCodes(r.URL.Query().Get("kind"))
+		for _, k := range kinds {
+			switch k {
+			case "stock", "index", "etf", "industry", "concept":
+			default:
+				writeError(w, http.StatusBadRequest, "kind must be stock, index, etf, industry or concept: "+k)
+				return
+			}
+		}
+		limit := 20
+		if v := strings.TrimSpace(r.URL.Query().Get("limit")); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				writeError(w, http.StatusBadRequest, "limit must be a positive integer")
+				return
+			}
+			limit = min(n, 100)
+		}
+		rows, err := st.SearchSecurities(q, kinds, limit)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
 			return
 		}
-		writeJSON(w, http.StatusOK, map[string]any{"code": code, "secid": secid, "points": points, "ts_utc": time.Now().UTC()})
+		out := searchResponse{Query: q, Results: make([]searchResult, 0, len(rows))}
+		for _, s := range rows {
+			out.Results = append(out.Results, searchResult{Security: s, Symbol: s.Symbol()})
+		}
+		if len(rows) == 0 {
+			if n, _, err := st.QuerySecuritiesUpdated(); err == nil && n == 0 {
+				out.Warning = "security master is empty; it is refreshed from Eastmoney on start and daily at securities.run_at"
+			}
+		}
+		writeJSON(w, http.StatusOK, out)
 	})
 
+	// Stock drill-down (see stock.go); history missing from SQLite is fetched and stored:
+	// GET /api/stock/detail?code=600519&days=60
+	handleAPI(mux, "/stock/detail", newStockDetail(st, mem, em, secidTrendCache).ServeHTTP)
+
 	// SecID intraday trend (today):
 	// GET /api/secid/trend?secid=1.000001
-	mux.HandleFunc("/api/secid/trend", func(w http.ResponseWriter, r *http.Request) {
+	handleAPI(mux, "/secid/trend", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
-			w.WriteHeader(http.StatusMethodNotAllowed)
+			writeMethodNotAllowed(w)
 			return
 		}
 		secid := strings.TrimSpace(r.URL.Query().Get("secid"))
 		if secid == "" {
-			writeJSON(w, http.StatusBadRequest, map[string]any{"error": "secid is required (e.g. 1.000001)"})
+			writeError(w, http.StatusBadRequest, "secid is required (e.g. 1.000001)")
 			return
 		}
 		if cached, ok := secidTrendCache.Get(secid); ok {
-			writeJSON(w, http.StatusOK, map[string]any{"secid": secid, "points": cached.points, "ts_utc": cached.tsUTC, "cached": true})
+			writeJSON(w, http.StatusOK, trendResponse{SecID: secid, Points: cached.points, TSUTC: cached.tsUTC, Cached: true})
 			return
 		}
 		points, err := em.StockTrends1D(r.Context(), secid)
 		if err != nil {
-			writeJSON(w, http.StatusBadGateway, map[string]any{"error": err.Error(), "secid": secid})
+			writeLegacyError(w, upstreamStatus(err), err.Error(), map[string]any{"error": err.Error(), "secid": secid})
 			return
 		}
 		secidTrendCache.Set(secid, points)
-		writeJSON(w, http.StatusOK, map[string]any{"secid": secid, "points": points, "ts_utc": time.Now().UTC()})
+		writeJSON(w, http.StatusOK, trendResponse{SecID: secid, Points: points, TSUTC: time.Now().UTC()})
+	})
+
+	// Daily history as CSV/XLSX (see export.go):
+	// GET /api/export/fundflow?codes=600519,000001&from=2024-01-01&to=2024-03-31&format=xlsx&headers=zh
+	handleAPI(mux, "/export/", newExportHandler(st))
+
+	handleAPI(mux, "/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, "no such endpoint: "+r.URL.Path)
 	})
 
 	// Static UI.
@@ -665,17 +1152,23 @@ func newWebServer(mgr *runtimecfg.Manager, db *sql.DB, mem *memstore.Store) http
 		_, _ = w.Write(b)
 	})
 
-	return logRequests(mux)
+	return logRequests(aliasShapes(guardRequests(mux, authn, webCfg.ReadOnly))), nil
 }
 
-func seedMemFromDB(db *sql.DB, mem *memstore.Store) {
-	if db == nil || mem == nil {
-		return
+// seedMemFromDB loads the latest persisted snapshot into memory and returns its id (0 if none).
+func seedMemFromDB(st store.Store, mem *memstore.Store) int64 {
+	if st == nil || mem == nil {
+		return 0
 	}
-	snap, ok, err := sqlite.LoadLatestRTSnapshot(db)
+	snap, ok, err := st.LoadLatestRTSnapshot()
 	if err != nil || !ok {
-		return
+		return 0
 	}
+	applyRTSnapshot(mem, snap)
+	return snap.ID
+}
+
+func applyRTSnapshot(mem *memstore.Store, snap sqlite.RTSnapshot) {
 	ts, err := time.Parse(time.RFC3339Nano, snap.TSUTC)
 	if err != nil {
 		ts = time.Now().UTC()
@@ -940,7 +1433,7 @@ func (j *boardDailyJob) finish() {
 	j.mu.Unlock()
 }
 
-func runBoardDailyBatch(job *boardDailyJob, em *eastmoney.Client, db *sql.DB, tp, fs, fid string, limit int) {
+func runBoardDailyBatch(job *boardDailyJob, em *eastmoney.Client, st store.Store, tp, fs, fid string, limit int) {
 	defer job.finish()
 	ctx := context.Background()
 	items, err := em.BoardListAll(ctx, fs, fid)
@@ -992,7 +1485,7 @@ func runBoardDailyBatch(job *boardDailyJob, em *eastmoney.Client, db *sql.DB, tp
 				Pct:       0,
 			})
 		}
-		if err := sqlite.UpsertBoardDailySeries(db, tp, fid, series); err != nil {
+		if err := st.UpsertBoardDailySeries(tp, fid, series); err != nil {
 			job.markFail(err)
 			time.Sleep(200 * time.Millisecond)
 			continue
@@ -1010,6 +1503,7 @@ func formatRFC3339Nano(t time.Time) string {
 }
 
 type configView struct {
+	DBDriver  string   `json:"db_driver"`
 	DBPath    string   `json:"db_path"`
 	Watchlist []string `json:"watchlist"`
 
@@ -1028,10 +1522,13 @@ type configView struct {
 	Concept    config.BoardConfig      `json:"concept"`
 	MarketAgg  config.MarketAggConfig  `json:"market_agg"`
 	BoardTrend config.BoardTrendConfig `json:"board_trend"`
+	Auction    config.AuctionConfig    `json:"auction"`
+	DailyJob   config.DailyJobConfig   `json:"daily_job"`
 }
 
 func toConfigView(cfg config.Config) configView {
 	var v configView
+	v.DBDriver = cfg.DBDriver
 	v.DBPath = cfg.DBPath
 	v.Watchlist = cfg.Watchlist
 	v.Realtime.IntervalSeconds = cfg.Realtime.IntervalSeconds
@@ -1047,6 +1544,8 @@ func toConfigView(cfg config.Config) configView {
 	v.Concept = cfg.Concept
 	v.MarketAgg = cfg.MarketAgg
 	v.BoardTrend = cfg.BoardTrend
+	v.Auction = cfg.Auction
+	v.DailyJob = cfg.DailyJob
 	return v
 }
 
@@ -15,6 +15,17 @@ async function postJSON(url, payload) {
   try { return JSON.parse(txt); } catch { return txt; }
 }
 
+async function sendJSON(method, url, payload) {
+  const r = await fetch(url, {
+    method,
+    headers: { "Content-Type": "application/json", "Accept": "application/json" },
+    body: payload === undefined ? undefined : JSON.stringify(payload),
+  });
+  const txt = await r.text();
+  if (!r.ok) throw new Error(txt);
+  try { return JSON.parse(txt); } catch { return txt; }
+}
+
 function setPill(ok, msg) {
   const pill = document.getElementById("pill");
   if (!pill) return;
@@ -47,8 +58,107 @@ function splitWatchlist(text) {
   return text.split(/\r?\n/).map(s => s.trim()).filter(Boolean);
 }
 
+const securityKinds = { stock: "股票", index: "指数", etf: "ETF", industry: "行业", concept: "概念" };
+
+// attachSymbolSearch wires a search box to /api/search; picking a result appends its symbol
+// (e.g. 600519.SH) to the textarea unless it is already listed. Boards can't be watched.
+function attachSymbolSearch(inputId, listId, textareaId) {
+  const input = document.getElementById(inputId);
+  const list = document.getElementById(listId);
+  const area = document.getElementById(textareaId);
+  if (!input || !list || !area) return;
+  let timer = null;
+  let seq = 0;
+  let results = [];
+  let active = -1;
+
+  const close = () => {
+    list.hidden = true;
+    list.innerHTML = "";
+    results = [];
+    active = -1;
+  };
+  const pick = (r) => {
+    const lines = splitWatchlist(area.value);
+    if (!lines.some(x => x.toUpperCase() === r.symbol)) lines.push(r.symbol);
+    area.value = lines.join("\n");
+    input.value = "";
+    close();
+    input.focus();
+  };
+  const render = () => {
+    list.innerHTML = "";
+    results.forEach((r, i) => {
+      const li = document.createElement("li");
+      if (i === active) li.classList.add("active");
+      const sym = document.createElement("span");
+      sym.textContent = r.symbol;
+      const name = document.createElement("span");
+      name.textContent = r.name;
+      const kind = document.createElement("span");
+      kind.className = "kind";
+      kind.textContent = securityKinds[r.kind] || r.kind;
+      li.append(sym, name, kind);
+      // mousedown fires before the input's blur closes the list.
+      li.addEventListener("mousedown", (ev) => {
+        ev.preventDefault();
+        pick(r);
+      });
+      list.appendChild(li);
+    });
+    list.hidden = results.length === 0;
+  };
+  const search = async () => {
+    const q = input.value.trim();
+    const my = ++seq;
+    if (!q) {
+      close();
+      return;
+    }
+    try {
+      const d = await getJSON(`/api/v1/search?q=${encodeURIComponent(q)}&kind=stock,index,etf&limit=12`);
+      if (my !== seq) return;
+      results = (d.results || []).filter(r => r.symbol);
+      active = results.length ? 0 : -1;
+      render();
+      if (!results.length && d.warning) {
+        const li = document.createElement("li");
+        li.textContent = "证券列表尚未加载，请稍后再试";
+        list.appendChild(li);
+        list.hidden = false;
+      }
+    } catch (e) {
+      console.error(e);
+    }
+  };
+
+  input.addEventListener("input", () => {
+    clearTimeout(timer);
+    timer = setTimeout(search, 200);
+  });
+  input.addEventListener("keydown", (ev) => {
+    if (ev.key === "ArrowDown" || ev.key === "ArrowUp") {
+      if (!results.length) return;
+      ev.preventDefault();
+      active = (active + (ev.key === "ArrowDown" ? 1 : results.length - 1)) % results.length;
+      render();
+    } else if (ev.key === "Enter") {
+      // Don't submit the surrounding form from the search box.
+      ev.preventDefault();
+      if (active >= 0 && results[active]) pick(results[active]);
+    } else if (ev.key === "Escape") {
+      close();
+    }
+  });
+  input.addEventListener("blur", () => setTimeout(close, 100));
+}
+
+function dbLabel(cfg) {
+  return cfg.db_driver === "postgres" ? "PostgreSQL" : (cfg.db_path || "-");
+}
+
 function fillConfig(cfg) {
-  document.getElementById("dbPath").textContent = cfg.db_path || "-";
+  document.getElementById("dbPath").textContent = dbLabel(cfg);
   document.getElementById("wlCount").textContent = String((cfg.watchlist || []).length);
 
   document.getElementById("rtInterval").value = cfg.realtime?.interval_seconds ?? 20;
@@ -76,6 +186,11 @@ function fillConfig(cfg) {
     fillTrendCfgForm();
   }
 
+  const dj = document.getElementById("dailyJobEnabled");
+  if (dj) dj.checked = cfg.daily_job?.enabled !== false;
+  const djAt = document.getElementById("dailyJobRunAt");
+  if (djAt) djAt.value = cfg.daily_job?.run_at || "15:35";
+
   document.getElementById("watchlist").value = (cfg.watchlist || []).join("\n");
 }
 
@@ -377,9 +492,39 @@ function setText(id, v) {
   el.textContent = v;
 }
 
+const datasetLabels = {
+  northbound: "北向",
+  fundflow: "自选资金",
+  toplist: "榜单",
+  "board:industry": "行业板块",
+  "board:concept": "概念板块",
+  "agg:industry_sum": "行业合计",
+  "agg:allstocks_sum": "全市场合计",
+};
+
+function datasetLabel(key) {
+  const parts = key.split(":");
+  return datasetLabels[key] || datasetLabels[parts.slice(0, 2).join(":")] || datasetLabels[parts[0]] || key;
+}
+
+// fillStale shows which datasets missed their refresh (decided server-side per dataset).
+function fillStale(snap) {
+  const el = document.getElementById("rtStale");
+  if (!el) return;
+  const stale = snap?.stale || [];
+  el.hidden = stale.length === 0;
+  el.textContent = stale.length ? "延迟: " + stale.map(datasetLabel).join(", ") : "";
+  el.title = stale.map(key => {
+    const m = snap?.meta?.[key] || {};
+    const at = m.fetched_at ? fmtBJTime(m.fetched_at) : "未获取";
+    return datasetLabel(key) + " 最后获取: " + at + (m.last_error ? " 错误: " + m.last_error : "");
+  }).join("\n");
+}
+
 function fillRealtime(snap, cfg) {
   const ts = snap?.ts_utc ? fmtBJTime(snap.ts_utc) : "-";
   setText("rtTs", ts);
+  fillStale(snap);
 
   const nb = snap?.northbound;
   if (!nb) {
@@ -427,18 +572,38 @@ function fillRealtime(snap, cfg) {
   tbody.innerHTML = "";
   const byCode = new Map();
   (snap?.fundflow || []).forEach(r => { byCode.set(r.Code || r.code, r); });
+  const updated = snap?.fundflow_updated || {};
+  const invalid = new Map((snap?.invalid_symbols || []).map(x => [x.symbol, x.error]));
+  // A row is stale when its chunk failed for a few ticks while others kept updating.
+  const staleMs = 3 * 1000 * Number(cfg?.realtime?.interval_seconds || 10);
+  const snapMs = Date.parse(snap?.ts_utc || "");
   const wl = (cfg?.watchlist || []);
   wl.forEach(sym => {
     const code = sym.split(".")[0];
     const r = byCode.get(code);
     const tr = document.createElement("tr");
+    if (invalid.has(sym)) {
+      tr.classList.add("stale");
+      tr.title = "无效代码: " + invalid.get(sym);
+    } else if (updated[code]) {
+      const at = Date.parse(updated[code]);
+      if (Number.isFinite(at) && Number.isFinite(snapMs) && snapMs - at > staleMs) {
+        tr.classList.add("stale");
+        tr.title = "最后更新: " + new Date(at).toLocaleTimeString();
+      }
+    }
     const td = (t, cls) => {
       const x = document.createElement("td");
       if (cls) x.className = cls;
       x.textContent = t;
       return x;
     };
-    tr.appendChild(td(code));
+    const codeCell = document.createElement("td");
+    const link = document.createElement("a");
+    link.href = `#/stock?code=${encodeURIComponent(code)}`;
+    link.textContent = code;
+    codeCell.appendChild(link);
+    tr.appendChild(codeCell);
     tr.appendChild(td(r ? (r.Name || r.name || "-") : "-"));
     tr.appendChild(td(fmtMoney(r ? (r.NetMain ?? r.net_main) : NaN), "num"));
     tr.appendChild(td(fmtMoney(r ? (r.NetXL ?? r.net_xl) : NaN), "num"));
@@ -447,6 +612,92 @@ function fillRealtime(snap, cfg) {
     tr.appendChild(td(fmtMoney(r ? (r.NetS ?? r.net_s) : NaN), "num"));
     tbody.appendChild(tr);
   });
+
+  renderGroupFlows(snap?.groups || []);
+}
+
+function renderGroupFlows(groups) {
+  const panel = document.getElementById("groupPanel");
+  const tbody = document.querySelector("#tblGroups tbody");
+  if (!panel || !tbody) return;
+  panel.hidden = groups.length === 0;
+  tbody.innerHTML = "";
+  groups.forEach(g => {
+    const tr = document.createElement("tr");
+    const td = (t, cls) => {
+      const x = document.createElement("td");
+      if (cls) x.className = cls;
+      x.textContent = t;
+      return x;
+    };
+    tr.appendChild(td(g.name));
+    tr.appendChild(td((g.tags || []).join(", ") || "-"));
+    tr.appendChild(td(`${g.covered}/${g.members}`, "num"));
+    tr.appendChild(td(fmtMoney(g.net_main), "num " + (g.net_main >= 0 ? "up" : "down")));
+    tr.appendChild(td(fmtMoney(g.net_xl), "num"));
+    tr.appendChild(td(fmtMoney(g.net_l), "num"));
+    tr.appendChild(td(fmtMoney(g.net_m), "num"));
+    tr.appendChild(td(fmtMoney(g.net_s), "num"));
+    tbody.appendChild(tr);
+  });
+}
+
+function fillGroupForm(g) {
+  document.getElementById("groupId").value = g?.id ? String(g.id) : "";
+  document.getElementById("groupName").value = g?.name || "";
+  document.getElementById("groupTags").value = (g?.tags || []).join(", ");
+  document.getElementById("groupNote").value = g?.note || "";
+  document.getElementById("groupSymbols").value = (g?.symbols || []).join("\n");
+}
+
+async function loadWatchGroups() {
+  const tbody = document.querySelector("#tblGroupEdit tbody");
+  if (!tbody) return;
+  let groups = [];
+  try {
+    groups = (await getJSON("/api/watchgroups")).groups || [];
+  } catch (e) {
+    console.error(e);
+    return;
+  }
+  tbody.innerHTML = "";
+  groups.forEach(g => {
+    const tr = document.createElement("tr");
+    const td = (t, cls) => {
+      const x = document.createElement("td");
+      if (cls) x.className = cls;
+      x.textContent = t;
+      return x;
+    };
+    tr.appendChild(td(g.name));
+    tr.appendChild(td((g.tags || []).join(", ") || "-"));
+    tr.appendChild(td(String((g.symbols || []).length), "num"));
+    tr.appendChild(td(g.note || "-"));
+    const ops = document.createElement("td");
+    const edit = document.createElement("button");
+    edit.type = "button";
+    edit.className = "btn";
+    edit.textContent = "编辑";
+    edit.addEventListener("click", () => fillGroupForm(g));
+    const del = document.createElement("button");
+    del.type = "button";
+    del.className = "btn";
+    del.textContent = "删除";
+    del.addEventListener("click", async () => {
+      if (!confirm(`删除分组「${g.name}」？`)) return;
+      try {
+        await sendJSON("DELETE", `/api/watchgroups?id=${g.id}`);
+        await loadWatchGroups();
+      } catch (e) {
+        console.error(e);
+        setText("groupHint", "删除失败: " + e.message);
+      }
+    });
+    ops.appendChild(edit);
+    ops.appendChild(del);
+    tr.appendChild(ops);
+    tbody.appendChild(tr);
+  });
 }
 
 function setRoute(route) {
@@ -461,7 +712,7 @@ function setRoute(route) {
 function getRoute() {
   const h = (location.hash || "#/home").replace(/^#\//, "");
   const route = h.split("?")[0];
-  if (route === "history" || route === "history-industry" || route === "history-concept" || route === "settings" || route === "home" || route === "industry" || route === "concept" || route === "trend") return route;
+  if (route === "history" || route === "history-industry" || route === "history-concept" || route === "settings" || route === "home" || route === "industry" || route === "concept" || route === "trend" || route === "stock") return route;
   return "home";
 }
 
@@ -484,11 +735,14 @@ function getRouteQuery() {
 let state = {
   cfg: null,
   timers: [],
+  snap: null,
+  stream: null,
   historyMeta: null,
   historyRows: null,
   homeChartRows: null,
   homeIndex: null,
   trendPoints: null,
+  stockDetail: null,
   dailyObserver: null,
   boardCharts: {
     industry: new Map(),
@@ -543,6 +797,7 @@ function setLoadStatus(type, text, show) {
 function clearTimers() {
   state.timers.forEach(id => clearInterval(id));
   state.timers = [];
+  stopRealtimeStream();
   ["industry", "concept"].forEach(tp => {
     const id = state.batchTimers?.[tp];
     if (id) clearInterval(id);
@@ -555,7 +810,7 @@ async function refreshConfig() {
     const cfg = await getJSON("/api/config");
     state.cfg = cfg;
     fillConfig(cfg);
-    setText("dbPath", cfg.db_path || "-");
+    setText("dbPath", dbLabel(cfg));
     setText("wlCount", String((cfg.watchlist || []).length));
     return cfg;
   } catch (e) {
@@ -575,8 +830,27 @@ async function refreshBuildInfo() {
   }
 }
 
+// Viewers and read-only servers can't save settings or start jobs; disable those controls up front.
+async function applyAccess() {
+  try {
+    const v = await getJSON("/api/version");
+    if (!v || (!v.read_only && v.role !== "viewer")) return;
+    const view = document.getElementById("viewSettings");
+    view.querySelectorAll("button").forEach(b => { b.disabled = true; });
+    ["histIndBatch", "histConBatch"].forEach(id => {
+      const b = document.getElementById(id);
+      if (b) b.disabled = true;
+    });
+    setText("settingsHint", v.read_only ? "只读模式：服务器不采集数据，设置不可修改。" : `当前用户 ${v.user} 为只读角色，设置不可修改。`);
+  } catch (e) {
+    console.error(e);
+  }
+}
+
 function wire() {
   initThemeToggle();
+  attachSymbolSearch("watchSearch", "watchSearchResults", "watchlist");
+  attachSymbolSearch("groupSearch", "groupSearchResults", "groupSymbols");
   fillTrendCfgForm();
   window.addEventListener("hashchange", () => bootRoute());
   window.addEventListener("resize", () => {
@@ -607,6 +881,9 @@ function wire() {
     if (route === "trend" && state.trendPoints) {
       renderTrendChart(state.trendPoints);
     }
+    if (route === "stock" && state.stockDetail) {
+      renderStockCharts(state.stockDetail);
+    }
   });
 
 
@@ -690,6 +967,31 @@ function wire() {
     }
   });
 
+  document.getElementById("formGroup")?.addEventListener("submit", async (ev) => {
+    ev.preventDefault();
+    const id = document.getElementById("groupId").value;
+    const payload = {
+      name: document.getElementById("groupName").value,
+      tags: document.getElementById("groupTags").value.split(/[,，]/).map(s => s.trim()).filter(Boolean),
+      note: document.getElementById("groupNote").value,
+      symbols: splitWatchlist(document.getElementById("groupSymbols").value),
+    };
+    try {
+      if (id) {
+        await sendJSON("PUT", `/api/watchgroups?id=${encodeURIComponent(id)}`, payload);
+      } else {
+        await sendJSON("POST", "/api/watchgroups", payload);
+      }
+      fillGroupForm(null);
+      setText("groupHint", "已保存");
+      await loadWatchGroups();
+    } catch (e) {
+      console.error(e);
+      setText("groupHint", "保存失败: " + e.message);
+    }
+  });
+  document.getElementById("groupNew")?.addEventListener("click", () => fillGroupForm(null));
+
   document.getElementById("formBoardTrend").addEventListener("submit", async (ev) => {
     ev.preventDefault();
     const batchSize = Number(document.getElementById("trendBatch").value);
@@ -723,10 +1025,50 @@ function wire() {
     }
   });
 
+  document.getElementById("formDailyJob")?.addEventListener("submit", async (ev) => {
+    ev.preventDefault();
+    const payload = {
+      daily_job_enabled: document.getElementById("dailyJobEnabled").checked,
+      daily_job_run_at: document.getElementById("dailyJobRunAt").value || "15:35",
+    };
+    try {
+      setPill(true, "saving...");
+      const cfg = await postJSON("/api/config", payload);
+      state.cfg = cfg;
+      fillConfig(cfg);
+      setPill(true, "saved");
+      setTimeout(() => setPill(true, "connected"), 700);
+    } catch (e) {
+      console.error(e);
+      setPill(false, "save failed");
+    }
+  });
+  document.getElementById("dailyJobRunNow")?.addEventListener("click", async () => {
+    try {
+      await postJSON("/api/daily/job", {});
+    } catch (e) {
+      console.error(e);
+    }
+    await pollDailyJob();
+  });
+
   document.getElementById("formHistory").addEventListener("submit", async (ev) => {
     ev.preventDefault();
     await loadHistory();
   });
+  document.getElementById("formExport")?.addEventListener("submit", (ev) => {
+    ev.preventDefault();
+    const q = new URLSearchParams();
+    q.set("format", document.getElementById("expFormat").value);
+    const codes = document.getElementById("expCodes").value.trim();
+    if (codes) q.set("codes", codes);
+    const from = document.getElementById("expFrom").value;
+    const to = document.getElementById("expTo").value;
+    if (from) q.set("from", from);
+    if (to) q.set("to", to);
+    if (document.getElementById("expZH").checked) q.set("headers", "zh");
+    window.location.href = "/api/v1/export/" + document.getElementById("expDataset").value + "?" + q.toString();
+  });
   document.getElementById("histPeriod")?.addEventListener("change", () => {
     const v = Number(document.getElementById("histPeriod").value || "90");
     const limit = document.getElementById("histLimit");
@@ -752,6 +1094,12 @@ function wire() {
     await loadTrendView(code);
   });
 
+  document.getElementById("formStock")?.addEventListener("submit", (ev) => {
+    ev.preventDefault();
+    const code = String(document.getElementById("stockCode")?.value || "").trim();
+    if (code) location.hash = `#/stock?code=${encodeURIComponent(code)}`;
+  });
+
   document.getElementById("histIndBatch")?.addEventListener("click", async () => {
     await startBoardDailyBatch("industry");
   });
@@ -763,6 +1111,7 @@ function wire() {
 async function refreshRealtimeOnce() {
   try {
     const snap = await getJSON("/api/realtime");
+    state.snap = snap;
     if (state.cfg) fillRealtime(snap, state.cfg);
     setPill(true, "connected");
     if (!state.marketClosed && isAfterCloseBJ()) {
@@ -777,6 +1126,87 @@ async function refreshRealtimeOnce() {
   }
 }
 
+// applyStreamEvent merges one incremental /api/stream update into a snapshot.
+function applyStreamEvent(snap, ev) {
+  const mergeByCode = (list, rows) => {
+    const out = (list || []).slice();
+    const idx = new Map(out.map((r, i) => [r.Code || r.code, i]));
+    (rows || []).forEach(r => {
+      const i = idx.get(r.Code);
+      if (i === undefined) out.push(r);
+      else out[i] = r;
+    });
+    return out;
+  };
+  switch (ev.dataset) {
+    case "northbound":
+      snap.northbound = ev.data;
+      break;
+    case "fundflow":
+      snap.fundflow = mergeByCode(snap.fundflow, ev.data);
+      snap.fundflow_updated = snap.fundflow_updated || {};
+      (ev.data || []).forEach(r => { snap.fundflow_updated[r.Code] = ev.ts_utc; });
+      break;
+    case "toplist":
+      snap.toplist_by_fid = snap.toplist_by_fid || {};
+      snap.toplist_by_fid[ev.key] = ev.data;
+      break;
+    case "board":
+      snap.boards_by_key = snap.boards_by_key || {};
+      snap.boards_by_key[ev.key] = mergeByCode(snap.boards_by_key[ev.key], ev.data);
+      break;
+    case "agg":
+      snap.agg_by_key = snap.agg_by_key || {};
+      snap.agg_by_key[ev.key] = ev.data;
+      break;
+  }
+  // An update proves the dataset is fresh again; the next /api/realtime poll re-evaluates the rest.
+  const key = ev.key ? ev.dataset + ":" + ev.key : ev.dataset;
+  if (snap.stale) snap.stale = snap.stale.filter(k => k !== key);
+  snap.ts_utc = ev.ts_utc;
+  snap.seq = ev.seq;
+}
+
+// startRealtimeStream pushes collector updates into the home view as they are fetched.
+// EventSource reconnects by itself and resumes from the last event id. Group sums are
+// computed server-side, so /api/realtime is still polled, just rarely.
+function startRealtimeStream() {
+  if (!window.EventSource) return false;
+  stopRealtimeStream();
+  const es = new EventSource("/api/stream");
+  let pending = null;
+  const render = () => {
+    if (pending) return;
+    pending = setTimeout(() => {
+      pending = null;
+      if (state.cfg && state.snap) fillRealtime(state.snap, state.cfg);
+    }, 300);
+  };
+  es.addEventListener("snapshot", (e) => {
+    const groups = state.snap?.groups;
+    state.snap = JSON.parse(e.data);
+    if (groups && !state.snap.groups) state.snap.groups = groups;
+    setPill(true, "live");
+    render();
+  });
+  es.addEventListener("update", (e) => {
+    if (!state.snap) return;
+    applyStreamEvent(state.snap, JSON.parse(e.data));
+    render();
+  });
+  es.onopen = () => setPill(true, "live");
+  es.onerror = () => setPill(false, "reconnecting");
+  state.stream = es;
+  return true;
+}
+
+function stopRealtimeStream() {
+  if (state.stream) {
+    state.stream.close();
+    state.stream = null;
+  }
+}
+
 async function loadBoardsFor(type, _listId, hintId) {
   const fid = (type === "concept" ? state.cfg?.concept?.fid : state.cfg?.industry?.fid) || "f62";
   const url = `/api/boards?type=${encodeURIComponent(type)}&fid=${encodeURIComponent(fid)}&limit=500`;
@@ -1145,6 +1575,34 @@ async function startBoardDailyBatch(type) {
   }
 }
 
+function formatDailyJobStatus(s) {
+  if (!s) return "日快照：-";
+  const rep = s.report;
+  const parts = [];
+  if (s.running) parts.push("运行中");
+  if (rep) {
+    parts.push(`交易日 ${rep.trade_date}`);
+    parts.push(`成功 ${rep.ok}/${rep.total}`);
+    if ((rep.failed || []).length) {
+      const names = rep.failed.slice(0, 5).map(f => f.key ? `${f.dataset}:${f.key}` : f.dataset);
+      parts.push(`失败 ${rep.failed.length}（${names.join(", ")}${rep.failed.length > 5 ? " ..." : ""}）`);
+    }
+    if (s.attempts > 1) parts.push(`尝试 ${s.attempts} 次`);
+  }
+  if (s.finished_at) parts.push(`完成 ${fmtBJTime(s.finished_at)}`);
+  if (s.next_run_at) parts.push(`下次 ${fmtBJTime(s.next_run_at)}`);
+  return "日快照：" + (parts.length ? parts.join(" | ") : "未运行");
+}
+
+async function pollDailyJob() {
+  try {
+    const s = await getJSON("/api/daily/job");
+    setText("dailyJobStatus", formatDailyJobStatus(s));
+  } catch (e) {
+    console.error(e);
+  }
+}
+
 async function fetchBoardTrend(boardCode) {
   const url = `/api/board/trend?board=${encodeURIComponent(boardCode)}`;
   return await getJSON(url);
@@ -1198,6 +1656,112 @@ async function loadTrendView(boardCode) {
   }
 }
 
+function drawOrHint(canvasId, hintId, labels, values) {
+  const hint = document.getElementById(hintId);
+  if (labels.length < 2) {
+    if (hint) {
+      hint.textContent = "暂无数据";
+      hint.hidden = false;
+    }
+    return;
+  }
+  if (hint) hint.hidden = true;
+  drawLineChart(document.getElementById(canvasId), labels, values);
+}
+
+function renderStockCharts(d) {
+  const trend = normalizeBoardTrend(d.trend || []);
+  drawOrHint("stockTrendChart", "stockTrendHint", trend.labels, trend.values);
+  const flow = d.fundflow_daily || [];
+  drawOrHint("stockFlowChart", "stockFlowHint", flow.map(p => p.trade_date.slice(5)), flow.map(p => Number(p.net_main)));
+  const margin = d.margin_daily || [];
+  drawOrHint("stockMarginChart", "stockMarginHint", margin.map(p => p.trade_date.slice(5)), margin.map(p => Number(p.rzye)));
+}
+
+function renderStockDetail(d) {
+  setText("stockTitle", `实时资金流（${d.code}${d.name ? " " + d.name : ""}）`);
+  const td = (t, cls) => {
+    const x = document.createElement("td");
+    if (cls) x.className = cls;
+    x.textContent = t;
+    return x;
+  };
+
+  const rtBody = document.querySelector("#tblStockRT tbody");
+  rtBody.innerHTML = "";
+  const r = d.realtime;
+  if (r) {
+    const tr = document.createElement("tr");
+    [r.NetMain, r.NetXL, r.NetL, r.NetM, r.NetS].forEach(v => tr.appendChild(td(fmtMoney(v), "num")));
+    tr.appendChild(td(d.realtime_updated ? new Date(d.realtime_updated).toLocaleTimeString() : "-"));
+    rtBody.appendChild(tr);
+  }
+
+  const boardBody = document.querySelector("#tblStockBoards tbody");
+  boardBody.innerHTML = "";
+  const typeLabels = { industry: "行业", concept: "概念" };
+  (d.boards || []).forEach(b => {
+    const tr = document.createElement("tr");
+    const codeCell = document.createElement("td");
+    const link = document.createElement("a");
+    link.href = `#/trend?board=${encodeURIComponent(b.code)}`;
+    link.textContent = b.code;
+    codeCell.appendChild(link);
+    tr.appendChild(codeCell);
+    tr.appendChild(td(b.name));
+    tr.appendChild(td(typeLabels[b.type] || "-"));
+    tr.appendChild(td(Number.isFinite(b.pct) ? b.pct.toFixed(2) + "%" : "-", "num"));
+    boardBody.appendChild(tr);
+  });
+
+  const topBody = document.querySelector("#tblStockToplist tbody");
+  topBody.innerHTML = "";
+  (d.toplist || []).forEach(a => {
+    const tr = document.createElement("tr");
+    tr.appendChild(td(a.trade_date));
+    tr.appendChild(td(a.fid));
+    tr.appendChild(td(String(a.best_rank), "num"));
+    tr.appendChild(td(String(a.last_rank), "num"));
+    tr.appendChild(td(fmtMoney(a.last_value), "num"));
+    tr.appendChild(td(String(a.samples), "num"));
+    topBody.appendChild(tr);
+  });
+
+  renderStockCharts(d);
+}
+
+async function loadStockView(code) {
+  const hint = document.getElementById("stockHint");
+  const input = document.getElementById("stockCode");
+  if (input && code) input.value = code;
+  if (!code) {
+    if (hint) {
+      hint.textContent = "请输入股票代码";
+      hint.hidden = false;
+    }
+    return;
+  }
+  if (hint) {
+    hint.textContent = "加载中...";
+    hint.hidden = false;
+  }
+  try {
+    const d = await getJSON(`/api/v1/stock/detail?code=${encodeURIComponent(code)}`);
+    state.stockDetail = d;
+    if (hint) {
+      hint.textContent = d.warning ? "部分数据加载失败: " + d.warning : "";
+      hint.hidden = !d.warning;
+    }
+    renderStockDetail(d);
+  } catch (e) {
+    console.error(e);
+    if (hint) {
+      hint.textContent = "加载失败";
+      hint.hidden = false;
+    }
+  }
+}
+
 async function refreshBoardTrends(type, boards, onlyMissing = false) {
   const cache = state.boardCharts[type];
   if (!cache || cache.size === 0) return;
@@ -1495,6 +2059,7 @@ async function bootRoute() {
   setRoute(route);
   state.trendPoints = null;
   state.homeIndex = null;
+  state.stockDetail = null;
 
   try {
     await refreshConfig();
@@ -1505,7 +2070,8 @@ async function bootRoute() {
   if (route === "home") {
     await refreshRealtimeOnce();
     if (!isAfterCloseBJ()) {
-      state.timers.push(setInterval(refreshRealtimeOnce, 10000));
+      const live = startRealtimeStream();
+      state.timers.push(setInterval(refreshRealtimeOnce, live ? 60000 : 10000));
     }
     await loadIndustryChartHome();
     if (!isAfterCloseBJ()) {
@@ -1584,10 +2150,17 @@ async function bootRoute() {
       document.getElementById("trendHint").textContent = "请输入板块代码";
       document.getElementById("trendHint").hidden = false;
     }
+  } else if (route === "stock") {
+    const q = getRouteQuery();
+    await loadStockView(q.code ? String(q.code).trim() : "");
   } else if (route === "settings") {
     // Don't auto-refresh config here; it would overwrite unsaved UI edits.
+    await pollDailyJob();
+    await loadWatchGroups();
+    state.timers.push(setInterval(pollDailyJob, 10000));
   }
 }
 
 wire();
 bootRoute();
+applyAccess();
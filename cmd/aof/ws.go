@@ -0,0 +1,329 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/eastmoney"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/memstore"
+)
+
+// WebSocket protocol (/api/ws), one JSON object per message.
+//
+// Client -> server:
+//
+//	{"op":"subscribe","dataset":"fundflow","keys":["600519","000001"]}
+//	{"op":"unsubscribe","dataset":"fundflow","keys":["000001"]}   (no keys: the whole dataset)
+//	{"op":"ping"}
+//
+// Datasets and keys: fundflow (stock code), board (board code, e.g. BK0457), toplist (fid),
+// agg (source:fid), northbound (no keys). Subscribing without keys means every key of the dataset.
+//
+// Server -> client:
+//
+//	{"type":"snapshot","dataset":...,"data":...}   current values right after each subscribe
+//	{"type":"update","seq":N,"dataset":...,"key":...,"ts_utc":...,"data":...}   matching rows only
+//	{"type":"resync"}   the client fell behind and updates were skipped; fresh snapshots follow
+//	{"type":"pong"} / {"type":"error","error":...}
+//
+// The server also sends WebSocket pings every wsPingPeriod and drops clients that stop answering.
+// The timings are variables so tests can shorten them.
+var (
+	wsPingPeriod = 20 * time.Second
+	wsPongWait   = 60 * time.Second
+	wsWriteWait  = 10 * time.Second
+)
+
+// wsBuffer is how many memstore events may queue for one client before it is resynced.
+const wsBuffer = 256
+
+var wsUpgrader = websocket.Upgrader{ReadBufferSize: 4096, WriteBufferSize: 16384}
+
+type wsRequest struct {
+	Op      string   `json:"op"`
+	Dataset string   `json:"dataset"`
+	Keys    []string `json:"keys"`
+}
+
+type wsMessage struct {
+	Type    string `json:"type"`
+	Seq     uint64 `json:"seq,omitempty"`
+	Dataset string `json:"dataset,omitempty"`
+	Key     string `json:"key,omitempty"`
+	TSUTC   string `json:"ts_utc,omitempty"`
+	Data    any    `json:"data,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// wsSubs maps dataset -> keys; a nil key set means all keys.
+type wsSubs map[string]map[string]struct{}
+
+var wsDatasets = map[string]bool{
+	memstore.EventFundflow:   true,
+	memstore.EventBoard:      true,
+	memstore.EventToplist:    true,
+	memstore.EventAgg:        true,
+	memstore.EventNorthbound: true,
+}
+
+func (s wsSubs) add(dataset string, keys []string) {
+	if len(keys) == 0 {
+		s[dataset] = nil
+		return
+	}
+	set, ok := s[dataset]
+	if ok && set == nil {
+		return // already subscribed to everything
+	}
+	if set == nil {
+		set = make(map[string]struct{}, len(keys))
+		s[dataset] = set
+	}
+	for _, k := range keys {
+		set[k] = struct{}{}
+	}
+}
+
+func (s wsSubs) remove(dataset string, keys []string) {
+	set, ok := s[dataset]
+	if !ok {
+		return
+	}
+	if len(keys) == 0 || set == nil {
+		delete(s, dataset)
+		return
+	}
+	for _, k := range keys {
+		delete(set, k)
+	}
+	if len(set) == 0 {
+		delete(s, dataset)
+	}
+}
+
+func (s wsSubs) has(dataset, key string) bool {
+	set, ok := s[dataset]
+	if !ok {
+		return false
+	}
+	if set == nil {
+		return true
+	}
+	_, ok = set[key]
+	return ok
+}
+
+// filter returns the part of e the client subscribed to.
+func (s wsSubs) filter(e memstore.Event) (any, bool) {
+	if _, ok := s[e.Dataset]; !ok {
+		return nil, false
+	}
+	switch e.Dataset {
+	case memstore.EventFundflow:
+		rows, _ := e.Data.([]eastmoney.FundflowRT)
+		var out []eastmoney.FundflowRT
+		for _, r := range rows {
+			if s.has(e.Dataset, r.Code) {
+				out = append(out, r)
+			}
+		}
+		return out, len(out) > 0
+	case memstore.EventBoard:
+		rows, _ := e.Data.([]eastmoney.TopItem)
+		var out []eastmoney.TopItem
+		for _, r := range rows {
+			if s.has(e.Dataset, r.Code) {
+				out = append(out, r)
+			}
+		}
+		return out, len(out) > 0
+	case memstore.EventNorthbound:
+		return e.Data, true
+	default: // toplist, agg: keyed by the event key
+		return e.Data, s.has(e.Dataset, e.Key)
+	}
+}
+
+// wsSnapshot builds the current values of one subscription from a memstore snapshot.
+func wsSnapshot(snap memstore.Snapshot, dataset string, keys []string) any {
+	want := func(k string) bool {
+		if len(keys) == 0 {
+			return true
+		}
+		for _, x := range keys {
+			if x == k {
+				return true
+			}
+		}
+		return false
+	}
+	switch dataset {
+	case memstore.EventFundflow:
+		out := []eastmoney.FundflowRT{}
+		for _, r := range snap.Fundflow {
+			if want(r.Code) {
+				out = append(out, r)
+			}
+		}
+		return out
+	case memstore.EventBoard:
+		out := map[string][]eastmoney.TopItem{}
+		for key, rows := range snap.BoardsByKey {
+			for _, r := range rows {
+				if want(r.Code) {
+					out[key] = append(out[key], r)
+				}
+			}
+		}
+		return out
+	case memstore.EventToplist:
+		out := map[string][]eastmoney.TopItem{}
+		for fid, rows := range snap.ToplistByFID {
+			if want(fid) {
+				out[fid] = rows
+			}
+		}
+		return out
+	case memstore.EventAgg:
+		out := map[string]float64{}
+		for k, v := range snap.AggByKey {
+			if want(k) {
+				out[k] = v
+			}
+		}
+		return out
+	default:
+		return snap.Northbound
+	}
+}
+
+func newWSHandler(mem *memstore.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		conn, err := wsUpgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return // Upgrade already replied with an HTTP error
+		}
+		defer conn.Close()
+
+		// Reader: forwards client requests to the writer loop below, which owns the connection writes.
+		reqs := make(chan wsRequest, 16)
+		done := make(chan struct{})
+		var once sync.Once
+		stop := func() { once.Do(func() { close(done) }) }
+		conn.SetReadLimit(64 << 10)
+		_ = conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		})
+		go func() {
+			defer stop()
+			for {
+				_, msg, err := conn.ReadMessage()
+				if err != nil {
+					return
+				}
+				_ = conn.SetReadDeadline(time.Now().Add(wsPongWait))
+				var req wsRequest
+				if err := json.Unmarshal(msg, &req); err != nil {
+					req = wsRequest{Op: "invalid"} // answered with an error by the writer loop
+				}
+				select {
+				case reqs <- req:
+				case <-done:
+					return
+				}
+			}
+		}()
+
+		send := func(m wsMessage) bool {
+			_ = conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			return conn.WriteJSON(m) == nil
+		}
+
+		subs := wsSubs{}
+		sub, _, _ := mem.Subscribe(0, wsBuffer)
+		defer func() { sub.Close() }()
+		ping := time.NewTicker(wsPingPeriod)
+		defer ping.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-r.Context().Done():
+				return
+			case <-ping.C:
+				_ = conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+				if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+					return
+				}
+			case req := <-reqs:
+				switch req.Op {
+				case "ping":
+					if !send(wsMessage{Type: "pong"}) {
+						return
+					}
+				case "subscribe", "unsubscribe":
+					if !wsDatasets[req.Dataset] {
+						if !send(wsMessage{Type: "error", Error: "unknown dataset: " + req.Dataset}) {
+							return
+						}
+						continue
+					}
+					if req.Op == "unsubscribe" {
+						subs.remove(req.Dataset, req.Keys)
+						continue
+					}
+					subs.add(req.Dataset, req.Keys)
+					snap := mem.SnapshotLatest()
+					if !send(wsMessage{Type: "snapshot", Seq: snap.Seq, Dataset: req.Dataset, TSUTC: formatRFC3339Nano(snap.TSUTC),
+						Data: wsSnapshot(snap, req.Dataset, req.Keys)}) {
+						return
+					}
+				case "invalid":
+					if !send(wsMessage{Type: "error", Error: "request must be a JSON object"}) {
+						return
+					}
+				default:
+					if !send(wsMessage{Type: "error", Error: "unknown op: " + req.Op}) {
+						return
+					}
+				}
+			case e, ok := <-sub.C:
+				if !ok {
+					// Too slow to keep up: memstore dropped us. Resubscribe and replace the
+					// skipped updates with fresh snapshots of everything subscribed.
+					sub, _, _ = mem.Subscribe(0, wsBuffer)
+					snap := mem.SnapshotLatest()
+					if !send(wsMessage{Type: "resync", Seq: snap.Seq}) {
+						return
+					}
+					for dataset, set := range subs {
+						var keys []string
+						for k := range set {
+							keys = append(keys, k)
+						}
+						if !send(wsMessage{Type: "snapshot", Seq: snap.Seq, Dataset: dataset, TSUTC: formatRFC3339Nano(snap.TSUTC),
+							Data: wsSnapshot(snap, dataset, keys)}) {
+							return
+						}
+					}
+					continue
+				}
+				data, ok := subs.filter(e)
+				if !ok {
+					continue
+				}
+				if !send(wsMessage{Type: "update", Seq: e.Seq, Dataset: e.Dataset, Key: e.Key, TSUTC: formatRFC3339Nano(e.TSUTC), Data: data}) {
+					log.Printf("ws client %s: write failed, closing", r.RemoteAddr)
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/eastmoney"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/memstore"
+)
+
+func dialWS(t *testing.T, srv *httptest.Server) *websocket.Conn {
+	t.Helper()
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+type wsTestMessage struct {
+	Type    string          `json:"type"`
+	Dataset string          `json:"dataset"`
+	Data    json.RawMessage `json:"data"`
+	Error   string          `json:"error"`
+}
+
+func readWS(t *testing.T, conn *websocket.Conn) wsTestMessage {
+	t.Helper()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var m wsTestMessage
+	if err := conn.ReadJSON(&m); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func fundflowCodes(t *testing.T, m wsTestMessage) []string {
+	t.Helper()
+	var rows []eastmoney.FundflowRT
+	if err := json.Unmarshal(m.Data, &rows); err != nil {
+		t.Fatalf("%s data: %v", m.Type, err)
+	}
+	var codes []string
+	for _, r := range rows {
+		codes = append(codes, r.Code)
+	}
+	return codes
+}
+
+func TestWSSubscribeSnapshotAndFilteredUpdates(t *testing.T) {
+	mem := memstore.New()
+	ts := time.Now().UTC()
+	mem.SetFundflow(ts, []eastmoney.FundflowRT{{Code: "600519", NetMain: 1}, {Code: "000001", NetMain: 2}})
+	srv := httptest.NewServer(newWSHandler(mem))
+	defer srv.Close()
+	conn := dialWS(t, srv)
+
+	send := func(req wsRequest) {
+		t.Helper()
+		if err := conn.WriteJSON(req); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	send(wsRequest{Op: "subscribe", Dataset: "fundflow", Keys: []string{"600519"}})
+	m := readWS(t, conn)
+	if m.Type != "snapshot" || m.Dataset != "fundflow" || strings.Join(fundflowCodes(t, m), ",") != "600519" {
+		t.Fatalf("snapshot=%+v", m)
+	}
+
+	// Both codes change; only the subscribed one is pushed.
+	mem.SetFundflow(ts.Add(5*time.Second), []eastmoney.FundflowRT{{Code: "600519", NetMain: 3}, {Code: "000001", NetMain: 4}})
+	m = readWS(t, conn)
+	if m.Type != "update" || strings.Join(fundflowCodes(t, m), ",") != "600519" {
+		t.Fatalf("update=%+v", m)
+	}
+
+	send(wsRequest{Op: "subscribe", Dataset: "nope"})
+	if m := readWS(t, conn); m.Type != "error" {
+		t.Fatalf("unknown dataset=%+v", m)
+	}
+
+	// Requests are handled in order, so the pong confirms the unsubscribe took effect.
+	send(wsRequest{Op: "unsubscribe", Dataset: "fundflow"})
+	send(wsRequest{Op: "ping"})
+	if m := readWS(t, conn); m.Type != "pong" {
+		t.Fatalf("ping=%+v", m)
+	}
+	mem.SetFundflow(ts.Add(10*time.Second), []eastmoney.FundflowRT{{Code: "600519", NetMain: 5}})
+	send(wsRequest{Op: "subscribe", Dataset: "agg", Keys: []string{"industry_sum:f62"}})
+	if m := readWS(t, conn); m.Type != "snapshot" || m.Dataset != "agg" {
+		t.Fatalf("agg snapshot=%+v", m)
+	}
+	mem.SetAgg(ts.Add(10*time.Second), "industry_sum", "f62", 7)
+	if m := readWS(t, conn); m.Type != "update" || m.Dataset != "agg" || string(m.Data) != "7" {
+		t.Fatalf("after unsubscribe got %+v", m)
+	}
+}
+
+func TestWSDropsClientThatStopsReading(t *testing.T) {
+	old := wsWriteWait
+	wsWriteWait = 200 * time.Millisecond
+	defer func() { wsWriteWait = old }()
+
+	mem := memstore.New()
+	h := newWSHandler(mem)
+	closed := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h(w, r)
+		close(closed)
+	}))
+	defer srv.Close()
+	conn := dialWS(t, srv)
+
+	if err := conn.WriteJSON(wsRequest{Op: "subscribe", Dataset: "fundflow"}); err != nil {
+		t.Fatal(err)
+	}
+	readWS(t, conn) // snapshot; the client reads nothing after this
+
+	rows := make([]eastmoney.FundflowRT, 2000)
+	for i := range rows {
+		rows[i].Code = fmt.Sprintf("%06d", i)
+		rows[i].Name = strings.Repeat("股", 32)
+	}
+	ts := time.Now().UTC()
+	deadline := time.After(20 * time.Second)
+	for i := 1; ; i++ {
+		for j := range rows {
+			rows[j].NetMain = float64(i)
+		}
+		mem.SetFundflow(ts.Add(time.Duration(i)*time.Second), rows)
+		select {
+		case <-closed:
+			return
+		case <-deadline:
+			t.Fatal("server kept a client that stopped reading")
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+}
+
+func TestWSServerPings(t *testing.T) {
+	old := wsPingPeriod
+	wsPingPeriod = 20 * time.Millisecond
+	defer func() { wsPingPeriod = old }()
+
+	srv := httptest.NewServer(newWSHandler(memstore.New()))
+	defer srv.Close()
+	conn := dialWS(t, srv)
+	pinged := make(chan struct{}, 1)
+	conn.SetPingHandler(func(string) error {
+		select {
+		case pinged <- struct{}{}:
+		default:
+		}
+		return nil
+	})
+	// Control frames are handled while reading; the read itself just times out.
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, _, _ = conn.ReadMessage()
+	select {
+	case <-pinged:
+	default:
+		t.Fatal("no ping from the server")
+	}
+}
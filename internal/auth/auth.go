@@ -0,0 +1,147 @@
+// Package auth checks the credentials configured in web.auth: HTTP basic auth against bcrypt
+// password hashes and bearer tokens against their SHA-256.
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/config"
+)
+
+const (
+	RoleViewer = "viewer"
+	RoleAdmin  = "admin"
+)
+
+// Principal is the user or token a request authenticated as.
+type Principal struct {
+	Name string
+	Role string
+}
+
+// CanWrite reports whether p may use mutating (non-GET) endpoints.
+func (p Principal) CanWrite() bool { return p.Role == RoleAdmin }
+
+// Authenticator checks requests against a fixed set of users and tokens.
+type Authenticator struct {
+	users  map[string]config.WebUser
+	tokens map[[sha256.Size]byte]Principal
+
+	// bcrypt is slow on purpose; verified remembers the basic auth credentials that already
+	// matched (keyed by their SHA-256) so a browser polling the API doesn't pay it per request.
+	mu       sync.Mutex
+	verified map[[sha256.Size]byte]string // -> password hash it matched
+}
+
+const maxVerified = 1024
+
+// New validates the configured hashes and returns an Authenticator for them.
+func New(cfg config.WebAuth) (*Authenticator, error) {
+	a := &Authenticator{
+		users:    make(map[string]config.WebUser, len(cfg.Users)),
+		tokens:   make(map[[sha256.Size]byte]Principal, len(cfg.Tokens)),
+		verified: make(map[[sha256.Size]byte]string),
+	}
+	for _, u := range cfg.Users {
+		if _, err := bcrypt.Cost([]byte(u.PasswordHash)); err != nil {
+			return nil, fmt.Errorf("web.auth.users %q: password_hash is not a bcrypt hash: %w", u.Name, err)
+		}
+		if _, dup := a.users[u.Name]; dup {
+			return nil, fmt.Errorf("web.auth.users: duplicate name %q", u.Name)
+		}
+		a.users[u.Name] = u
+	}
+	for _, t := range cfg.Tokens {
+		b, err := hex.DecodeString(strings.ToLower(t.SHA256))
+		if err != nil || len(b) != sha256.Size {
+			return nil, fmt.Errorf("web.auth.tokens %q: sha256 must be 64 hex characters", t.Name)
+		}
+		var sum [sha256.Size]byte
+		copy(sum[:], b)
+		if _, dup := a.tokens[sum]; dup {
+			return nil, fmt.Errorf("web.auth.tokens %q: same token as another entry", t.Name)
+		}
+		a.tokens[sum] = Principal{Name: t.Name, Role: t.Role}
+	}
+	return a, nil
+}
+
+// Check authenticates r by its Authorization header.
+func (a *Authenticator) Check(r *http.Request) (Principal, bool) {
+	if h := r.Header.Get("Authorization"); len(h) > 7 && strings.EqualFold(h[:7], "Bearer ") {
+		p, ok := a.tokens[sha256.Sum256([]byte(strings.TrimSpace(h[7:])))]
+		return p, ok
+	}
+	name, password, ok := r.BasicAuth()
+	if !ok {
+		return Principal{}, false
+	}
+	u, ok := a.users[name]
+	if !ok {
+		// Same work as a wrong password, so unknown names can't be told apart by timing.
+		_ = bcrypt.CompareHashAndPassword(dummyHash(), []byte(password))
+		return Principal{}, false
+	}
+	key := sha256.Sum256([]byte(name + "\x00" + password))
+	a.mu.Lock()
+	hash, hit := a.verified[key]
+	a.mu.Unlock()
+	if hit && subtle.ConstantTimeCompare([]byte(hash), []byte(u.PasswordHash)) == 1 {
+		return Principal{Name: u.Name, Role: u.Role}, true
+	}
+	if bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)) != nil {
+		return Principal{}, false
+	}
+	a.mu.Lock()
+	if len(a.verified) >= maxVerified {
+		clear(a.verified)
+	}
+	a.verified[key] = u.PasswordHash
+	a.mu.Unlock()
+	return Principal{Name: u.Name, Role: u.Role}, true
+}
+
+var dummyHash = sync.OnceValue(func() []byte {
+	b, _ := bcrypt.GenerateFromPassword([]byte("aof"), bcrypt.DefaultCost)
+	return b
+})
+
+// HashPassword returns the bcrypt hash to put in web.auth.users[].password_hash.
+func HashPassword(password string) (string, error) {
+	b, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	return string(b), err
+}
+
+// NewToken returns a random bearer token and the SHA-256 to put in web.auth.tokens[].sha256.
+func NewToken() (token, sum string, err error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token = "aof_" + hex.EncodeToString(b)
+	h := sha256.Sum256([]byte(token))
+	return token, hex.EncodeToString(h[:]), nil
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying p.
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the principal stored by WithPrincipal, if any.
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/config"
+)
+
+func TestCheck(t *testing.T) {
+	hash, err := HashPassword("s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, sum, err := NewToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := New(config.WebAuth{
+		Enabled: true,
+		Users:   []config.WebUser{{Name: "alice", PasswordHash: hash, Role: RoleAdmin}},
+		Tokens:  []config.WebToken{{Name: "grafana", SHA256: sum, Role: RoleViewer}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	basic := func(user, pass string) (Principal, bool) {
+		r := httptest.NewRequest("GET", "/api/realtime", nil)
+		r.SetBasicAuth(user, pass)
+		return a.Check(r)
+	}
+	for i := 0; i < 2; i++ { // second round hits the verified cache
+		if p, ok := basic("alice", "s3cret"); !ok || p.Name != "alice" || !p.CanWrite() {
+			t.Fatalf("alice = %+v, %v", p, ok)
+		}
+		if _, ok := basic("alice", "wrong"); ok {
+			t.Fatal("wrong password accepted")
+		}
+	}
+	if _, ok := basic("bob", "s3cret"); ok {
+		t.Fatal("unknown user accepted")
+	}
+
+	bearer := func(tok string) (Principal, bool) {
+		r := httptest.NewRequest("GET", "/api/realtime", nil)
+		r.Header.Set("Authorization", "Bearer "+tok)
+		return a.Check(r)
+	}
+	if p, ok := bearer(token); !ok || p.Name != "grafana" || p.CanWrite() {
+		t.Fatalf("token = %+v, %v", p, ok)
+	}
+	if _, ok := bearer(token + "x"); ok {
+		t.Fatal("wrong token accepted")
+	}
+	if _, ok := a.Check(httptest.NewRequest("GET", "/", nil)); ok {
+		t.Fatal("request without credentials accepted")
+	}
+}
+
+func TestNewRejectsBadHashes(t *testing.T) {
+	if _, err := New(config.WebAuth{Users: []config.WebUser{{Name: "a", PasswordHash: "plaintext"}}}); err == nil {
+		t.Fatal("plaintext password_hash accepted")
+	}
+	if _, err := New(config.WebAuth{Tokens: []config.WebToken{{Name: "t", SHA256: "zz"}}}); err == nil {
+		t.Fatal("bad sha256 accepted")
+	}
+}
@@ -0,0 +1,83 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/config"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/market"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/symbol"
+)
+
+// runAuctionLoop samples call auctions (09:15-09:25, 14:57-15:00) independently of the realtime loop,
+// which is gated by continuous trading hours and usually ticks too slowly for a 10-minute window.
+func (c *Collector) runAuctionLoop(ctx context.Context) {
+	var lastPhase string
+	for {
+		cfg := c.config()
+		now := time.Now().In(c.loc)
+		phase := ""
+		if cfg.Auction.Enabled {
+			phase = market.CNAuctionPhase(now)
+		}
+		if phase != lastPhase {
+			if phase != "" {
+				log.Printf("auction capture: phase=%s interval=%ds", phase, cfg.Auction.IntervalSeconds)
+			}
+			lastPhase = phase
+		}
+
+		sleep := 15 * time.Second
+		if phase != "" {
+			if err := c.collectAuctionOnce(ctx, now, phase, cfg); err != nil {
+				log.Printf("auction tick error: %v", err)
+			}
+			sleep = time.Duration(cfg.Auction.IntervalSeconds) * time.Second
+			if sleep <= 0 {
+				sleep = 5 * time.Second
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(sleep):
+		}
+	}
+}
+
+func (c *Collector) collectAuctionOnce(ctx context.Context, now time.Time, phase string, cfg config.Config) error {
+	ts := now.UTC()
+	tradeDate := now.In(c.loc).Format("2006-01-02")
+
+	if cfg.Auction.Watchlist != nil && *cfg.Auction.Watchlist && len(cfg.Watchlist) > 0 {
+		// Invalid symbols are reported by the realtime fundflow tick; just skip them here.
+		secids, _ := symbol.SplitEastmoneySecIDs(cfg.Watchlist)
+		rows, err := c.em.AuctionRealtimeChunked(ctx, secids, cfg.Realtime.FundflowChunkSize, cfg.Realtime.FundflowConcurrency)
+		if err != nil {
+			if len(rows) == 0 {
+				return fmt.Errorf("auction watchlist: %w", err)
+			}
+			log.Printf("auction watchlist partial (%d rows): %v", len(rows), err)
+		}
+		if err := c.st.UpsertAuctionRT(ts, tradeDate, phase, "watchlist", rows); err != nil {
+			return fmt.Errorf("store auction watchlist: %w", err)
+		}
+	}
+
+	if cfg.Auction.TopSize > 0 && cfg.Auction.FS != "" {
+		rows, err := c.em.AuctionTopList(ctx, cfg.Auction.FS, cfg.Auction.FID, cfg.Auction.TopSize, cfg.Realtime.FundflowConcurrency)
+		if err != nil {
+			if len(rows) == 0 {
+				return fmt.Errorf("auction toplist: %w", err)
+			}
+			log.Printf("auction toplist partial (%d rows): %v", len(rows), err)
+		}
+		if err := c.st.UpsertAuctionRT(ts, tradeDate, phase, "toplist", rows); err != nil {
+			return fmt.Errorf("store auction toplist: %w", err)
+		}
+	}
+	return nil
+}
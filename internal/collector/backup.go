@@ -0,0 +1,59 @@
+package collector
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/store"
+)
+
+// Backup writes an online copy of the database to <dir>/aof-<utc>.db and then deletes all but
+// the newest keep backups in dir (backup.keep: 7 by default, config requires >= 1).
+// It returns the new file's path.
+// The copy is written under a .tmp name first, so a failed backup never counts towards keep.
+func Backup(st store.Store, dir string, keep int, nowUTC time.Time) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, "aof-"+nowUTC.UTC().Format("20060102T150405Z")+".db")
+	tmp := path + ".tmp"
+	_ = os.Remove(tmp)
+	if err := st.Backup(tmp); err != nil {
+		_ = os.Remove(tmp)
+		return "", err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return "", err
+	}
+	if _, err := pruneBackups(dir, keep); err != nil {
+		return path, fmt.Errorf("rotate backups: %w", err)
+	}
+	return path, nil
+}
+
+// pruneBackups deletes the oldest aof-*.db files in dir beyond keep and returns their paths.
+// A keep <= 0 deletes nothing rather than every backup.
+func pruneBackups(dir string, keep int) ([]string, error) {
+	if keep <= 0 {
+		return nil, nil
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "aof-*.db"))
+	if err != nil {
+		return nil, err
+	}
+	// Names embed a fixed-width UTC timestamp, so they sort oldest first.
+	sort.Strings(files)
+	if len(files) <= keep {
+		return nil, nil
+	}
+	removed := files[:len(files)-keep]
+	for _, f := range removed {
+		if err := os.Remove(f); err != nil {
+			return nil, err
+		}
+	}
+	return removed, nil
+}
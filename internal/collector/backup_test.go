@@ -0,0 +1,82 @@
+package collector
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/config"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/store"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/store/sqlite"
+)
+
+func TestPruneBackups(t *testing.T) {
+	dir := t.TempDir()
+	names := []string{
+		"aof-20240103T194000Z.db",
+		"aof-20240101T194000Z.db",
+		"aof-20240102T194000Z.db",
+		"aof-20240104T194000Z.db.tmp",
+		"notes.txt",
+	}
+	for _, n := range names {
+		if err := os.WriteFile(filepath.Join(dir, n), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	removed, err := pruneBackups(dir, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 1 || filepath.Base(removed[0]) != "aof-20240101T194000Z.db" {
+		t.Fatalf("removed=%v", removed)
+	}
+	left, _ := os.ReadDir(dir)
+	if len(left) != 4 {
+		t.Fatalf("left %d files", len(left))
+	}
+
+	if removed, _ := pruneBackups(dir, 0); len(removed) != 0 {
+		t.Fatalf("keep=0 removed %v", removed)
+	}
+}
+
+func TestBackupRotation(t *testing.T) {
+	st, err := store.Open(config.Config{DBPath: filepath.Join(t.TempDir(), "aof.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	if _, err := st.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "backups")
+	t0 := time.Date(2024, 1, 2, 19, 40, 0, 0, time.UTC)
+	var paths []string
+	for i := 0; i < 4; i++ {
+		p, err := Backup(st, dir, 2, t0.Add(time.Duration(i)*24*time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, p)
+	}
+	if filepath.Base(paths[3]) != "aof-20240105T194000Z.db" {
+		t.Fatalf("path=%s", paths[3])
+	}
+
+	left, err := filepath.Glob(filepath.Join(dir, "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(left) != 2 || left[0] != paths[2] || left[1] != paths[3] {
+		t.Fatalf("left=%v, want the newest 2 of %v", left, paths)
+	}
+	for _, p := range left {
+		if _, err := sqlite.CheckBackup(p); err != nil {
+			t.Errorf("%s: %v", p, err)
+		}
+	}
+}
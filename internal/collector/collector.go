@@ -2,9 +2,10 @@ package collector
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
 	"log"
+	"maps"
+	"strings"
 	"time"
 
 	"github.com/pcdogyu/A-Stock-Order-Flow/internal/config"
@@ -12,6 +13,7 @@ import (
 	"github.com/pcdogyu/A-Stock-Order-Flow/internal/memstore"
 	"github.com/pcdogyu/A-Stock-Order-Flow/internal/market"
 	"github.com/pcdogyu/A-Stock-Order-Flow/internal/symbol"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/store"
 	"github.com/pcdogyu/A-Stock-Order-Flow/internal/store/sqlite"
 )
 
@@ -21,7 +23,7 @@ type ConfigProvider interface {
 
 type Collector struct {
 	cfgp ConfigProvider
-	db  *sql.DB
+	st  store.Store
 	em  *eastmoney.Client
 	loc *time.Location
 	mem *memstore.Store
@@ -30,27 +32,65 @@ type Collector struct {
 	lastConcept  time.Time
 	lastAllStocks time.Time
 	lastToplist  []eastmoney.TopItem
+	lastInvalid  int
+	// lastFlow is the previous persisted fundflow sample per code (persist loop only).
+	lastFlow map[string]flowSample
+	// persisted is the memstore content version last written per dataset key (persist loop only).
+	persisted map[string]uint64
 }
 
-func New(cfgp ConfigProvider, db *sql.DB, mem *memstore.Store) *Collector {
+func New(cfgp ConfigProvider, st store.Store, mem *memstore.Store) *Collector {
 	loc, _ := time.LoadLocation("Asia/Shanghai")
 	if mem == nil {
 		mem = memstore.New()
 	}
 	return &Collector{
 		cfgp: cfgp,
-		db:  db,
+		st:  st,
 		em:  eastmoney.NewClient(),
 		loc: loc,
 		mem: mem,
+
+		lastFlow: make(map[string]flowSample),
+		persisted: make(map[string]uint64),
+	}
+}
+
+// config returns the current config with Watchlist extended by the members of all watch groups,
+// so every collector (realtime, auction, daily) covers the union.
+func (c *Collector) config() config.Config {
+	cfg := c.cfgp.Get()
+	extra, err := c.st.QueryWatchGroupSymbols()
+	if err != nil {
+		log.Printf("watch groups err: %v", err)
+		return cfg
+	}
+	if len(extra) == 0 {
+		return cfg
+	}
+	seen := make(map[string]struct{}, len(cfg.Watchlist)+len(extra))
+	union := make([]string, 0, len(cfg.Watchlist)+len(extra))
+	for _, list := range [][]string{cfg.Watchlist, extra} {
+		for _, sym := range list {
+			key := strings.ToUpper(strings.TrimSpace(sym))
+			if _, dup := seen[key]; dup {
+				continue
+			}
+			seen[key] = struct{}{}
+			union = append(union, sym)
+		}
 	}
+	cfg.Watchlist = union
+	return cfg
 }
 
 func (c *Collector) RunRealtime(ctx context.Context) error {
+	go c.runAuctionLoop(ctx)
+
 	var lastLog time.Time
 	var lastInterval int
 	for {
-		cfg := c.cfgp.Get()
+		cfg := c.config()
 		if lastInterval != cfg.Realtime.IntervalSeconds || time.Since(lastLog) > time.Minute {
 			log.Printf("realtime running: interval=%ds watchlist=%d toplist=%d",
 				cfg.Realtime.IntervalSeconds, len(cfg.Watchlist), cfg.Toplist.Size)
@@ -84,27 +124,39 @@ func (c *Collector) collectRealtimeOnce(ctx context.Context, now time.Time, cfg
 	// 1) Northbound (沪股通/深股通)
 	nb, err := c.em.NorthboundRealtime(ctx)
 	if err != nil {
+		c.mem.SetError(memstore.DatasetKeyNorthbound, ts, err)
 		return fmt.Errorf("northbound rt: %w", err)
 	}
 	c.mem.SetNorthbound(ts, nb)
 
 	// 2) Watchlist fundflow (主力/超大/大/中/小)
-	secids, err := symbol.ToEastmoneySecIDs(cfg.Watchlist)
-	if err != nil {
-		return err
+	// Bad symbols are skipped (and surfaced in the snapshot) instead of failing the tick;
+	// chunks that fail keep their previous values and last-success timestamps.
+	secids, invalid := symbol.SplitEastmoneySecIDs(cfg.Watchlist)
+	c.mem.SetInvalidSymbols(invalid)
+	if len(invalid) != c.lastInvalid {
+		for _, it := range invalid {
+			log.Printf("watchlist symbol skipped: %s: %s", it.Symbol, it.Err)
+		}
+		c.lastInvalid = len(invalid)
 	}
-	ffRows, err := c.em.FundflowRealtime(ctx, secids)
+	ffRows, err := c.em.FundflowRealtimeChunked(ctx, secids, cfg.Realtime.FundflowChunkSize, cfg.Realtime.FundflowConcurrency)
 	if err != nil {
-		return fmt.Errorf("fundflow rt: %w", err)
+		c.mem.SetError(memstore.DatasetKeyFundflow, ts, err)
+		if len(ffRows) == 0 {
+			return fmt.Errorf("fundflow rt: %w", err)
+		}
+		log.Printf("fundflow rt partial (%d/%d rows): %v", len(ffRows), len(secids), err)
 	}
 	c.mem.SetFundflow(ts, ffRows)
 
 	// 3) Top list by net main inflow (or any Eastmoney fid field)
 	top, err := c.em.TopListDynamic(ctx, cfg.Toplist.FS, cfg.Toplist.FID, cfg.Toplist.Size)
 	if err != nil {
+		// Memory keeps the last list; only the error is recorded so its fetch time stays honest.
+		c.mem.SetError(memstore.DatasetKeyToplist(cfg.Toplist.FID), ts, err)
 		if len(c.lastToplist) > 0 {
 			log.Printf("toplist rt err: %v (use cache)", err)
-			c.mem.SetToplist(ts, cfg.Toplist.FID, c.lastToplist)
 		} else {
 			return fmt.Errorf("toplist rt: %w", err)
 		}
@@ -120,6 +172,7 @@ func (c *Collector) collectRealtimeOnce(ctx context.Context, now time.Time, cfg
 			items, err := c.em.BoardListAll(ctx, cfg.Industry.FS, cfg.Industry.FID)
 			if err != nil {
 				log.Printf("industry boards rt err: %v", err)
+				c.mem.SetError(memstore.DatasetKeyBoard("industry", cfg.Industry.FID), ts, err)
 			} else {
 				c.mem.SetBoard(ts, "industry", cfg.Industry.FID, items)
 				var sum float64
@@ -144,6 +197,7 @@ func (c *Collector) collectRealtimeOnce(ctx context.Context, now time.Time, cfg
 			}
 			if err != nil {
 				log.Printf("concept boards rt err: %v", err)
+				c.mem.SetError(memstore.DatasetKeyBoard("concept", cfg.Concept.FID), ts, err)
 			} else {
 				c.mem.SetBoard(ts, "concept", cfg.Concept.FID, items)
 			}
@@ -158,6 +212,7 @@ func (c *Collector) collectRealtimeOnce(ctx context.Context, now time.Time, cfg
 			sum, total, err := c.em.AllStocksSum(ctx, cfg.MarketAgg.FS, cfg.MarketAgg.FID, cfg.MarketAgg.Concurrency)
 			if err != nil {
 				log.Printf("allstocks sum rt err: %v", err)
+				c.mem.SetError(memstore.DatasetKeyAgg("allstocks_sum", cfg.MarketAgg.FID), ts, err)
 			} else {
 				_ = total // kept for logging later if needed
 				c.mem.SetAgg(ts, "allstocks_sum", cfg.MarketAgg.FID, sum)
@@ -169,42 +224,74 @@ func (c *Collector) collectRealtimeOnce(ctx context.Context, now time.Time, cfg
 	return nil
 }
 
-// PersistRealtimeSnapshot writes an in-memory snapshot to SQLite "rt" tables.
-// Caller controls the interval.
+// PersistRealtimeSnapshot writes an in-memory snapshot to the "rt" tables as one snapshot
+// (a single transaction recorded in rt_snapshots). Caller controls the interval.
 func (c *Collector) PersistRealtimeSnapshot(tsUTC time.Time) error {
 	snap := c.mem.Snapshot(tsUTC)
-	agg := snap.AggByKey
-	if agg == nil {
-		agg = make(map[string]float64)
+	// Only datasets whose content changed since the last persist are written, so idle
+	// nights and weekends don't keep re-inserting identical rows under a new ts_utc.
+	dirty := func(key string) bool {
+		v := snap.Versions[key]
+		return v > 0 && v != c.persisted[key]
+	}
+	w := sqlite.RTWrite{
+		Toplist: make(map[string][]eastmoney.TopItem),
+		Boards:  make(map[string][]eastmoney.TopItem),
+		Agg:     make(map[string]float64),
+	}
+	// written maps dataset keys to the versions being written; they count as persisted on commit.
+	written := make(map[string]uint64)
+	mark := func(key string) {
+		if _, ok := written[key]; !ok {
+			written[key] = snap.Versions[key]
+			w.Datasets = append(w.Datasets, key)
+		}
+	}
+
+	for key, v := range snap.AggByKey {
+		source, fid, ok := split2(key)
+		if !ok || !dirty(memstore.DatasetKeyAgg(source, fid)) {
+			continue
+		}
+		w.Agg[key] = v
+		mark(memstore.DatasetKeyAgg(source, fid))
 	}
 	// Ensure industry_sum is available even if realtime agg wasn't set.
 	for key, rows := range snap.BoardsByKey {
 		bt, fid, ok := split2(key)
-		if !ok || bt != "industry" {
+		if !ok || bt != "industry" || !dirty(memstore.DatasetKeyBoard(bt, fid)) {
 			continue
 		}
 		aggKey := "industry_sum:" + fid
-		if _, exists := agg[aggKey]; exists {
+		if _, exists := snap.AggByKey[aggKey]; exists {
 			continue
 		}
 		var sum float64
 		for _, it := range rows {
 			sum += it.Price
 		}
-		agg[aggKey] = sum
+		w.Agg[aggKey] = sum
 	}
 
-	if snap.Northbound != nil {
-		if err := sqlite.UpsertNorthboundRT(c.db, tsUTC, *snap.Northbound); err != nil {
-			return err
-		}
+	if snap.Northbound != nil && dirty(memstore.DatasetKeyNorthbound) {
+		w.Northbound = snap.Northbound
+		mark(memstore.DatasetKeyNorthbound)
 	}
-	if err := sqlite.UpsertFundflowRT(c.db, tsUTC, snap.Fundflow); err != nil {
-		return err
+	if dirty(memstore.DatasetKeyFundflow) {
+		w.Fundflow = snap.Fundflow
+		mark(memstore.DatasetKeyFundflow)
+	}
+	// Deltas are computed against a copy so a failed write doesn't advance the baseline.
+	lastFlow := c.lastFlow
+	if inFlowSession(tsUTC, c.loc) {
+		lastFlow = maps.Clone(c.lastFlow)
+		w.TradeDate = tsUTC.In(c.loc).Format("2006-01-02")
+		w.FlowDeltas = flowDeltas(lastFlow, flowSamples(snap, tsUTC, w.TradeDate))
 	}
 	for fid, rows := range snap.ToplistByFID {
-		if err := sqlite.UpsertTopListRT(c.db, tsUTC, fid, rows); err != nil {
-			return err
+		if key := memstore.DatasetKeyToplist(fid); dirty(key) {
+			w.Toplist[fid] = rows
+			mark(key)
 		}
 	}
 	for key, rows := range snap.BoardsByKey {
@@ -212,19 +299,20 @@ func (c *Collector) PersistRealtimeSnapshot(tsUTC time.Time) error {
 		if !ok {
 			continue
 		}
-		if err := sqlite.UpsertBoardRT(c.db, tsUTC, bt, fid, rows); err != nil {
-			return err
+		if vkey := memstore.DatasetKeyBoard(bt, fid); dirty(vkey) {
+			w.Boards[key] = rows
+			mark(vkey)
 		}
 	}
-	for key, v := range agg {
-		source, fid, ok := split2(key)
-		if !ok {
-			continue
-		}
-		if err := sqlite.UpsertMarketAggRT(c.db, tsUTC, source, fid, v); err != nil {
-			return err
-		}
+	w.CompactBoards = c.cfgp.Get().Persist.BoardFormat == "compact"
+
+	if _, err := c.st.WriteRTSnapshot(tsUTC, w); err != nil {
+		return err
 	}
+	for key, v := range written {
+		c.persisted[key] = v
+	}
+	c.lastFlow = lastFlow
 	return nil
 }
 
@@ -236,101 +324,3 @@ func split2(s string) (a, b string, ok bool) {
 	}
 	return "", "", false
 }
-
-func (c *Collector) RunDaily(ctx context.Context, date time.Time) error {
-	cfg := c.cfgp.Get()
-	// We treat "daily" as "fetch latest and persist to date bucket".
-	// For free sources this is more robust than attempting holiday calendars.
-	tradeDate := date.In(c.loc).Format("2006-01-02")
-	log.Printf("daily started: trade_date=%s watchlist=%d", tradeDate, len(cfg.Watchlist))
-
-	// 1) Northbound: use realtime endpoint and persist as daily snapshot.
-	nb, err := c.em.NorthboundRealtime(ctx)
-	if err != nil {
-		return fmt.Errorf("northbound daily via rt: %w", err)
-	}
-	if err := sqlite.UpsertNorthboundDaily(c.db, tradeDate, nb); err != nil {
-		return fmt.Errorf("store northbound daily: %w", err)
-	}
-
-	// 2) Fundflow daily: use fflow kline endpoint (daily series) and take last entry.
-	for _, sym := range cfg.Watchlist {
-		secid, err := symbol.ToEastmoneySecID(sym)
-		if err != nil {
-			log.Printf("skip symbol=%q: %v", sym, err)
-			continue
-		}
-		row, err := c.em.FundflowDailyLatest(ctx, secid)
-		if err != nil {
-			log.Printf("fundflow daily err symbol=%s: %v", sym, err)
-			continue
-		}
-		if err := sqlite.UpsertFundflowDaily(c.db, row.TradeDate, row); err != nil {
-			log.Printf("store fundflow daily err symbol=%s: %v", sym, err)
-		}
-	}
-
-	// 3) Margin (融资融券) daily: query per-symbol latest record, then store.
-	for _, sym := range cfg.Watchlist {
-		code, err := symbol.CodeOnly(sym)
-		if err != nil {
-			log.Printf("skip symbol=%q: %v", sym, err)
-			continue
-		}
-		row, err := c.em.MarginLatestByCode(ctx, code)
-		if err != nil {
-			log.Printf("margin daily err symbol=%s: %v", sym, err)
-			continue
-		}
-		if err := sqlite.UpsertMarginDaily(c.db, row.TradeDate, row); err != nil {
-			log.Printf("store margin daily err symbol=%s: %v", sym, err)
-		}
-	}
-
-	// 4) Industry/Concept daily snapshots + whole-market aggregate.
-	if cfg.Industry.Enabled {
-		items, err := c.em.BoardListAll(ctx, cfg.Industry.FS, cfg.Industry.FID)
-		if err != nil {
-			log.Printf("industry boards daily err: %v", err)
-		} else {
-			_ = sqlite.UpsertBoardDaily(c.db, tradeDate, "industry", cfg.Industry.FID, items)
-			var sum float64
-			for _, it := range items {
-				sum += it.Price
-			}
-			_ = sqlite.UpsertMarketAggDaily(c.db, tradeDate, "industry_sum", cfg.Industry.FID, sum)
-		}
-	}
-	if cfg.Concept.Enabled {
-		var items []eastmoney.TopItem
-		var err error
-		if cfg.Concept.CollectAll {
-			items, err = c.em.BoardListAll(ctx, cfg.Concept.FS, cfg.Concept.FID)
-		} else {
-			items, err = c.em.BoardListTop(ctx, cfg.Concept.FS, cfg.Concept.FID, cfg.Concept.TopSize)
-		}
-		if err != nil {
-			log.Printf("concept boards daily err: %v", err)
-		} else {
-			_ = sqlite.UpsertBoardDaily(c.db, tradeDate, "concept", cfg.Concept.FID, items)
-		}
-	}
-
-	if cfg.MarketAgg.Enabled {
-		sum, _, err := c.em.AllStocksSum(ctx, cfg.MarketAgg.FS, cfg.MarketAgg.FID, cfg.MarketAgg.Concurrency)
-		if err != nil {
-			log.Printf("allstocks sum daily err: %v", err)
-		} else {
-			_ = sqlite.UpsertMarketAggDaily(c.db, tradeDate, "allstocks_sum", cfg.MarketAgg.FID, sum)
-		}
-	}
-
-	// Daily run is a good place to apply retention as well (for cron/task-scheduler usage).
-	if cfg.RetentionDays > 0 {
-		if err := sqlite.CleanupOldData(c.db, time.Now().UTC(), cfg.RetentionDays); err != nil {
-			log.Printf("cleanup err: %v", err)
-		}
-	}
-
-	return nil
-}
@@ -0,0 +1,270 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/config"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/eastmoney"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/store/sqlite"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/symbol"
+)
+
+// Daily datasets. Per-symbol datasets (fundflow, margin) use the watchlist symbol as item key.
+const (
+	DailyNorthbound = "northbound"
+	DailyFundflow   = "fundflow"
+	DailyMargin     = "margin"
+	DailyIndustry   = "board_industry"
+	DailyConcept    = "board_concept"
+	DailyMarketAgg  = "market_agg"
+)
+
+// DailyItem is one unit of daily work, retried independently.
+type DailyItem struct {
+	Dataset string `json:"dataset"`
+	Key     string `json:"key,omitempty"`
+}
+
+type DailyFailure struct {
+	DailyItem
+	Err string `json:"error"`
+}
+
+// DailyReport summarizes a daily run. Every run and item outcome is also recorded in
+// daily_runs / daily_run_items so completeness can be checked per trade date later.
+type DailyReport struct {
+	RunID      int64          `json:"run_id"`
+	TradeDate  string         `json:"trade_date"`
+	StartedAt  time.Time      `json:"started_at"`
+	FinishedAt time.Time      `json:"finished_at"`
+	Total      int            `json:"total"`
+	OK         int            `json:"ok"`
+	Failed     []DailyFailure `json:"failed,omitempty"`
+}
+
+// FailedItems returns the items that failed in this report.
+func (r DailyReport) FailedItems() []DailyItem {
+	out := make([]DailyItem, 0, len(r.Failed))
+	for _, f := range r.Failed {
+		out = append(out, f.DailyItem)
+	}
+	return out
+}
+
+func (r DailyReport) err() error {
+	if len(r.Failed) > 0 {
+		return fmt.Errorf("daily %s: %d of %d items failed", r.TradeDate, len(r.Failed), r.Total)
+	}
+	return nil
+}
+
+// DailyItems lists everything a full daily run covers for cfg.
+func DailyItems(cfg config.Config) []DailyItem {
+	items := []DailyItem{{Dataset: DailyNorthbound}}
+	for _, sym := range cfg.Watchlist {
+		items = append(items, DailyItem{Dataset: DailyFundflow, Key: sym})
+	}
+	for _, sym := range cfg.Watchlist {
+		items = append(items, DailyItem{Dataset: DailyMargin, Key: sym})
+	}
+	if cfg.Industry.Enabled {
+		items = append(items, DailyItem{Dataset: DailyIndustry})
+	}
+	if cfg.Concept.Enabled {
+		items = append(items, DailyItem{Dataset: DailyConcept})
+	}
+	if cfg.MarketAgg.Enabled {
+		items = append(items, DailyItem{Dataset: DailyMarketAgg})
+	}
+	return items
+}
+
+// RunDaily fetches every daily dataset for date and applies retention.
+// Failed items are reported rather than aborting the run; err is non-nil if any item failed.
+// trigger is recorded with the run ("cli", "schedule", "manual", ...).
+func (c *Collector) RunDaily(ctx context.Context, date time.Time, trigger string) (DailyReport, error) {
+	cfg := c.config()
+	items := DailyItems(cfg)
+	rep := c.runDailyItems(ctx, c.startDailyRun(date, trigger, items), items)
+
+	// Daily run is a good place to roll up today's rt rows and apply retention as well
+	// (for cron/task-scheduler usage).
+	if cfg.RetentionDays > 0 {
+		if err := Housekeeping(c.st, cfg, time.Now().UTC()); err != nil {
+			log.Printf("cleanup err: %v", err)
+		}
+	}
+	return rep, rep.err()
+}
+
+// RetryDaily re-runs the failures of rep within the same run and returns the merged report.
+func (c *Collector) RetryDaily(ctx context.Context, date time.Time, rep DailyReport) (DailyReport, error) {
+	items := rep.FailedItems()
+	rep.Failed = nil
+	rep = c.runDailyItems(ctx, rep, items)
+	return rep, rep.err()
+}
+
+// RetryFailedDaily starts a new run covering only the items whose latest recorded outcome
+// for date's trade date is a failure. The report is empty if nothing needs a retry.
+func (c *Collector) RetryFailedDaily(ctx context.Context, date time.Time, trigger string) (DailyReport, error) {
+	tradeDate := date.In(c.loc).Format("2006-01-02")
+	status, err := c.st.QueryDailyItemStatus(tradeDate)
+	if err != nil {
+		return DailyReport{TradeDate: tradeDate}, err
+	}
+	var items []DailyItem
+	for _, it := range status {
+		if it.Status != sqlite.DailyItemOK {
+			items = append(items, DailyItem{Dataset: it.Dataset, Key: it.Key})
+		}
+	}
+	if len(items) == 0 {
+		log.Printf("daily retry: trade_date=%s nothing to retry", tradeDate)
+		return DailyReport{TradeDate: tradeDate}, nil
+	}
+	rep := c.runDailyItems(ctx, c.startDailyRun(date, trigger, items), items)
+	return rep, rep.err()
+}
+
+func (c *Collector) startDailyRun(date time.Time, trigger string, items []DailyItem) DailyReport {
+	// We treat "daily" as "fetch latest and persist to date bucket".
+	// For free sources this is more robust than attempting holiday calendars.
+	rep := DailyReport{
+		TradeDate: date.In(c.loc).Format("2006-01-02"),
+		StartedAt: time.Now().UTC(),
+		Total:     len(items),
+	}
+	id, err := c.st.CreateDailyRun(rep.TradeDate, trigger, rep.StartedAt, rep.Total)
+	if err != nil {
+		log.Printf("daily run log err: %v", err)
+	}
+	rep.RunID = id
+	return rep
+}
+
+func (c *Collector) runDailyItems(ctx context.Context, rep DailyReport, items []DailyItem) DailyReport {
+	cfg := c.config()
+	log.Printf("daily started: trade_date=%s run=%d items=%d watchlist=%d", rep.TradeDate, rep.RunID, len(items), len(cfg.Watchlist))
+
+	for _, it := range items {
+		err := ctx.Err()
+		if err == nil {
+			err = c.runDailyItem(ctx, cfg, rep.TradeDate, it)
+		}
+		status, errMsg := sqlite.DailyItemOK, ""
+		if err != nil {
+			log.Printf("daily err dataset=%s key=%s: %v", it.Dataset, it.Key, err)
+			status, errMsg = sqlite.DailyItemFailed, err.Error()
+			rep.Failed = append(rep.Failed, DailyFailure{DailyItem: it, Err: errMsg})
+		} else {
+			rep.OK++
+		}
+		if rep.RunID > 0 {
+			if err := c.st.UpsertDailyRunItem(rep.RunID, it.Dataset, it.Key, status, errMsg, time.Now().UTC()); err != nil {
+				log.Printf("daily run log err: %v", err)
+			}
+		}
+	}
+	rep.FinishedAt = time.Now().UTC()
+	if rep.RunID > 0 {
+		if err := c.st.FinishDailyRun(rep.RunID, rep.FinishedAt); err != nil {
+			log.Printf("daily run log err: %v", err)
+		}
+	}
+	log.Printf("daily finished: trade_date=%s run=%d ok=%d failed=%d", rep.TradeDate, rep.RunID, rep.OK, len(rep.Failed))
+	return rep
+}
+
+func (c *Collector) runDailyItem(ctx context.Context, cfg config.Config, tradeDate string, it DailyItem) error {
+	switch it.Dataset {
+	case DailyNorthbound:
+		// Use realtime endpoint and persist as daily snapshot.
+		nb, err := c.em.NorthboundRealtime(ctx)
+		if err != nil {
+			return fmt.Errorf("northbound daily via rt: %w", err)
+		}
+		if err := c.st.UpsertNorthboundDaily(tradeDate, nb); err != nil {
+			return fmt.Errorf("store northbound daily: %w", err)
+		}
+		return nil
+
+	case DailyFundflow:
+		// Use fflow kline endpoint (daily series) and take last entry.
+		secid, err := symbol.ToEastmoneySecID(it.Key)
+		if err != nil {
+			return err
+		}
+		row, err := c.em.FundflowDailyLatest(ctx, secid)
+		if err != nil {
+			return fmt.Errorf("fundflow daily: %w", err)
+		}
+		if err := c.st.UpsertFundflowDaily(row.TradeDate, row); err != nil {
+			return fmt.Errorf("store fundflow daily: %w", err)
+		}
+		return nil
+
+	case DailyMargin:
+		// Query per-symbol latest record (融资融券), then store.
+		code, err := symbol.CodeOnly(it.Key)
+		if err != nil {
+			return err
+		}
+		row, err := c.em.MarginLatestByCode(ctx, code)
+		if err != nil {
+			return fmt.Errorf("margin daily: %w", err)
+		}
+		if err := c.st.UpsertMarginDaily(row.TradeDate, row); err != nil {
+			return fmt.Errorf("store margin daily: %w", err)
+		}
+		return nil
+
+	case DailyIndustry:
+		// Industry daily snapshot + whole-market aggregate (industry sum).
+		items, err := c.em.BoardListAll(ctx, cfg.Industry.FS, cfg.Industry.FID)
+		if err != nil {
+			return fmt.Errorf("industry boards daily: %w", err)
+		}
+		if err := c.st.UpsertBoardDaily(tradeDate, "industry", cfg.Industry.FID, items); err != nil {
+			return fmt.Errorf("store industry boards daily: %w", err)
+		}
+		var sum float64
+		for _, it := range items {
+			sum += it.Price
+		}
+		if err := c.st.UpsertMarketAggDaily(tradeDate, "industry_sum", cfg.Industry.FID, sum); err != nil {
+			return fmt.Errorf("store industry_sum daily: %w", err)
+		}
+		return nil
+
+	case DailyConcept:
+		var items []eastmoney.TopItem
+		var err error
+		if cfg.Concept.CollectAll {
+			items, err = c.em.BoardListAll(ctx, cfg.Concept.FS, cfg.Concept.FID)
+		} else {
+			items, err = c.em.BoardListTop(ctx, cfg.Concept.FS, cfg.Concept.FID, cfg.Concept.TopSize)
+		}
+		if err != nil {
+			return fmt.Errorf("concept boards daily: %w", err)
+		}
+		if err := c.st.UpsertBoardDaily(tradeDate, "concept", cfg.Concept.FID, items); err != nil {
+			return fmt.Errorf("store concept boards daily: %w", err)
+		}
+		return nil
+
+	case DailyMarketAgg:
+		sum, _, err := c.em.AllStocksSum(ctx, cfg.MarketAgg.FS, cfg.MarketAgg.FID, cfg.MarketAgg.Concurrency)
+		if err != nil {
+			return fmt.Errorf("allstocks sum daily: %w", err)
+		}
+		if err := c.st.UpsertMarketAggDaily(tradeDate, "allstocks_sum", cfg.MarketAgg.FID, sum); err != nil {
+			return fmt.Errorf("store allstocks_sum daily: %w", err)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown daily dataset: %q", it.Dataset)
+}
@@ -0,0 +1,107 @@
+package collector
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/config"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/eastmoney"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/store"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/store/sqlite"
+)
+
+type staticConfig config.Config
+
+func (c staticConfig) Get() config.Config { return config.Config(c) }
+
+func TestRetryFailedDailyOnlyFailedItems(t *testing.T) {
+	st, err := store.Open(config.Config{DBPath: filepath.Join(t.TempDir(), "aof.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	if _, err := st.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	c := New(staticConfig{}, st, nil)
+	c.em = eastmoney.NewOfflineClient() // every fetch fails; only item selection is under test
+
+	date := time.Date(2024, 1, 2, 8, 0, 0, 0, time.UTC)
+	record := func(trigger string, items map[[2]string]string) {
+		t.Helper()
+		id, err := st.CreateDailyRun("2024-01-02", trigger, date, len(items))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for k, status := range items {
+			if err := st.UpsertDailyRunItem(id, k[0], k[1], status, "", date); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := st.FinishDailyRun(id, date); err != nil {
+			t.Fatal(err)
+		}
+	}
+	record("schedule", map[[2]string]string{
+		{DailyNorthbound, ""}:        sqlite.DailyItemOK,
+		{DailyMargin, "600519.SH"}:   sqlite.DailyItemFailed,
+		{DailyFundflow, "000001.SZ"}: sqlite.DailyItemFailed,
+		{DailyFundflow, "600519.SH"}: sqlite.DailyItemOK,
+	})
+	// A manual retry already fixed one of the failures.
+	record("manual", map[[2]string]string{{DailyFundflow, "000001.SZ"}: sqlite.DailyItemOK})
+
+	rep, err := c.RetryFailedDaily(context.Background(), date, "retry")
+	if err == nil {
+		t.Fatal("offline retry reported success")
+	}
+	if rep.Total != 1 || len(rep.Failed) != 1 || rep.Failed[0].DailyItem != (DailyItem{Dataset: DailyMargin, Key: "600519.SH"}) {
+		t.Fatalf("report=%+v", rep)
+	}
+	items, err := st.QueryDailyRunItems(rep.RunID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].Dataset != DailyMargin || items[0].Status != sqlite.DailyItemFailed {
+		t.Fatalf("retry run items=%+v", items)
+	}
+
+	// Once everything succeeded there is nothing to retry and no run is created.
+	record("manual", map[[2]string]string{{DailyMargin, "600519.SH"}: sqlite.DailyItemOK})
+	rep, err = c.RetryFailedDaily(context.Background(), date, "retry")
+	if err != nil || rep.RunID != 0 || rep.Total != 0 {
+		t.Fatalf("nothing-to-retry report=%+v err=%v", rep, err)
+	}
+}
+
+func TestConfigAddsWatchGroupMembers(t *testing.T) {
+	st, err := store.Open(config.Config{DBPath: filepath.Join(t.TempDir(), "aof.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	if _, err := st.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	c := New(staticConfig{Watchlist: []string{"600519.SH", "000001.SZ"}}, st, nil)
+	if got := c.config().Watchlist; !reflect.DeepEqual(got, []string{"600519.SH", "000001.SZ"}) {
+		t.Fatalf("without groups: %v", got)
+	}
+
+	for _, g := range []sqlite.WatchGroup{
+		{Name: "a", Symbols: []string{"000001.SH", "600519.SH"}},
+		{Name: "b", Symbols: []string{"000001.SZ", "300750.SZ"}},
+	} {
+		if _, err := st.CreateWatchGroup(g); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The configured watchlist comes first; group members are appended once each.
+	want := []string{"600519.SH", "000001.SZ", "000001.SH", "300750.SZ"}
+	if got := c.config().Watchlist; !reflect.DeepEqual(got, want) {
+		t.Fatalf("union = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,101 @@
+package collector
+
+import (
+	"time"
+
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/market"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/memstore"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/store/sqlite"
+)
+
+// flowSample is the cumulative "today net" state of one stock at a persist tick.
+type flowSample struct {
+	tsUTC     time.Time
+	tradeDate string
+	name      string
+	source    string
+	main      float64
+	xl        float64
+	l         float64
+	m         float64
+	s         float64
+}
+
+// flowDeltas diffs cur against prev per code and updates prev in place.
+// A code seen for the first time on a trade date (e.g. the first sample after the open resets
+// Eastmoney's counters) reports its raw value as the delta. Unchanged codes produce no row.
+// After a restart prev is empty, so the first sample only establishes the baseline for that day.
+func flowDeltas(prev, cur map[string]flowSample) []sqlite.FundflowDelta {
+	var out []sqlite.FundflowDelta
+	for code, c := range cur {
+		p, ok := prev[code]
+		prev[code] = c
+		if ok && p.tradeDate == c.tradeDate {
+			if p.main == c.main && p.xl == c.xl && p.l == c.l && p.m == c.m && p.s == c.s {
+				continue
+			}
+			out = append(out, sqlite.FundflowDelta{
+				Code:      code,
+				Name:      c.name,
+				Source:    c.source,
+				PrevTSUTC: p.tsUTC,
+				DtSec:     c.tsUTC.Sub(p.tsUTC).Seconds(),
+				DMain:     c.main - p.main,
+				DXL:       c.xl - p.xl,
+				DL:        c.l - p.l,
+				DM:        c.m - p.m,
+				DS:        c.s - p.s,
+				NetMain:   c.main,
+			})
+			continue
+		}
+		if ok {
+			// New trade date: counters restarted at the open.
+			out = append(out, sqlite.FundflowDelta{
+				Code:    code,
+				Name:    c.name,
+				Source:  c.source,
+				DMain:   c.main,
+				DXL:     c.xl,
+				DL:      c.l,
+				DM:      c.m,
+				DS:      c.s,
+				NetMain: c.main,
+			})
+		}
+	}
+	return out
+}
+
+// flowSamples collects the watchlist fundflow and, when the toplist is ranked by main net inflow (f62),
+// the toplist stocks not already in the watchlist (main only).
+func flowSamples(snap memstore.Snapshot, tsUTC time.Time, tradeDate string) map[string]flowSample {
+	out := make(map[string]flowSample, len(snap.Fundflow))
+	for _, r := range snap.Fundflow {
+		out[r.Code] = flowSample{
+			tsUTC: tsUTC, tradeDate: tradeDate, name: r.Name, source: "watchlist",
+			main: r.NetMain, xl: r.NetXL, l: r.NetL, m: r.NetM, s: r.NetS,
+		}
+	}
+	for _, it := range snap.ToplistByFID["f62"] {
+		if _, ok := out[it.Code]; ok || it.Code == "" {
+			continue
+		}
+		out[it.Code] = flowSample{
+			tsUTC: tsUTC, tradeDate: tradeDate, name: it.Name, source: "toplist", main: it.Value,
+		}
+	}
+	return out
+}
+
+// inFlowSession reports whether fundflow values at t belong to the current trade date:
+// from the open auction until shortly after the close. Outside it Eastmoney still serves the
+// previous day's totals, which must not be mistaken for a new day's first delta.
+func inFlowSession(t time.Time, loc *time.Location) bool {
+	t = t.In(loc)
+	if !market.IsCNTradingDay(t) {
+		return false
+	}
+	hm := t.Hour()*60 + t.Minute()
+	return hm >= 9*60+15 && hm < 15*60+5
+}
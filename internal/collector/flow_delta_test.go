@@ -0,0 +1,41 @@
+package collector
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFlowDeltas(t *testing.T) {
+	t0 := time.Date(2024, 1, 2, 6, 0, 0, 0, time.UTC)
+	prev := map[string]flowSample{}
+
+	// First sample only sets the baseline.
+	if got := flowDeltas(prev, map[string]flowSample{
+		"600519": {tsUTC: t0, tradeDate: "2024-01-02", main: 100, xl: 60},
+	}); len(got) != 0 {
+		t.Fatalf("baseline: got %d rows", len(got))
+	}
+
+	// Change within the same day.
+	got := flowDeltas(prev, map[string]flowSample{
+		"600519": {tsUTC: t0.Add(time.Minute), tradeDate: "2024-01-02", main: 130, xl: 50},
+	})
+	if len(got) != 1 || got[0].DMain != 30 || got[0].DXL != -10 || got[0].DtSec != 60 || got[0].NetMain != 130 {
+		t.Fatalf("same day: %+v", got)
+	}
+
+	// Unchanged values produce nothing.
+	if got := flowDeltas(prev, map[string]flowSample{
+		"600519": {tsUTC: t0.Add(2 * time.Minute), tradeDate: "2024-01-02", main: 130, xl: 50},
+	}); len(got) != 0 {
+		t.Fatalf("unchanged: %+v", got)
+	}
+
+	// Next trade date: the raw value is the delta since the open.
+	got = flowDeltas(prev, map[string]flowSample{
+		"600519": {tsUTC: t0.Add(24 * time.Hour), tradeDate: "2024-01-03", main: -20, xl: 5},
+	})
+	if len(got) != 1 || got[0].DMain != -20 || got[0].DXL != 5 || !got[0].PrevTSUTC.IsZero() {
+		t.Fatalf("reset: %+v", got)
+	}
+}
@@ -0,0 +1,31 @@
+package collector
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/config"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/store"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/store/sqlite"
+)
+
+// Housekeeping rolls raw realtime rows up into 1m/5m bars and then applies tiered retention,
+// archiving deleted rows first when archive is enabled.
+// Cleanup still runs if the rollup fails; it never deletes raw rows that weren't rolled up.
+func Housekeeping(st store.Store, cfg config.Config, nowUTC time.Time) error {
+	ret := sqlite.Retention{
+		DailyDays:  cfg.RetentionDays,
+		RawDays:    cfg.Retention.RawDays,
+		Bars1mDays: cfg.Retention.Bars1mDays,
+		Bars5mDays: cfg.Retention.Bars5mDays,
+	}
+	if cfg.Archive.Enabled != nil && *cfg.Archive.Enabled {
+		ret.ArchiveDir = cfg.Archive.Dir
+	}
+	rollupErr := st.RollupRT(nowUTC)
+	err := st.CleanupOldData(nowUTC, ret)
+	if rollupErr != nil {
+		return fmt.Errorf("%w (cleanup: %v)", rollupErr, err)
+	}
+	return err
+}
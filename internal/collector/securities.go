@@ -0,0 +1,77 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/eastmoney"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/pinyin"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/store/sqlite"
+)
+
+// RefreshSecurities reloads the security master from the configured Eastmoney lists and returns
+// the number of rows written. A list that fails (or comes back empty) keeps its previous rows.
+func (c *Collector) RefreshSecurities(ctx context.Context) (int, error) {
+	cfg := c.cfgp.Get()
+	lists := []struct{ kind, fs string }{
+		{"stock", cfg.Securities.StockFS},
+		{"index", cfg.Securities.IndexFS},
+		{"etf", cfg.Securities.ETFFS},
+		{"industry", cfg.Industry.FS},
+		{"concept", cfg.Concept.FS},
+	}
+	var (
+		rows  []sqlite.Security
+		kinds []string
+		errs  []error
+	)
+	for _, l := range lists {
+		items, err := c.em.SecurityList(ctx, l.fs)
+		if err == nil && len(items) == 0 {
+			err = fmt.Errorf("empty list")
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", l.kind, err))
+			continue
+		}
+		kinds = append(kinds, l.kind)
+		for _, it := range items {
+			rows = append(rows, securityRow(l.kind, it))
+		}
+	}
+	if len(kinds) > 0 {
+		if err := c.st.ReplaceSecurities(kinds, rows, time.Now().UTC()); err != nil {
+			return 0, err
+		}
+	}
+	return len(rows), errors.Join(errs...)
+}
+
+func securityRow(kind string, it eastmoney.SecurityItem) sqlite.Security {
+	return sqlite.Security{
+		SecID:  it.SecID(),
+		Code:   it.Code,
+		Name:   it.Name,
+		Market: marketOf(it.MarketID, it.Code),
+		Kind:   kind,
+		Pinyin: pinyin.Initials(it.Name),
+	}
+}
+
+// marketOf maps an Eastmoney market id to the exchange suffix; Beijing shares share id 0 with
+// Shenzhen and are told apart by their code (see symbol.ToEastmoneySecIDFromCode).
+func marketOf(marketID int, code string) string {
+	switch marketID {
+	case 1:
+		return "SH"
+	case 90:
+		return "BK"
+	}
+	if strings.HasPrefix(code, "92") || strings.HasPrefix(code, "8") || strings.HasPrefix(code, "4") {
+		return "BJ"
+	}
+	return "SZ"
+}
@@ -0,0 +1,27 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/eastmoney"
+)
+
+func TestSecurityRow(t *testing.T) {
+	for _, c := range []struct {
+		kind           string
+		it             eastmoney.SecurityItem
+		secid, sym     string
+		market, pinyin string
+	}{
+		{"stock", eastmoney.SecurityItem{Code: "600519", Name: "贵州茅台", MarketID: 1}, "1.600519", "600519.SH", "SH", "GZMT"},
+		{"stock", eastmoney.SecurityItem{Code: "000001", Name: "平安银行", MarketID: 0}, "0.000001", "000001.SZ", "SZ", "PAYH"},
+		{"stock", eastmoney.SecurityItem{Code: "920152", Name: "晨光电缆", MarketID: 0}, "0.920152", "920152.BJ", "BJ", "CGDL"},
+		{"index", eastmoney.SecurityItem{Code: "399300", Name: "沪深300", MarketID: 0}, "0.399300", "399300.SZ", "SZ", "HS300"},
+		{"industry", eastmoney.SecurityItem{Code: "BK0477", Name: "酿酒行业", MarketID: 90}, "90.BK0477", "", "BK", "NJHY"},
+	} {
+		r := securityRow(c.kind, c.it)
+		if r.SecID != c.secid || r.Symbol() != c.sym || r.Market != c.market || r.Pinyin != c.pinyin || r.Kind != c.kind {
+			t.Errorf("securityRow(%s, %+v) = %+v (symbol %q)", c.kind, c.it, r, r.Symbol())
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package eastmoney
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// AuctionItem is a call auction (集合竞价) quote.
+// During the auction Eastmoney reports the indicative (virtual matched) price in f2 and the
+// matched volume/amount in f5/f6 of ulist/clist. The unmatched quantity sits on the bid1/ask1
+// legs of the level-1 order book, which only qt/stock/get returns (see OrderBook1).
+type AuctionItem struct {
+	Rank   int
+	SecID  string
+	Code   string
+	Name   string
+	Price  float64 // indicative price
+	Pct    float64
+	Volume float64 // matched volume (lots, 手)
+	Amount float64 // matched amount (yuan)
+	BidVol float64 // bid1 volume at the indicative price (lots)
+	AskVol float64 // ask1 volume at the indicative price (lots)
+}
+
+// Unmatched returns the signed unmatched imbalance in lots: >0 buy side, <0 sell side.
+func (it AuctionItem) Unmatched() float64 {
+	return it.BidVol - it.AskVol
+}
+
+// UnmatchedAmt values the unmatched imbalance at the indicative price (1 lot = 100 shares).
+func (it AuctionItem) UnmatchedAmt() float64 {
+	return it.Unmatched() * it.Price * 100
+}
+
+// f12 code, f13 market, f14 name, f2 price, f3 pct, f5 volume, f6 amount.
+// ulist/clist have no order book volumes (their f20 is total market cap); see OrderBook1.
+const auctionFields = "f12,f13,f14,f2,f3,f5,f6"
+
+// auctionBookConcurrency caps the order book requests of one AuctionRealtime call.
+const auctionBookConcurrency = 4
+
+// AuctionRealtime fetches call auction quotes for a list of secids: ["1.600519","0.000001"].
+// Items whose order book could not be fetched are dropped (see fillAuctionBooks).
+func (c *Client) AuctionRealtime(ctx context.Context, secids []string) ([]AuctionItem, error) {
+	items, err := c.auctionQuotes(ctx, secids)
+	if err != nil {
+		return nil, err
+	}
+	return c.fillAuctionBooks(ctx, items, auctionBookConcurrency)
+}
+
+// auctionQuotes is AuctionRealtime without the order books.
+func (c *Client) auctionQuotes(ctx context.Context, secids []string) ([]AuctionItem, error) {
+	if len(secids) == 0 {
+		return nil, nil
+	}
+	u := "https://push2.eastmoney.com/api/qt/ulist.np/get"
+	q := url.Values{}
+	q.Set("fltt", "2")
+	q.Set("secids", joinComma(secids))
+	q.Set("fields", auctionFields)
+	u = u + "?" + q.Encode()
+
+	var raw struct {
+		RC   int `json:"rc"`
+		Data *struct {
+			Diff []json.RawMessage `json:"diff"`
+		} `json:"data"`
+	}
+	if err := c.getJSON(ctx, u, &raw); err != nil {
+		return nil, err
+	}
+	if raw.RC != 0 || raw.Data == nil {
+		return nil, fmt.Errorf("unexpected response rc=%d", raw.RC)
+	}
+	return decodeAuctionDiff(raw.Data.Diff, 1), nil
+}
+
+// AuctionTopList ranks the universe (fs) by fid during the call auction, e.g. fid=f6 (matched amount).
+// Order books are fetched with up to concurrency requests in flight; items whose book failed are
+// dropped and keep the rank they had in the upstream list.
+func (c *Client) AuctionTopList(ctx context.Context, fs, fid string, size, concurrency int) ([]AuctionItem, error) {
+	if size <= 0 {
+		size = 50
+	}
+	if size > 100 {
+		size = 100
+	}
+	u := "https://push2.eastmoney.com/api/qt/clist/get"
+	q := url.Values{}
+	q.Set("pn", "1")
+	q.Set("pz", strconv.Itoa(size))
+	q.Set("po", "1")
+	q.Set("np", "1")
+	q.Set("fltt", "2")
+	q.Set("invt", "2")
+	q.Set("_", strconv.FormatInt(time.Now().UnixMilli(), 10))
+	q.Set("fid", fid)
+	q.Set("fs", fs)
+	q.Set("fields", auctionFields)
+	u = u + "?" + q.Encode()
+
+	var raw struct {
+		RC   int `json:"rc"`
+		Data *struct {
+			Total int               `json:"total"`
+			Diff  []json.RawMessage `json:"diff"`
+		} `json:"data"`
+	}
+	if err := c.getJSON(ctx, u, &raw); err != nil {
+		return nil, err
+	}
+	if raw.RC != 0 || raw.Data == nil {
+		return nil, fmt.Errorf("unexpected response rc=%d", raw.RC)
+	}
+	items := decodeAuctionDiff(raw.Data.Diff, 1)
+	return c.fillAuctionBooks(ctx, items, concurrency)
+}
+
+// OrderBook1 is the level-1 order book of a stock. During the call auction both legs quote the
+// indicative price and their volumes are the unmatched quantity on each side.
+type OrderBook1 struct {
+	BidPrice float64
+	BidVol   float64 // lots
+	AskPrice float64
+	AskVol   float64 // lots
+}
+
+// qt/stock/get numbering: f19/f20 bid1 price/volume, f39/f40 ask1 price/volume.
+const orderBook1Fields = "f19,f20,f39,f40"
+
+// OrderBook1 fetches the level-1 order book of one secid ("1.600519").
+func (c *Client) OrderBook1(ctx context.Context, secid string) (OrderBook1, error) {
+	u := "https://push2.eastmoney.com/api/qt/stock/get"
+	q := url.Values{}
+	q.Set("fltt", "2")
+	q.Set("invt", "2")
+	q.Set("secid", secid)
+	q.Set("fields", orderBook1Fields)
+	u = u + "?" + q.Encode()
+
+	var raw struct {
+		RC   int            `json:"rc"`
+		Data map[string]any `json:"data"`
+	}
+	if err := c.getJSON(ctx, u, &raw); err != nil {
+		return OrderBook1{}, err
+	}
+	if raw.RC != 0 || raw.Data == nil {
+		return OrderBook1{}, fmt.Errorf("unexpected response rc=%d", raw.RC)
+	}
+	return decodeOrderBook1(raw.Data), nil
+}
+
+// fillAuctionBooks sets BidVol/AskVol from each item's order book, with at most concurrency
+// requests in flight. Without a book the unmatched imbalance is unknown, so items whose book could
+// not be fetched are left out of the result rather than stored as balanced; the error reports how
+// many were dropped.
+func (c *Client) fillAuctionBooks(ctx context.Context, items []AuctionItem, concurrency int) ([]AuctionItem, error) {
+	var (
+		mu     sync.Mutex
+		failed int
+		first  error
+		wg     sync.WaitGroup
+	)
+	ok := make([]bool, len(items))
+	sem := make(chan struct{}, clampConcurrency(concurrency))
+	for i := range items {
+		if items[i].SecID == "" {
+			continue
+		}
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			defer func() { <-sem }()
+			book, err := c.OrderBook1(ctx, items[i].SecID)
+			if err != nil {
+				mu.Lock()
+				failed++
+				if first == nil {
+					first = fmt.Errorf("%s: %w", items[i].SecID, err)
+				}
+				mu.Unlock()
+				return
+			}
+			items[i].BidVol, items[i].AskVol = book.BidVol, book.AskVol
+			ok[i] = true
+		}()
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	out := items[:0]
+	for i, it := range items {
+		if ok[i] {
+			out = append(out, it)
+		}
+	}
+	if failed > 0 {
+		return out, fmt.Errorf("order book %d/%d failed: %w", failed, len(items), first)
+	}
+	return out, nil
+}
+
+func decodeOrderBook1(m map[string]any) OrderBook1 {
+	return OrderBook1{
+		BidPrice: asFloat(m["f19"]),
+		BidVol:   asFloat(m["f20"]),
+		AskPrice: asFloat(m["f39"]),
+		AskVol:   asFloat(m["f40"]),
+	}
+}
+
+func decodeAuctionDiff(diff []json.RawMessage, firstRank int) []AuctionItem {
+	out := make([]AuctionItem, 0, len(diff))
+	for i, msg := range diff {
+		var m map[string]any
+		if err := json.Unmarshal(msg, &m); err != nil {
+			continue
+		}
+		code, _ := m["f12"].(string)
+		name, _ := m["f14"].(string)
+		var secid string
+		if _, ok := m["f13"]; ok && code != "" {
+			secid = strconv.Itoa(int(asFloat(m["f13"]))) + "." + code
+		}
+		out = append(out, AuctionItem{
+			Rank:   firstRank + i,
+			SecID:  secid,
+			Code:   code,
+			Name:   name,
+			Price:  asFloat(m["f2"]),
+			Pct:    asFloat(m["f3"]),
+			Volume: asFloat(m["f5"]),
+			Amount: asFloat(m["f6"]),
+		})
+	}
+	return out
+}
@@ -0,0 +1,143 @@
+package eastmoney
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// decodeFixture decodes like getJSON does (UseNumber).
+func decodeFixture(t *testing.T, name string, out any) {
+	t.Helper()
+	b, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(out); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDecodeAuctionDiff(t *testing.T) {
+	var raw struct {
+		Data struct {
+			Diff []json.RawMessage `json:"diff"`
+		} `json:"data"`
+	}
+	decodeFixture(t, "auction_ulist.json", &raw)
+	items := decodeAuctionDiff(raw.Data.Diff, 1)
+	if len(items) != 2 {
+		t.Fatalf("items=%d", len(items))
+	}
+	it := items[0]
+	if it.SecID != "1.600519" || it.Code != "600519" || it.Name != "贵州茅台" || it.Rank != 1 {
+		t.Fatalf("item=%+v", it)
+	}
+	if it.Price != 1688 || it.Volume != 2315 || it.Amount != 390870000 {
+		t.Fatalf("quote=%+v", it)
+	}
+	// f20 of ulist/clist is total market cap, never an order book volume.
+	if it.BidVol != 0 || it.AskVol != 0 {
+		t.Fatalf("book volumes from ulist: %+v", it)
+	}
+	if items[1].SecID != "0.000001" || items[1].Price != 0 {
+		t.Fatalf("suspended item=%+v", items[1])
+	}
+}
+
+func TestDecodeOrderBook1(t *testing.T) {
+	var raw struct {
+		Data map[string]any `json:"data"`
+	}
+	decodeFixture(t, "auction_stock_get.json", &raw)
+	book := decodeOrderBook1(raw.Data)
+	if book.BidPrice != 1688 || book.BidVol != 412 || book.AskPrice != 1688 || book.AskVol != 0 {
+		t.Fatalf("book=%+v", book)
+	}
+
+	it := AuctionItem{Price: 1688, BidVol: book.BidVol, AskVol: book.AskVol}
+	if it.Unmatched() != 412 || it.UnmatchedAmt() != 412*1688*100 {
+		t.Fatalf("unmatched=%v amt=%v", it.Unmatched(), it.UnmatchedAmt())
+	}
+}
+
+// fixtureTransport answers ulist/clist with auction_ulist.json and stock/get with
+// auction_stock_get.json, except for the secids in fail, which get rc=102. It records the peak
+// number of order book requests in flight.
+type fixtureTransport struct {
+	t    *testing.T
+	fail map[string]bool
+
+	mu             sync.Mutex
+	inFlight, peak int
+}
+
+func (ft *fixtureTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	body := `{"rc":102,"data":null}`
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/stock/get"):
+		ft.mu.Lock()
+		ft.inFlight++
+		ft.peak = max(ft.peak, ft.inFlight)
+		ft.mu.Unlock()
+		defer func() {
+			ft.mu.Lock()
+			ft.inFlight--
+			ft.mu.Unlock()
+		}()
+		if !ft.fail[r.URL.Query().Get("secid")] {
+			body = ft.read("auction_stock_get.json")
+		}
+	case strings.HasSuffix(r.URL.Path, "/ulist.np/get"), strings.HasSuffix(r.URL.Path, "/clist/get"):
+		body = ft.read("auction_ulist.json")
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    r,
+	}, nil
+}
+
+func (ft *fixtureTransport) read(name string) string {
+	b, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		ft.t.Error(err)
+	}
+	return string(b)
+}
+
+func TestAuctionDropsFailedBooks(t *testing.T) {
+	ft := &fixtureTransport{t: t, fail: map[string]bool{"0.000001": true}}
+	c := &Client{hc: &http.Client{Transport: ft}}
+	ctx := context.Background()
+
+	rows, err := c.AuctionRealtimeChunked(ctx, []string{"1.600519", "0.000001"}, 100, 1)
+	if err == nil || !strings.Contains(err.Error(), "order book 1/2 failed: 0.000001") {
+		t.Fatalf("err=%v", err)
+	}
+	if len(rows) != 1 || rows[0].SecID != "1.600519" || rows[0].Rank != 1 || rows[0].BidVol != 412 {
+		t.Fatalf("rows=%+v", rows)
+	}
+	if ft.peak != 1 {
+		t.Fatalf("%d order book requests in flight, concurrency is 1", ft.peak)
+	}
+
+	rows, err = c.AuctionTopList(ctx, "m:1+t:2", "f6", 2, 2)
+	if err == nil || len(rows) != 1 || rows[0].SecID != "1.600519" || rows[0].Rank != 1 {
+		t.Fatalf("toplist rows=%+v err=%v", rows, err)
+	}
+
+	ft.fail = nil
+	if rows, err := c.AuctionRealtime(ctx, []string{"1.600519", "0.000001"}); err != nil || len(rows) != 2 {
+		t.Fatalf("rows=%+v err=%v", rows, err)
+	}
+}
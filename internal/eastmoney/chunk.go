@@ -0,0 +1,99 @@
+package eastmoney
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// fetchChunked splits secids into chunks of size and calls fetch for each chunk with at most
+// concurrency requests in flight. Rows from successful chunks are returned in chunk order even
+// when other chunks fail; the returned error joins the failures.
+func fetchChunked[T any](ctx context.Context, secids []string, size, concurrency int,
+	fetch func(context.Context, []string) ([]T, error)) ([]T, error) {
+	if len(secids) == 0 {
+		return nil, nil
+	}
+	if size <= 0 {
+		size = 100
+	}
+	concurrency = clampConcurrency(concurrency)
+
+	var chunks [][]string
+	for i := 0; i < len(secids); i += size {
+		end := i + size
+		if end > len(secids) {
+			end = len(secids)
+		}
+		chunks = append(chunks, secids[i:end])
+	}
+	if len(chunks) == 1 {
+		return fetch(ctx, chunks[0])
+	}
+
+	results := make([][]T, len(chunks))
+	errs := make([]error, len(chunks))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, chunk := range chunks {
+		i, chunk := i, chunk
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				return
+			}
+			defer func() { <-sem }()
+
+			rows, err := fetch(ctx, chunk)
+			// A fetch may return usable rows together with an error.
+			results[i] = rows
+			if err != nil {
+				errs[i] = fmt.Errorf("chunk %d/%d (%s..): %w", i+1, len(chunks), chunk[0], err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var out []T
+	for _, rows := range results {
+		out = append(out, rows...)
+	}
+	return out, errors.Join(errs...)
+}
+
+// clampConcurrency limits the requests one call keeps in flight to 1..10.
+func clampConcurrency(n int) int {
+	if n < 1 {
+		return 1
+	}
+	if n > 10 {
+		return 10
+	}
+	return n
+}
+
+// FundflowRealtimeChunked is FundflowRealtime for large lists: secids are split into chunks of
+// chunkSize fetched concurrently. It returns partial rows together with an error if some chunks fail.
+func (c *Client) FundflowRealtimeChunked(ctx context.Context, secids []string, chunkSize, concurrency int) ([]FundflowRT, error) {
+	return fetchChunked(ctx, secids, chunkSize, concurrency, c.FundflowRealtime)
+}
+
+// AuctionRealtimeChunked is the chunked variant of AuctionRealtime; ranks are renumbered across chunks.
+// The per-stock order book requests share the same concurrency limit as the quote chunks.
+func (c *Client) AuctionRealtimeChunked(ctx context.Context, secids []string, chunkSize, concurrency int) ([]AuctionItem, error) {
+	rows, err := fetchChunked(ctx, secids, chunkSize, concurrency, c.auctionQuotes)
+	if len(rows) > 0 {
+		var bookErr error
+		rows, bookErr = c.fillAuctionBooks(ctx, rows, concurrency)
+		err = errors.Join(err, bookErr)
+	}
+	for i := range rows {
+		rows[i].Rank = i + 1
+	}
+	return rows, err
+}
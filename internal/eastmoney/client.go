@@ -16,7 +16,18 @@ import (
 )
 
 type Client struct {
-	hc *http.Client
+	hc      *http.Client
+	offline bool
+}
+
+// ErrOffline is returned by every request of a client from NewOfflineClient.
+var ErrOffline = errors.New("eastmoney: live fetch disabled (read-only mode)")
+
+// NewOfflineClient returns a client that never contacts Eastmoney; every call fails with ErrOffline.
+func NewOfflineClient() *Client {
+	c := NewClient()
+	c.offline = true
+	return c
 }
 
 func NewClient() *Client {
@@ -93,8 +104,8 @@ func (c *Client) FundflowRealtime(ctx context.Context, secids []string) ([]Fundf
 	q.Set("fltt", "2")
 	q.Set("secids", joinComma(secids))
 	// Fields:
-	// f12: code, f14: name, f62: main net, f66: xl, f72: l, f78: m, f84: s
-	q.Set("fields", "f12,f14,f62,f66,f72,f78,f84")
+	// f12: code, f13: market, f14: name, f62: main net, f66: xl, f72: l, f78: m, f84: s, f124: update time
+	q.Set("fields", "f12,f13,f14,f62,f66,f72,f78,f84,f124")
 	u = u + "?" + q.Encode()
 
 	var resp ulistResp
@@ -107,15 +118,20 @@ func (c *Client) FundflowRealtime(ctx context.Context, secids []string) ([]Fundf
 
 	out := make([]FundflowRT, 0, len(resp.Data.Diff))
 	for _, d := range resp.Data.Diff {
+		var secid string
+		if d.F13 != nil && d.F12 != "" {
+			secid = strconv.Itoa(int(asFloat(d.F13))) + "." + d.F12
+		}
 		out = append(out, FundflowRT{
-			Code:     d.F12,
-			Name:     d.F14,
-			NetMain:  d.F62,
-			NetXL:    d.F66,
-			NetL:     d.F72,
-			NetM:     d.F78,
-			NetS:     d.F84,
-			RawSecID: "", // not returned
+			Code:       d.F12,
+			Name:       d.F14,
+			NetMain:    d.F62,
+			NetXL:      d.F66,
+			NetL:       d.F72,
+			NetM:       d.F78,
+			NetS:       d.F84,
+			RawSecID:   secid,
+			UpdateTime: int64(asFloat(d.F124)),
 		})
 	}
 	return out, nil
@@ -124,11 +140,23 @@ func (c *Client) FundflowRealtime(ctx context.Context, secids []string) ([]Fundf
 // FundflowDailyLatest returns the latest available daily record for secid.
 // This is used for T+0 after close; during trading it may represent a partial day.
 func (c *Client) FundflowDailyLatest(ctx context.Context, secid string) (FundflowDaily, error) {
+	rows, err := c.FundflowDailySeries(ctx, secid, 1)
+	if err != nil {
+		return FundflowDaily{}, err
+	}
+	return rows[len(rows)-1], nil
+}
+
+// FundflowDailySeries returns up to limit daily records for secid, oldest first.
+func (c *Client) FundflowDailySeries(ctx context.Context, secid string, limit int) ([]FundflowDaily, error) {
+	if limit <= 0 {
+		limit = 1
+	}
 	u := "https://push2.eastmoney.com/api/qt/stock/fflow/kline/get"
 	q := url.Values{}
 	q.Set("secid", secid)
 	q.Set("klt", "101") // daily
-	q.Set("lmt", "1")
+	q.Set("lmt", strconv.Itoa(limit))
 	q.Set("fields1", "f1,f2,f3,f7")
 	// fields2 output comes as a compact CSV in data.klines, so field list isn't strictly required,
 	// but keeping it avoids surprises if the API changes default fields.
@@ -137,39 +165,55 @@ func (c *Client) FundflowDailyLatest(ctx context.Context, secid string) (Fundflo
 
 	var resp fflowKlineResp
 	if err := c.getJSON(ctx, u, &resp); err != nil {
-		return FundflowDaily{}, err
+		return nil, err
 	}
 	if resp.RC != 0 || resp.Data == nil || len(resp.Data.Klines) == 0 {
-		return FundflowDaily{}, fmt.Errorf("unexpected response rc=%d", resp.RC)
+		return nil, fmt.Errorf("unexpected response rc=%d", resp.RC)
 	}
 
-	// Format: "YYYY-MM-DD,main,small,medium,large,xl"
-	parts := splitComma(resp.Data.Klines[0])
-	if len(parts) < 6 {
-		return FundflowDaily{}, fmt.Errorf("unexpected kline format: %q", resp.Data.Klines[0])
-	}
-	main, _ := strconv.ParseFloat(parts[1], 64)
-	small, _ := strconv.ParseFloat(parts[2], 64)
-	medium, _ := strconv.ParseFloat(parts[3], 64)
-	large, _ := strconv.ParseFloat(parts[4], 64)
-	xl, _ := strconv.ParseFloat(parts[5], 64)
+	out := make([]FundflowDaily, 0, len(resp.Data.Klines))
+	for _, line := range resp.Data.Klines {
+		// Format: "YYYY-MM-DD,main,small,medium,large,xl"
+		parts := splitComma(line)
+		if len(parts) < 6 {
+			return nil, fmt.Errorf("unexpected kline format: %q", line)
+		}
+		main, _ := strconv.ParseFloat(parts[1], 64)
+		small, _ := strconv.ParseFloat(parts[2], 64)
+		medium, _ := strconv.ParseFloat(parts[3], 64)
+		large, _ := strconv.ParseFloat(parts[4], 64)
+		xl, _ := strconv.ParseFloat(parts[5], 64)
 
-	return FundflowDaily{
-		TradeDate: parts[0],
-		SecID:     secid,
-		Code:      resp.Data.Code,
-		Name:      resp.Data.Name,
-		NetMain:   main,
-		NetS:      small,
-		NetM:      medium,
-		NetL:      large,
-		NetXL:     xl,
-	}, nil
+		out = append(out, FundflowDaily{
+			TradeDate: parts[0],
+			SecID:     secid,
+			Code:      resp.Data.Code,
+			Name:      resp.Data.Name,
+			NetMain:   main,
+			NetS:      small,
+			NetM:      medium,
+			NetL:      large,
+			NetXL:     xl,
+		})
+	}
+	return out, nil
 }
 
 // MarginLatestByCode pulls latest per-stock margin record (融资融券) from Eastmoney datacenter.
-// NOTE: The datacenter filter grammar is fragile; for stability we filter by code only and take latest record.
 func (c *Client) MarginLatestByCode(ctx context.Context, code string) (MarginDaily, error) {
+	rows, err := c.MarginSeriesByCode(ctx, code, 1)
+	if err != nil {
+		return MarginDaily{}, err
+	}
+	return rows[len(rows)-1], nil
+}
+
+// MarginSeriesByCode returns up to limit daily margin records for code, oldest first.
+// NOTE: The datacenter filter grammar is fragile; for stability we filter by code only and sort by date.
+func (c *Client) MarginSeriesByCode(ctx context.Context, code string, limit int) ([]MarginDaily, error) {
+	if limit <= 0 {
+		limit = 1
+	}
 	u := "https://datacenter-web.eastmoney.com/api/data/v1/get"
 	q := url.Values{}
 	q.Set("reportName", "RPTA_WEB_RZRQ_GGMX")
@@ -177,37 +221,44 @@ func (c *Client) MarginLatestByCode(ctx context.Context, code string) (MarginDai
 	// SCODE seems to require double-quote string OR numeric; double-quote keeps leading zeros safe.
 	q.Set("filter", fmt.Sprintf(`(SCODE="%s")`, code))
 	q.Set("pageNumber", "1")
-	q.Set("pageSize", "1")
+	q.Set("pageSize", strconv.Itoa(limit))
 	q.Set("sortColumns", "DATE")
 	q.Set("sortTypes", "-1")
 	u = u + "?" + q.Encode()
 
 	var resp datacenterResp[marginRow]
 	if err := c.getJSON(ctx, u, &resp); err != nil {
-		return MarginDaily{}, err
+		return nil, err
 	}
 	if !resp.Success || resp.Result == nil || len(resp.Result.Data) == 0 {
-		return MarginDaily{}, fmt.Errorf("no margin data for code=%s", code)
+		return nil, fmt.Errorf("no margin data for code=%s", code)
 	}
-	r := resp.Result.Data[0]
-	return MarginDaily{
-		TradeDate: formatDatacenterDate(r.DATE),
-		Code:      r.SCODE,
-		Name:      r.SECNAME,
-		Market:    r.TRADE_MARKET,
-		RZYE:      r.RZYE,
-		RZMRE:     r.RZMRE,
-		RZCHE:     r.RZCHE,
-		RZJME:     r.RZJME,
-		RQYE:      r.RQYE,
-		RQMCL:     r.RQMCL,
-		RQCHL:     r.RQCHL,
-		RQJMG:     r.RQJMG,
-		RZRQYE:    r.RZRQYE,
-	}, nil
+	out := make([]MarginDaily, len(resp.Result.Data))
+	for i, r := range resp.Result.Data {
+		// Newest first from the API.
+		out[len(out)-1-i] = MarginDaily{
+			TradeDate: formatDatacenterDate(r.DATE),
+			Code:      r.SCODE,
+			Name:      r.SECNAME,
+			Market:    r.TRADE_MARKET,
+			RZYE:      r.RZYE,
+			RZMRE:     r.RZMRE,
+			RZCHE:     r.RZCHE,
+			RZJME:     r.RZJME,
+			RQYE:      r.RQYE,
+			RQMCL:     r.RQMCL,
+			RQCHL:     r.RQCHL,
+			RQJMG:     r.RQJMG,
+			RZRQYE:    r.RZRQYE,
+		}
+	}
+	return out, nil
 }
 
 func (c *Client) getJSON(ctx context.Context, u string, out any) error {
+	if c.offline {
+		return ErrOffline
+	}
 	var lastErr error
 	backoff := 200 * time.Millisecond
 
@@ -0,0 +1,88 @@
+package eastmoney
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// SecurityItem is one entry of a clist universe: code, name and Eastmoney market id (f13:
+// 1 = Shanghai, 0 = Shenzhen/Beijing, 90 = boards).
+type SecurityItem struct {
+	Code     string
+	Name     string
+	MarketID int
+}
+
+// SecID is the Eastmoney secid of the item, e.g. 1.600519.
+func (s SecurityItem) SecID() string {
+	return strconv.Itoa(s.MarketID) + "." + s.Code
+}
+
+// SecurityList returns every security of a clist filter (fs), ordered by code. Pages are fetched
+// one after another; the whole A-share list is about 60 requests.
+func (c *Client) SecurityList(ctx context.Context, fs string) ([]SecurityItem, error) {
+	const pageSize = 100 // seems capped by API
+	var out []SecurityItem
+	for pn := 1; ; pn++ {
+		total, items, err := c.securityPage(ctx, fs, pn, pageSize)
+		if err != nil {
+			return nil, fmt.Errorf("%s page %d: %w", fs, pn, err)
+		}
+		out = append(out, items...)
+		if len(items) == 0 || len(out) >= total {
+			return out, nil
+		}
+	}
+}
+
+func (c *Client) securityPage(ctx context.Context, fs string, pn, pz int) (total int, items []SecurityItem, err error) {
+	u := "https://push2.eastmoney.com/api/qt/clist/get"
+	q := url.Values{}
+	q.Set("pn", strconv.Itoa(pn))
+	q.Set("pz", strconv.Itoa(pz))
+	q.Set("po", "0")
+	q.Set("np", "1")
+	q.Set("fltt", "2")
+	q.Set("invt", "2")
+	q.Set("_", strconv.FormatInt(time.Now().UnixMilli(), 10))
+	q.Set("fid", "f12")
+	q.Set("fs", fs)
+	q.Set("fields", "f12,f13,f14")
+	u = u + "?" + q.Encode()
+
+	var raw struct {
+		RC   int `json:"rc"`
+		Data *struct {
+			Total int               `json:"total"`
+			Diff  []json.RawMessage `json:"diff"`
+		} `json:"data"`
+	}
+	if err := c.getJSON(ctx, u, &raw); err != nil {
+		return 0, nil, err
+	}
+	if raw.RC != 0 {
+		return 0, nil, fmt.Errorf("unexpected response rc=%d", raw.RC)
+	}
+	if raw.Data == nil {
+		return 0, nil, nil
+	}
+
+	out := make([]SecurityItem, 0, len(raw.Data.Diff))
+	for _, msg := range raw.Data.Diff {
+		var m map[string]any
+		if err := json.Unmarshal(msg, &m); err != nil {
+			continue
+		}
+		code, _ := m["f12"].(string)
+		name, _ := m["f14"].(string)
+		if code == "" || name == "" {
+			continue
+		}
+		out = append(out, SecurityItem{Code: code, Name: name, MarketID: int(asFloat(m["f13"]))})
+	}
+	return raw.Data.Total, out, nil
+}
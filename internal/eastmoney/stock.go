@@ -0,0 +1,63 @@
+package eastmoney
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// StockBoards returns the boards (industry, concept and region) that list secid, as TopItems with the
+// board's price and pct; Value is unused. Rank follows the upstream order (pct descending).
+func (c *Client) StockBoards(ctx context.Context, secid string) ([]TopItem, error) {
+	if secid == "" {
+		return nil, fmt.Errorf("secid is required")
+	}
+	u := "https://push2.eastmoney.com/api/qt/slist/get"
+	q := url.Values{}
+	q.Set("spt", "3") // boards containing the security
+	q.Set("secid", secid)
+	q.Set("pn", "1")
+	q.Set("pz", "200")
+	q.Set("po", "1")
+	q.Set("np", "1")
+	q.Set("fltt", "2")
+	q.Set("invt", "2")
+	q.Set("fid", "f3")
+	q.Set("_", strconv.FormatInt(time.Now().UnixMilli(), 10))
+	q.Set("fields", "f12,f14,f2,f3")
+	u = u + "?" + q.Encode()
+
+	var raw struct {
+		RC   int `json:"rc"`
+		Data *struct {
+			Diff []json.RawMessage `json:"diff"`
+		} `json:"data"`
+	}
+	if err := c.getJSON(ctx, u, &raw); err != nil {
+		return nil, err
+	}
+	if raw.RC != 0 {
+		return nil, fmt.Errorf("unexpected response rc=%d", raw.RC)
+	}
+	if raw.Data == nil {
+		return nil, nil
+	}
+
+	out := make([]TopItem, 0, len(raw.Data.Diff))
+	for _, msg := range raw.Data.Diff {
+		var m map[string]any
+		if err := json.Unmarshal(msg, &m); err != nil {
+			continue
+		}
+		code, _ := m["f12"].(string)
+		if code == "" {
+			continue
+		}
+		name, _ := m["f14"].(string)
+		out = append(out, TopItem{Rank: len(out) + 1, Code: code, Name: name, Price: asFloat(m["f2"]), Pct: asFloat(m["f3"])})
+	}
+	return out, nil
+}
@@ -30,7 +30,11 @@ type FundflowRT struct {
 	NetL     float64
 	NetM     float64
 	NetS     float64
+	// RawSecID is the Eastmoney secid ("1.600519"); codes alone collide across markets
+	// (000001 is both the SSE index and Ping An Bank). Empty for rows loaded from SQLite.
 	RawSecID string
+	// UpdateTime is the quote's last update (unix seconds, f124).
+	UpdateTime int64
 }
 
 type FundflowDaily struct {
@@ -96,13 +100,15 @@ type ulistResp struct {
 	RC   int `json:"rc"`
 	Data *struct {
 		Diff []struct {
-			F12 string  `json:"f12"`
-			F14 string  `json:"f14"`
-			F62 float64 `json:"f62"`
-			F66 float64 `json:"f66"`
-			F72 float64 `json:"f72"`
-			F78 float64 `json:"f78"`
-			F84 float64 `json:"f84"`
+			F12  string  `json:"f12"`
+			F13  any     `json:"f13"`
+			F14  string  `json:"f14"`
+			F62  float64 `json:"f62"`
+			F66  float64 `json:"f66"`
+			F72  float64 `json:"f72"`
+			F78  float64 `json:"f78"`
+			F84  float64 `json:"f84"`
+			F124 any     `json:"f124"` // unix seconds, "-" when missing
 		} `json:"diff"`
 	} `json:"data"`
 }
@@ -121,6 +127,7 @@ type clistResp struct {
 	Data *struct {
 		Diff []struct {
 			F12  string  `json:"f12"`
+			F13  any     `json:"f13"`
 			F14  string  `json:"f14"`
 			F2   float64 `json:"f2"`
 			F3   float64 `json:"f3"`
@@ -0,0 +1,230 @@
+// Package export writes history tables (sqlite.ExportTable) as CSV or Excel (.xlsx) files for
+// `aof export` and /api/v1/export.
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/store/sqlite"
+)
+
+// ContentType returns the MIME type of format.
+func ContentType(format string) string {
+	if format == "xlsx" {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// Write writes t in format ("csv" or "xlsx"). With zh the header row uses Chinese column names.
+// sheet names the xlsx worksheet.
+func Write(w io.Writer, format string, t sqlite.ExportTable, zh bool, sheet string) error {
+	switch format {
+	case "csv":
+		return WriteCSV(w, t, zh)
+	case "xlsx":
+		return WriteXLSX(w, t, zh, sheet)
+	default:
+		return fmt.Errorf("unknown format %q (want csv or xlsx)", format)
+	}
+}
+
+var zhHeaders = map[string]string{
+	"trade_date": "交易日期",
+	"time":       "时间",
+	"source":     "来源",
+	"fid":        "指标",
+	"board_type": "板块类型",
+	"code":       "代码",
+	"name":       "名称",
+	"price":      "价格",
+	"pct":        "涨跌幅(%)",
+	"value":      "数值",
+	"net_main":   "主力净流入",
+	"net_xl":     "超大单净流入",
+	"net_l":      "大单净流入",
+	"net_m":      "中单净流入",
+	"net_s":      "小单净流入",
+
+	"sh_day_net_amt_in": "沪股通当日资金净流入",
+	"sh_net_buy_amt":    "沪股通净买额",
+	"sh_buy_amt":        "沪股通买入额",
+	"sh_sell_amt":       "沪股通卖出额",
+	"sh_buy_sell_amt":   "沪股通成交额",
+	"sz_day_net_amt_in": "深股通当日资金净流入",
+	"sz_net_buy_amt":    "深股通净买额",
+	"sz_buy_amt":        "深股通买入额",
+	"sz_sell_amt":       "深股通卖出额",
+	"sz_buy_sell_amt":   "深股通成交额",
+}
+
+// Header returns the header cell for a column.
+func Header(col string, zh bool) string {
+	if h, ok := zhHeaders[col]; ok && zh {
+		return h
+	}
+	return col
+}
+
+func headers(t sqlite.ExportTable, zh bool) []string {
+	out := make([]string, len(t.Columns))
+	for i, c := range t.Columns {
+		out[i] = Header(c, zh)
+	}
+	return out
+}
+
+// formatNumber renders a float without exponent or trailing zeros; NaN and Inf are left empty.
+func formatNumber(v float64) string {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return ""
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// WriteCSV writes t as UTF-8 CSV with a byte order mark, so Excel shows Chinese text correctly.
+func WriteCSV(w io.Writer, t sqlite.ExportTable, zh bool) error {
+	bw := bufio.NewWriter(w)
+	if _, err := bw.WriteString("\ufeff"); err != nil {
+		return err
+	}
+	cw := csv.NewWriter(bw)
+	if err := cw.Write(headers(t, zh)); err != nil {
+		return err
+	}
+	rec := make([]string, len(t.Columns))
+	for _, row := range t.Rows {
+		for i, cell := range row {
+			switch v := cell.(type) {
+			case string:
+				rec[i] = v
+			case float64:
+				rec[i] = formatNumber(v)
+			default:
+				rec[i] = ""
+			}
+		}
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// WriteXLSX writes t as a single-sheet Office Open XML workbook with a frozen header row.
+// Strings are inline (no shared string table); numbers are numeric cells.
+func WriteXLSX(w io.Writer, t sqlite.ExportTable, zh bool, sheet string) error {
+	if sheet == "" {
+		sheet = "Sheet1"
+	}
+	zw := zip.NewWriter(w)
+	files := []struct{ name, body string }{
+		{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+			`</Types>`},
+		{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+			`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="` + escape(sheetName(sheet)) + `" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+			`</Relationships>`},
+	}
+	for _, f := range files {
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(fw, f.body); err != nil {
+			return err
+		}
+	}
+	fw, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	if err := writeSheet(fw, t, zh); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func writeSheet(w io.Writer, t sqlite.ExportTable, zh bool) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>` +
+		`<sheetData>`)
+	writeRow := func(n int, cells []any) {
+		fmt.Fprintf(bw, `<row r="%d">`, n)
+		for i, cell := range cells {
+			ref := columnName(i) + strconv.Itoa(n)
+			switch v := cell.(type) {
+			case string:
+				if v != "" {
+					fmt.Fprintf(bw, `<c r="%s" t="inlineStr"><is><t>%s</t></is></c>`, ref, escape(v))
+				}
+			case float64:
+				if s := formatNumber(v); s != "" {
+					fmt.Fprintf(bw, `<c r="%s"><v>%s</v></c>`, ref, s)
+				}
+			}
+		}
+		bw.WriteString(`</row>`)
+	}
+	head := make([]any, len(t.Columns))
+	for i, h := range headers(t, zh) {
+		head[i] = h
+	}
+	writeRow(1, head)
+	for i, row := range t.Rows {
+		writeRow(i+2, row)
+	}
+	bw.WriteString(`</sheetData></worksheet>`)
+	return bw.Flush()
+}
+
+// columnName is the spreadsheet column letter of a 0-based index: A..Z, AA, AB, ...
+func columnName(i int) string {
+	var b []byte
+	for i++; i > 0; i = (i - 1) / 26 {
+		b = append([]byte{byte('A' + (i-1)%26)}, b...)
+	}
+	return string(b)
+}
+
+// sheetName drops the characters Excel forbids in sheet names and keeps its 31 character limit.
+func sheetName(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, s)
+	if r := []rune(s); len(r) > 31 {
+		s = string(r[:31])
+	}
+	return s
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
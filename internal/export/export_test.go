@@ -0,0 +1,90 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/store/sqlite"
+)
+
+var table = sqlite.ExportTable{
+	Columns: []string{"trade_date", "code", "name", "net_main"},
+	Rows: [][]any{
+		{"2024-01-02", "600519", "贵州茅台", 1.5e8},
+		{"2024-01-02", "000001", "平安银行, \"A\"", nil},
+	},
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, table, true); err != nil {
+		t.Fatal(err)
+	}
+	want := "\ufeff交易日期,代码,名称,主力净流入\n" +
+		"2024-01-02,600519,贵州茅台,150000000\n" +
+		"2024-01-02,000001,\"平安银行, \"\"A\"\"\",\n"
+	if buf.String() != want {
+		t.Fatalf("csv =\n%q\nwant\n%q", buf.String(), want)
+	}
+}
+
+func TestWriteXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteXLSX(&buf, table, false, "fundflow"); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := io.ReadAll(rc)
+		rc.Close()
+		parts[f.Name] = string(b)
+		// Every part must be well-formed XML.
+		d := xml.NewDecoder(bytes.NewReader(b))
+		for {
+			if _, err := d.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("%s: %v", f.Name, err)
+			}
+		}
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml"} {
+		if _, ok := parts[name]; !ok {
+			t.Fatalf("missing part %s", name)
+		}
+	}
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	for _, want := range []string{
+		`<c r="D1" t="inlineStr"><is><t>net_main</t></is></c>`,
+		`<c r="C2" t="inlineStr"><is><t>贵州茅台</t></is></c>`,
+		`<c r="D2"><v>150000000</v></c>`,
+		`<t>平安银行, &#34;A&#34;</t>`,
+	} {
+		if !strings.Contains(sheet, want) {
+			t.Fatalf("sheet lacks %s:\n%s", want, sheet)
+		}
+	}
+	if strings.Contains(sheet, `r="D3"`) {
+		t.Fatal("NULL value written as a cell")
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	for i, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"} {
+		if got := columnName(i); got != want {
+			t.Fatalf("columnName(%d) = %s, want %s", i, got, want)
+		}
+	}
+}
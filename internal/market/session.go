@@ -2,6 +2,22 @@ package market
 
 import "time"
 
+// Call auction phases (集合竞价) in Asia/Shanghai.
+const (
+	PhaseOpenAuction  = "open"  // 09:15-09:25
+	PhaseCloseAuction = "close" // 14:57-15:00
+)
+
+// IsCNTradingDay gates by weekday only; CN holidays are not handled.
+func IsCNTradingDay(t time.Time) bool {
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	if err == nil {
+		t = t.In(loc)
+	}
+	wd := t.Weekday()
+	return wd != time.Saturday && wd != time.Sunday
+}
+
 // IsCNTradingTime checks A-share continuous auction sessions in Asia/Shanghai.
 // This ignores holidays; for a free MVP we at least gate by weekday and session hours.
 func IsCNTradingTime(t time.Time) bool {
@@ -10,8 +26,7 @@ func IsCNTradingTime(t time.Time) bool {
 		t = t.In(loc)
 	}
 
-	wd := t.Weekday()
-	if wd == time.Saturday || wd == time.Sunday {
+	if !IsCNTradingDay(t) {
 		return false
 	}
 
@@ -26,3 +41,26 @@ func IsCNTradingTime(t time.Time) bool {
 	return false
 }
 
+// CNAuctionPhase returns PhaseOpenAuction or PhaseCloseAuction when t falls in a call auction window,
+// otherwise "". The close window overlaps the tail of the afternoon session on purpose.
+func CNAuctionPhase(t time.Time) string {
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	if err == nil {
+		t = t.In(loc)
+	}
+
+	if !IsCNTradingDay(t) {
+		return ""
+	}
+
+	hm := t.Hour()*60 + t.Minute()
+	// 09:15-09:25 (matching happens at 09:25; keep the last minute to capture the final result)
+	if hm >= 9*60+15 && hm <= 9*60+25 {
+		return PhaseOpenAuction
+	}
+	// 14:57-15:00
+	if hm >= 14*60+57 && hm <= 15*60 {
+		return PhaseCloseAuction
+	}
+	return ""
+}
@@ -24,3 +24,29 @@ func TestIsCNTradingTime(t *testing.T) {
 	}
 }
 
+func TestCNAuctionPhase(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Shanghai")
+	cases := []struct {
+		h, m int
+		want string
+	}{
+		{9, 14, ""},
+		{9, 15, PhaseOpenAuction},
+		{9, 25, PhaseOpenAuction},
+		{9, 26, ""},
+		{14, 56, ""},
+		{14, 57, PhaseCloseAuction},
+		{15, 0, PhaseCloseAuction},
+		{15, 1, ""},
+	}
+	for _, tc := range cases {
+		in := time.Date(2026, 2, 2, tc.h, tc.m, 0, 0, loc)
+		if got := CNAuctionPhase(in); got != tc.want {
+			t.Fatalf("%02d:%02d: got=%q want=%q", tc.h, tc.m, got, tc.want)
+		}
+	}
+	// Weekend.
+	if got := CNAuctionPhase(time.Date(2026, 2, 1, 9, 20, 0, 0, loc)); got != "" {
+		t.Fatalf("expected no auction on weekend, got=%q", got)
+	}
+}
@@ -0,0 +1,109 @@
+package memstore
+
+import "time"
+
+// Dataset keys identify one independently fetched dataset; content versions (see bump)
+// and freshness metadata are tracked per key.
+func DatasetKeyToplist(fid string) string          { return "toplist:" + fid }
+func DatasetKeyBoard(boardType, fid string) string { return "board:" + boardType + ":" + fid }
+func DatasetKeyAgg(source, fid string) string      { return "agg:" + source + ":" + fid }
+
+const (
+	DatasetKeyNorthbound = "northbound"
+	DatasetKeyFundflow   = "fundflow"
+)
+
+// DatasetMeta describes how fresh one dataset is.
+type DatasetMeta struct {
+	// FetchedAt is the last successful fetch.
+	FetchedAt *time.Time `json:"fetched_at,omitempty"`
+	// UpstreamAt is the data time reported by the source, for sources that report one.
+	UpstreamAt *time.Time `json:"upstream_at,omitempty"`
+	// LastError is the most recent fetch error; it is kept after later successes, compare ErrorAt with FetchedAt.
+	LastError string     `json:"last_error,omitempty"`
+	ErrorAt   *time.Time `json:"error_at,omitempty"`
+}
+
+// Age returns how long ago the dataset was last fetched, or -1 if it never was.
+func (m DatasetMeta) Age(now time.Time) time.Duration {
+	if m.FetchedAt == nil {
+		return -1
+	}
+	return now.Sub(*m.FetchedAt)
+}
+
+// Failing reports whether the last fetch attempt failed.
+func (m DatasetMeta) Failing() bool {
+	return m.ErrorAt != nil && (m.FetchedAt == nil || m.ErrorAt.After(*m.FetchedAt))
+}
+
+// touch must be called with s.mu held for writing.
+func (s *Store) touch(key string, tsUTC, upstream time.Time) {
+	if s.meta == nil {
+		s.meta = make(map[string]DatasetMeta)
+	}
+	m := s.meta[key]
+	fetched := tsUTC
+	m.FetchedAt = &fetched
+	if !upstream.IsZero() {
+		up := upstream.UTC()
+		m.UpstreamAt = &up
+	}
+	s.meta[key] = m
+}
+
+// SetError records a failed fetch for a dataset key; its data and FetchedAt are left as they were.
+func (s *Store) SetError(key string, tsUTC time.Time, err error) {
+	if err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.meta == nil {
+		s.meta = make(map[string]DatasetMeta)
+	}
+	m := s.meta[key]
+	at := tsUTC
+	m.ErrorAt = &at
+	m.LastError = err.Error()
+	s.meta[key] = m
+}
+
+// Meta returns the freshness metadata of one dataset key.
+func (s *Store) Meta(key string) DatasetMeta {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.meta[key]
+}
+
+// metaCopy must be called with s.mu held.
+func (s *Store) metaCopy() map[string]DatasetMeta {
+	out := make(map[string]DatasetMeta, len(s.meta))
+	for k, v := range s.meta {
+		out[k] = v
+	}
+	return out
+}
+
+// latestFetch must be called with s.mu held.
+func (s *Store) latestFetch() time.Time {
+	var ts time.Time
+	for _, m := range s.meta {
+		if m.FetchedAt != nil && m.FetchedAt.After(ts) {
+			ts = *m.FetchedAt
+		}
+	}
+	return ts
+}
+
+// unixTime converts an upstream epoch timestamp in seconds or milliseconds.
+func unixTime(v int64) time.Time {
+	switch {
+	case v <= 0:
+		return time.Time{}
+	case v > 1e12:
+		return time.UnixMilli(v)
+	default:
+		return time.Unix(v, 0)
+	}
+}
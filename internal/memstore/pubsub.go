@@ -0,0 +1,125 @@
+package memstore
+
+import (
+	"time"
+
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/eastmoney"
+)
+
+// Event datasets.
+const (
+	EventNorthbound = "northbound"
+	EventFundflow   = "fundflow"
+	EventToplist    = "toplist"
+	EventBoard      = "board"
+	EventAgg        = "agg"
+)
+
+// backlogSize is how many events are kept for subscribers resuming from a sequence number.
+const backlogSize = 1024
+
+// Event is an incremental update published on each Set* call. Data holds only what changed:
+// NorthboundRT, []FundflowRT (changed codes), []TopItem (toplist: full list; board: changed boards) or float64.
+type Event struct {
+	Seq     uint64    `json:"seq"`
+	Dataset string    `json:"dataset"`
+	Key     string    `json:"key,omitempty"` // fid, boardType:fid or source:fid
+	TSUTC   time.Time `json:"ts_utc"`
+	Data    any       `json:"data"`
+}
+
+type pubsub struct {
+	seq     uint64
+	backlog *Ring[Event]
+	subs    map[chan Event]struct{}
+}
+
+// Subscription delivers events to one consumer. C is closed when the subscriber falls behind
+// by more than its buffer (the consumer should resume from its last Seq) or after Close.
+type Subscription struct {
+	C <-chan Event
+	s *Store
+	c chan Event
+}
+
+// Subscribe registers a consumer. With after > 0 the events newer than after are returned as backlog;
+// complete is false if some of them were already evicted, in which case the consumer should start
+// from a full Snapshot instead.
+func (s *Store) Subscribe(after uint64, buffer int) (sub *Subscription, backlog []Event, complete bool) {
+	if buffer < 1 {
+		buffer = 64
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.initPubsub()
+
+	complete = after > 0
+	if after > 0 {
+		backlog = s.pub.backlog.Filter(func(e Event) bool { return e.Seq > after })
+		oldest := s.pub.seq + 1
+		if len(backlog) > 0 {
+			oldest = backlog[0].Seq
+		}
+		complete = oldest == after+1 && after <= s.pub.seq
+	}
+	c := make(chan Event, buffer)
+	s.pub.subs[c] = struct{}{}
+	return &Subscription{C: c, s: s, c: c}, backlog, complete
+}
+
+// Close unregisters the subscription. It is safe to call more than once.
+func (sub *Subscription) Close() {
+	sub.s.mu.Lock()
+	defer sub.s.mu.Unlock()
+	if _, ok := sub.s.pub.subs[sub.c]; ok {
+		delete(sub.s.pub.subs, sub.c)
+		close(sub.c)
+	}
+}
+
+// Seq returns the sequence number of the latest published event.
+func (s *Store) Seq() uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.pub.seq
+}
+
+func (s *Store) initPubsub() {
+	if s.pub.backlog == nil {
+		s.pub.backlog = NewRing[Event](backlogSize)
+		s.pub.subs = make(map[chan Event]struct{})
+	}
+}
+
+// publish must be called with s.mu held for writing. Slow subscribers are dropped rather than
+// blocking the collector.
+func (s *Store) publish(dataset, key string, tsUTC time.Time, data any) {
+	s.initPubsub()
+	s.pub.seq++
+	e := Event{Seq: s.pub.seq, Dataset: dataset, Key: key, TSUTC: tsUTC, Data: data}
+	s.pub.backlog.Push(e)
+	for c := range s.pub.subs {
+		select {
+		case c <- e:
+		default:
+			delete(s.pub.subs, c)
+			close(c)
+		}
+	}
+}
+
+// changedItems returns the items of next that are new or differ from prev (matched by Code).
+func changedItems(prev, next []eastmoney.TopItem) []eastmoney.TopItem {
+	old := make(map[string]eastmoney.TopItem, len(prev))
+	for _, it := range prev {
+		old[it.Code] = it
+	}
+	var out []eastmoney.TopItem
+	for _, it := range next {
+		if o, ok := old[it.Code]; ok && o == it {
+			continue
+		}
+		out = append(out, it)
+	}
+	return out
+}
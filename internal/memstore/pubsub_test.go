@@ -0,0 +1,42 @@
+package memstore
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSubscribeResume(t *testing.T) {
+	s := New()
+	ts := time.Date(2024, 1, 2, 2, 0, 0, 0, time.UTC)
+	s.SetAgg(ts, "industry_sum", "f62", 1)
+	s.SetAgg(ts, "industry_sum", "f62", 2)
+
+	sub, backlog, complete := s.Subscribe(1, 4)
+	defer sub.Close()
+	if !complete || len(backlog) != 1 || backlog[0].Seq != 2 {
+		t.Fatalf("resume: complete=%v backlog=%+v", complete, backlog)
+	}
+	if _, _, complete := s.Subscribe(0, 4); complete {
+		t.Fatalf("fresh subscriber must start from a snapshot")
+	}
+	if _, _, complete := s.Subscribe(99, 4); complete {
+		t.Fatalf("unknown seq must start from a snapshot")
+	}
+
+	s.SetAgg(ts.Add(time.Second), "industry_sum", "f62", 3)
+	if e := <-sub.C; e.Seq != 3 || e.Data.(float64) != 3 {
+		t.Fatalf("live event=%+v", e)
+	}
+
+	// A subscriber that doesn't drain its buffer is dropped instead of blocking writers.
+	for i := 0; i < 5; i++ {
+		s.SetAgg(ts.Add(time.Duration(2+i)*time.Second), "industry_sum", "f62", float64(i))
+	}
+	n := 0
+	for range sub.C {
+		n++
+	}
+	if n != 4 {
+		t.Fatalf("buffered events before drop: %d", n)
+	}
+}
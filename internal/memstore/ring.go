@@ -0,0 +1,75 @@
+package memstore
+
+// Ring is a fixed-capacity FIFO buffer; once full, Push overwrites the oldest item.
+// Its storage grows with the items pushed (doubling, never past the capacity), so a ring
+// that only ever sees a few samples stays small.
+// It is not safe for concurrent use; Store guards its rings with its own mutex.
+type Ring[T any] struct {
+	buf  []T
+	size int // capacity
+	head int // index of the oldest item; 0 until buf is full
+	n    int
+}
+
+func NewRing[T any](capacity int) *Ring[T] {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &Ring[T]{size: capacity}
+}
+
+func (r *Ring[T]) Len() int { return r.n }
+
+func (r *Ring[T]) Cap() int { return r.size }
+
+func (r *Ring[T]) Push(v T) {
+	if r.n < r.size {
+		if r.n == cap(r.buf) {
+			grown := make([]T, r.n, min(max(2*r.n, 16), r.size))
+			copy(grown, r.buf)
+			r.buf = grown
+		}
+		r.buf = append(r.buf, v)
+		r.n++
+		return
+	}
+	r.buf[r.head] = v
+	r.head = (r.head + 1) % len(r.buf)
+}
+
+func (r *Ring[T]) Last() (T, bool) {
+	var zero T
+	if r.n == 0 {
+		return zero, false
+	}
+	return r.buf[(r.head+r.n-1)%len(r.buf)], true
+}
+
+// SetLast replaces the newest item; it is a no-op on an empty ring.
+func (r *Ring[T]) SetLast(v T) {
+	if r.n == 0 {
+		return
+	}
+	r.buf[(r.head+r.n-1)%len(r.buf)] = v
+}
+
+// Items returns a copy of the contents, oldest first.
+func (r *Ring[T]) Items() []T {
+	out := make([]T, r.n)
+	for i := 0; i < r.n; i++ {
+		out[i] = r.buf[(r.head+i)%len(r.buf)]
+	}
+	return out
+}
+
+// Filter returns items matching keep, oldest first.
+func (r *Ring[T]) Filter(keep func(T) bool) []T {
+	var out []T
+	for i := 0; i < r.n; i++ {
+		v := r.buf[(r.head+i)%len(r.buf)]
+		if keep(v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
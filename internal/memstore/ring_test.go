@@ -0,0 +1,57 @@
+package memstore
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRing(t *testing.T) {
+	r := NewRing[int](3)
+	if _, ok := r.Last(); ok || r.Len() != 0 {
+		t.Fatalf("empty ring: len=%d", r.Len())
+	}
+	r.Push(1)
+	r.Push(2)
+	if got := r.Items(); !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Fatalf("items=%v", got)
+	}
+	r.Push(3)
+	r.Push(4)
+	r.Push(5)
+	if got := r.Items(); !reflect.DeepEqual(got, []int{3, 4, 5}) {
+		t.Fatalf("wrapped items=%v", got)
+	}
+	r.SetLast(50)
+	if v, ok := r.Last(); !ok || v != 50 {
+		t.Fatalf("last=%v ok=%v", v, ok)
+	}
+	if got := r.Filter(func(v int) bool { return v%2 == 0 }); !reflect.DeepEqual(got, []int{4, 50}) {
+		t.Fatalf("filter=%v", got)
+	}
+}
+
+func TestRingGrowsLazily(t *testing.T) {
+	r := NewRing[int](100)
+	if r.Cap() != 100 || cap(r.buf) != 0 {
+		t.Fatalf("new ring cap=%d allocated=%d", r.Cap(), cap(r.buf))
+	}
+	for i := 0; i < 20; i++ {
+		r.Push(i)
+	}
+	if cap(r.buf) >= 100 {
+		t.Fatalf("20 items allocated %d slots", cap(r.buf))
+	}
+	for i := 20; i < 250; i++ {
+		r.Push(i)
+	}
+	if cap(r.buf) != 100 || r.Len() != 100 {
+		t.Fatalf("full ring allocated=%d len=%d", cap(r.buf), r.Len())
+	}
+	items := r.Items()
+	if items[0] != 150 || items[99] != 249 {
+		t.Fatalf("items=%v..%v", items[0], items[99])
+	}
+	if v, _ := r.Last(); v != 249 {
+		t.Fatalf("last=%d", v)
+	}
+}
@@ -0,0 +1,205 @@
+package memstore
+
+import (
+	"time"
+
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/eastmoney"
+)
+
+// SeriesCapacity bounds each intraday ring: a 4h session sampled every 5s.
+// Rings grow as samples arrive, so memory is at most keys × SeriesCapacity × point size per
+// series, e.g. a full FundflowPoint ring (64 bytes a point) takes about 180 KiB, and a key sampled
+// only a few times takes a few KiB.
+const SeriesCapacity = 2880
+
+type Point struct {
+	TSUTC time.Time `json:"ts_utc"`
+	Value float64   `json:"value"`
+}
+
+type FundflowPoint struct {
+	TSUTC   time.Time `json:"ts_utc"`
+	NetMain float64   `json:"net_main"`
+	NetXL   float64   `json:"net_xl"`
+	NetL    float64   `json:"net_l"`
+	NetM    float64   `json:"net_m"`
+	NetS    float64   `json:"net_s"`
+}
+
+type BoardPoint struct {
+	TSUTC time.Time `json:"ts_utc"`
+	Price float64   `json:"price"`
+	Pct   float64   `json:"pct"`
+	Value float64   `json:"value"`
+}
+
+// BoardSumPoint sums value and price over all boards of one type/fid at a tick.
+type BoardSumPoint struct {
+	TSUTC time.Time `json:"ts_utc"`
+	Value float64   `json:"value"`
+	Price float64   `json:"price"`
+}
+
+type NorthboundPoint struct {
+	TSUTC time.Time `json:"ts_utc"`
+	SH    float64   `json:"sh"` // day net amount in
+	SZ    float64   `json:"sz"`
+}
+
+func (p Point) ts() time.Time           { return p.TSUTC }
+func (p FundflowPoint) ts() time.Time   { return p.TSUTC }
+func (p BoardPoint) ts() time.Time      { return p.TSUTC }
+func (p BoardSumPoint) ts() time.Time   { return p.TSUTC }
+func (p NorthboundPoint) ts() time.Time { return p.TSUTC }
+
+type timed interface{ ts() time.Time }
+
+// series holds today's intraday history per key. All rings are dropped when the
+// Asia/Shanghai date of incoming samples changes.
+type series struct {
+	date       string
+	fundflow   map[string]*Ring[FundflowPoint]   // code
+	boards     map[string]*Ring[BoardPoint]      // board code
+	boardSums  map[string]*Ring[BoardSumPoint]   // boardType:fid
+	agg        map[string]*Ring[Point]           // source:fid
+	northbound map[string]*Ring[NorthboundPoint] // single key ""
+}
+
+// seriesFor returns the series for tsUTC's trade date, resetting it on a new day.
+// It returns nil for samples of an older day. Caller must hold s.mu.
+func (s *Store) seriesFor(tsUTC time.Time) *series {
+	date := tsUTC.In(s.loc).Format("2006-01-02")
+	if s.series != nil && date < s.series.date {
+		return nil
+	}
+	if s.series == nil || date != s.series.date {
+		s.series = &series{
+			date:       date,
+			fundflow:   make(map[string]*Ring[FundflowPoint]),
+			boards:     make(map[string]*Ring[BoardPoint]),
+			boardSums:  make(map[string]*Ring[BoardSumPoint]),
+			agg:        make(map[string]*Ring[Point]),
+			northbound: make(map[string]*Ring[NorthboundPoint]),
+		}
+	}
+	return s.series
+}
+
+// pushSeries appends v to the ring for key. A sample with the same timestamp as the newest
+// replaces it; older samples are ignored so replays can't reorder a ring.
+func pushSeries[T timed](m map[string]*Ring[T], key string, v T) {
+	r, ok := m[key]
+	if !ok {
+		r = NewRing[T](SeriesCapacity)
+		m[key] = r
+	}
+	if last, ok := r.Last(); ok {
+		switch {
+		case v.ts().Equal(last.ts()):
+			r.SetLast(v)
+			return
+		case v.ts().Before(last.ts()):
+			return
+		}
+	}
+	r.Push(v)
+}
+
+func seriesItems[T any](m map[string]*Ring[T], key string) []T {
+	r, ok := m[key]
+	if !ok {
+		return nil
+	}
+	return r.Items()
+}
+
+func (s *Store) appendFundflowSeries(tsUTC time.Time, rows []eastmoney.FundflowRT) {
+	ser := s.seriesFor(tsUTC)
+	if ser == nil {
+		return
+	}
+	for _, r := range rows {
+		if r.Code == "" {
+			continue
+		}
+		pushSeries(ser.fundflow, r.Code, FundflowPoint{
+			TSUTC: tsUTC, NetMain: r.NetMain, NetXL: r.NetXL, NetL: r.NetL, NetM: r.NetM, NetS: r.NetS,
+		})
+	}
+}
+
+func (s *Store) appendBoardSeries(tsUTC time.Time, boardType, fid string, rows []eastmoney.TopItem) {
+	ser := s.seriesFor(tsUTC)
+	if ser == nil {
+		return
+	}
+	sum := BoardSumPoint{TSUTC: tsUTC}
+	for _, it := range rows {
+		sum.Value += it.Value
+		sum.Price += it.Price
+		if it.Code != "" {
+			pushSeries(ser.boards, it.Code, BoardPoint{TSUTC: tsUTC, Price: it.Price, Pct: it.Pct, Value: it.Value})
+		}
+	}
+	pushSeries(ser.boardSums, boardType+":"+fid, sum)
+}
+
+// SeriesDate is the Asia/Shanghai trade date the in-memory series belong to ("" if empty).
+func (s *Store) SeriesDate() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.series == nil {
+		return ""
+	}
+	return s.series.date
+}
+
+// FundflowSeries returns today's samples for a stock code, oldest first.
+func (s *Store) FundflowSeries(code string) []FundflowPoint {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.series == nil {
+		return nil
+	}
+	return seriesItems(s.series.fundflow, code)
+}
+
+// BoardSeries returns today's samples for a board code (e.g. BK0457), oldest first.
+func (s *Store) BoardSeries(code string) []BoardPoint {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.series == nil {
+		return nil
+	}
+	return seriesItems(s.series.boards, code)
+}
+
+// BoardSumSeries returns today's per-tick sums over all boards of boardType/fid, oldest first.
+func (s *Store) BoardSumSeries(boardType, fid string) []BoardSumPoint {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.series == nil {
+		return nil
+	}
+	return seriesItems(s.series.boardSums, boardType+":"+fid)
+}
+
+// AggSeries returns today's market aggregate samples for source/fid, oldest first.
+func (s *Store) AggSeries(source, fid string) []Point {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.series == nil {
+		return nil
+	}
+	return seriesItems(s.series.agg, source+":"+fid)
+}
+
+// NorthboundSeries returns today's northbound net inflow samples, oldest first.
+func (s *Store) NorthboundSeries() []NorthboundPoint {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.series == nil {
+		return nil
+	}
+	return seriesItems(s.series.northbound, "")
+}
@@ -5,139 +5,217 @@ import (
 	"time"
 
 	"github.com/pcdogyu/A-Stock-Order-Flow/internal/eastmoney"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/symbol"
 )
 
 // Store keeps the latest realtime fetch results in memory.
 // This avoids writing every tick to SQLite; a separate snapshot task persists periodically.
 type Store struct {
-	mu sync.RWMutex
+	mu  sync.RWMutex
+	loc *time.Location
+
+	// series keeps today's intraday history for charts (see series.go).
+	series *series
+	// pub fans out each Set* as an incremental Event (see pubsub.go).
+	pub pubsub
+	// versions counts content changes per dataset key (see meta.go); a Set* with
+	// identical data leaves the version alone so unchanged datasets aren't persisted again.
+	versions map[string]uint64
+	// meta tracks fetch time, upstream time and last error per dataset key.
+	meta map[string]DatasetMeta
 
 	northbound struct {
-		tsUTC time.Time
-		val   eastmoney.NorthboundRT
-		ok    bool
+		val eastmoney.NorthboundRT
+		ok  bool
 	}
 
 	fundflow struct {
-		tsUTC  time.Time
-		byCode map[string]eastmoney.FundflowRT
+		// byKey is keyed by secid (by code for rows without one, e.g. seeded from SQLite) so
+		// codes shared across markets, like 000001.SH and 000001.SZ, don't replace each other.
+		byKey map[string]eastmoney.FundflowRT
+		// okAt is the last successful fetch per code; with chunked fetches some codes can lag.
+		okAt    map[string]time.Time
+		invalid []symbol.InvalidSymbol
 	}
 
 	toplist struct {
-		tsUTC time.Time
 		byFID map[string][]eastmoney.TopItem
 	}
 
 	boards struct {
-		tsUTC time.Time
 		// key: boardType ("industry"/"concept") + ":" + fid
 		byKey map[string][]eastmoney.TopItem
 	}
 
 	agg struct {
-		tsUTC time.Time
 		// key: source + ":" + fid
 		byKey map[string]float64
 	}
 }
 
 func New() *Store {
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		loc = time.FixedZone("CST", 8*3600)
+	}
 	return &Store{
+		loc: loc,
 		fundflow: struct {
-			tsUTC  time.Time
-			byCode map[string]eastmoney.FundflowRT
-		}{byCode: make(map[string]eastmoney.FundflowRT)},
+			byKey   map[string]eastmoney.FundflowRT
+			okAt    map[string]time.Time
+			invalid []symbol.InvalidSymbol
+		}{byKey: make(map[string]eastmoney.FundflowRT), okAt: make(map[string]time.Time)},
 		toplist: struct {
-			tsUTC time.Time
 			byFID map[string][]eastmoney.TopItem
 		}{byFID: make(map[string][]eastmoney.TopItem)},
 		boards: struct {
-			tsUTC time.Time
 			byKey map[string][]eastmoney.TopItem
 		}{byKey: make(map[string][]eastmoney.TopItem)},
 		agg: struct {
-			tsUTC time.Time
 			byKey map[string]float64
 		}{byKey: make(map[string]float64)},
 	}
 }
 
+// bump must be called with s.mu held for writing.
+func (s *Store) bump(key string) {
+	if s.versions == nil {
+		s.versions = make(map[string]uint64)
+	}
+	s.versions[key]++
+}
+
 func (s *Store) SetNorthbound(tsUTC time.Time, v eastmoney.NorthboundRT) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.northbound.tsUTC = tsUTC
+	if !s.northbound.ok || s.northbound.val != v {
+		s.bump(DatasetKeyNorthbound)
+	}
+	upstream := unixTime(v.SH.UpdateTime)
+	if sz := unixTime(v.SZ.UpdateTime); sz.After(upstream) {
+		upstream = sz
+	}
+	s.touch(DatasetKeyNorthbound, tsUTC, upstream)
 	s.northbound.val = v
 	s.northbound.ok = true
+	if ser := s.seriesFor(tsUTC); ser != nil {
+		pushSeries(ser.northbound, "", NorthboundPoint{TSUTC: tsUTC, SH: v.SH.DayNetAmtIn, SZ: v.SZ.DayNetAmtIn})
+	}
+	s.publish(EventNorthbound, "", tsUTC, v)
 }
 
 func (s *Store) SetFundflow(tsUTC time.Time, rows []eastmoney.FundflowRT) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.fundflow.tsUTC = tsUTC
-	if s.fundflow.byCode == nil {
-		s.fundflow.byCode = make(map[string]eastmoney.FundflowRT)
+	if s.fundflow.byKey == nil {
+		s.fundflow.byKey = make(map[string]eastmoney.FundflowRT)
+	}
+	if s.fundflow.okAt == nil {
+		s.fundflow.okAt = make(map[string]time.Time)
 	}
+	var changed []eastmoney.FundflowRT
+	var upstream time.Time
 	for _, r := range rows {
+		if t := unixTime(r.UpdateTime); t.After(upstream) {
+			upstream = t
+		}
 		if r.Code != "" {
-			s.fundflow.byCode[r.Code] = r
+			key := r.Code
+			if r.RawSecID != "" {
+				key = r.RawSecID
+				delete(s.fundflow.byKey, r.Code) // superseded seed row
+			}
+			if old, ok := s.fundflow.byKey[key]; !ok || old != r {
+				changed = append(changed, r)
+			}
+			s.fundflow.byKey[key] = r
+			s.fundflow.okAt[r.Code] = tsUTC
 		}
 	}
+	s.touch(DatasetKeyFundflow, tsUTC, upstream)
+	s.appendFundflowSeries(tsUTC, rows)
+	if len(changed) > 0 {
+		s.bump(DatasetKeyFundflow)
+		s.publish(EventFundflow, "", tsUTC, changed)
+	}
+}
+
+// SetInvalidSymbols records watchlist entries skipped by the last fundflow tick.
+func (s *Store) SetInvalidSymbols(v []symbol.InvalidSymbol) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fundflow.invalid = append([]symbol.InvalidSymbol(nil), v...)
 }
 
 func (s *Store) SetToplist(tsUTC time.Time, fid string, rows []eastmoney.TopItem) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.toplist.tsUTC = tsUTC
+	s.touch(DatasetKeyToplist(fid), tsUTC, time.Time{})
 	if s.toplist.byFID == nil {
 		s.toplist.byFID = make(map[string][]eastmoney.TopItem)
 	}
+	if !equalItems(s.toplist.byFID[fid], rows) {
+		s.bump(DatasetKeyToplist(fid))
+	}
 	s.toplist.byFID[fid] = append([]eastmoney.TopItem(nil), rows...)
+	s.publish(EventToplist, fid, tsUTC, s.toplist.byFID[fid])
 }
 
 func (s *Store) SetBoard(tsUTC time.Time, boardType, fid string, rows []eastmoney.TopItem) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.boards.tsUTC = tsUTC
+	s.touch(DatasetKeyBoard(boardType, fid), tsUTC, time.Time{})
 	if s.boards.byKey == nil {
 		s.boards.byKey = make(map[string][]eastmoney.TopItem)
 	}
 	key := boardType + ":" + fid
-	s.boards.byKey[key] = append([]eastmoney.TopItem(nil), rows...)
-}
-
-func (s *Store) BoardTS(boardType, fid string) time.Time {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	if s.boards.byKey == nil {
-		return time.Time{}
+	changed := changedItems(s.boards.byKey[key], rows)
+	if !equalItems(s.boards.byKey[key], rows) {
+		s.bump(DatasetKeyBoard(boardType, fid))
 	}
-	key := boardType + ":" + fid
-	if _, ok := s.boards.byKey[key]; !ok {
-		return time.Time{}
+	s.boards.byKey[key] = append([]eastmoney.TopItem(nil), rows...)
+	s.appendBoardSeries(tsUTC, boardType, fid, rows)
+	if len(changed) > 0 {
+		s.publish(EventBoard, key, tsUTC, changed)
 	}
-	return s.boards.tsUTC
 }
 
 func (s *Store) SetAgg(tsUTC time.Time, source, fid string, value float64) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.agg.tsUTC = tsUTC
+	s.touch(DatasetKeyAgg(source, fid), tsUTC, time.Time{})
 	if s.agg.byKey == nil {
 		s.agg.byKey = make(map[string]float64)
 	}
 	key := source + ":" + fid
+	if old, ok := s.agg.byKey[key]; !ok || old != value {
+		s.bump(DatasetKeyAgg(source, fid))
+	}
 	s.agg.byKey[key] = value
+	if ser := s.seriesFor(tsUTC); ser != nil {
+		pushSeries(ser.agg, key, Point{TSUTC: tsUTC, Value: value})
+	}
+	s.publish(EventAgg, key, tsUTC, value)
 }
 
 type Snapshot struct {
 	TSUTC time.Time `json:"ts_utc"`
+	// Seq is the latest Event included in the snapshot (see Subscribe).
+	Seq uint64 `json:"seq,omitempty"`
+	// Versions are the content versions of each dataset key at snapshot time.
+	Versions map[string]uint64 `json:"-"`
 
 	Northbound *eastmoney.NorthboundRT `json:"northbound,omitempty"`
 	Fundflow   []eastmoney.FundflowRT  `json:"fundflow,omitempty"`
+	// FundflowUpdated is the last successful fetch per fundflow code.
+	FundflowUpdated map[string]time.Time   `json:"fundflow_updated,omitempty"`
+	InvalidSymbols  []symbol.InvalidSymbol `json:"invalid_symbols,omitempty"`
 
 	ToplistByFID map[string][]eastmoney.TopItem `json:"toplist_by_fid,omitempty"`
 	BoardsByKey  map[string][]eastmoney.TopItem `json:"boards_by_key,omitempty"`
 	AggByKey     map[string]float64             `json:"agg_by_key,omitempty"`
+	// Meta is the freshness of each dataset key (see meta.go).
+	Meta map[string]DatasetMeta `json:"meta,omitempty"`
 }
 
 func (s *Store) Snapshot(tsUTC time.Time) Snapshot {
@@ -150,11 +228,21 @@ func (s *Store) Snapshot(tsUTC time.Time) Snapshot {
 		nb = &tmp
 	}
 
-	ff := make([]eastmoney.FundflowRT, 0, len(s.fundflow.byCode))
-	for _, v := range s.fundflow.byCode {
+	ff := make([]eastmoney.FundflowRT, 0, len(s.fundflow.byKey))
+	for _, v := range s.fundflow.byKey {
 		ff = append(ff, v)
 	}
 
+	ffAt := make(map[string]time.Time, len(s.fundflow.okAt))
+	for k, v := range s.fundflow.okAt {
+		ffAt[k] = v
+	}
+	invalid := append([]symbol.InvalidSymbol(nil), s.fundflow.invalid...)
+	versions := make(map[string]uint64, len(s.versions))
+	for k, v := range s.versions {
+		versions[k] = v
+	}
+
 	top := make(map[string][]eastmoney.TopItem, len(s.toplist.byFID))
 	for k, v := range s.toplist.byFID {
 		top[k] = append([]eastmoney.TopItem(nil), v...)
@@ -171,12 +259,17 @@ func (s *Store) Snapshot(tsUTC time.Time) Snapshot {
 	}
 
 	return Snapshot{
-		TSUTC:        tsUTC,
-		Northbound:   nb,
-		Fundflow:     ff,
-		ToplistByFID: top,
-		BoardsByKey:  boards,
-		AggByKey:     agg,
+		TSUTC:           tsUTC,
+		Northbound:      nb,
+		Fundflow:        ff,
+		FundflowUpdated: ffAt,
+		InvalidSymbols:  invalid,
+		Seq:             s.pub.seq,
+		Versions:        versions,
+		ToplistByFID:    top,
+		BoardsByKey:     boards,
+		AggByKey:        agg,
+		Meta:            s.metaCopy(),
 	}
 }
 
@@ -186,22 +279,7 @@ func (s *Store) SnapshotLatest() Snapshot {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	var ts time.Time
-	if s.northbound.ok && s.northbound.tsUTC.After(ts) {
-		ts = s.northbound.tsUTC
-	}
-	if s.fundflow.tsUTC.After(ts) {
-		ts = s.fundflow.tsUTC
-	}
-	if s.toplist.tsUTC.After(ts) {
-		ts = s.toplist.tsUTC
-	}
-	if s.boards.tsUTC.After(ts) {
-		ts = s.boards.tsUTC
-	}
-	if s.agg.tsUTC.After(ts) {
-		ts = s.agg.tsUTC
-	}
+	ts := s.latestFetch()
 	if ts.IsZero() {
 		ts = time.Now().UTC()
 	}
@@ -212,11 +290,21 @@ func (s *Store) SnapshotLatest() Snapshot {
 		nb = &tmp
 	}
 
-	ff := make([]eastmoney.FundflowRT, 0, len(s.fundflow.byCode))
-	for _, v := range s.fundflow.byCode {
+	ff := make([]eastmoney.FundflowRT, 0, len(s.fundflow.byKey))
+	for _, v := range s.fundflow.byKey {
 		ff = append(ff, v)
 	}
 
+	ffAt := make(map[string]time.Time, len(s.fundflow.okAt))
+	for k, v := range s.fundflow.okAt {
+		ffAt[k] = v
+	}
+	invalid := append([]symbol.InvalidSymbol(nil), s.fundflow.invalid...)
+	versions := make(map[string]uint64, len(s.versions))
+	for k, v := range s.versions {
+		versions[k] = v
+	}
+
 	top := make(map[string][]eastmoney.TopItem, len(s.toplist.byFID))
 	for k, v := range s.toplist.byFID {
 		top[k] = append([]eastmoney.TopItem(nil), v...)
@@ -233,11 +321,28 @@ func (s *Store) SnapshotLatest() Snapshot {
 	}
 
 	return Snapshot{
-		TSUTC:        ts,
-		Northbound:   nb,
-		Fundflow:     ff,
-		ToplistByFID: top,
-		BoardsByKey:  boards,
-		AggByKey:     agg,
+		TSUTC:           ts,
+		Northbound:      nb,
+		Fundflow:        ff,
+		FundflowUpdated: ffAt,
+		InvalidSymbols:  invalid,
+		Seq:             s.pub.seq,
+		Versions:        versions,
+		ToplistByFID:    top,
+		BoardsByKey:     boards,
+		AggByKey:        agg,
+		Meta:            s.metaCopy(),
+	}
+}
+
+func equalItems(a, b []eastmoney.TopItem) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
 	}
+	return true
 }
@@ -0,0 +1,75 @@
+package memstore
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/eastmoney"
+)
+
+func TestVersionsOnlyBumpOnChange(t *testing.T) {
+	s := New()
+	ts := time.Date(2024, 1, 2, 2, 0, 0, 0, time.UTC)
+	rows := []eastmoney.TopItem{{Code: "BK1", Value: 1}}
+	key := DatasetKeyBoard("industry", "f62")
+
+	s.SetBoard(ts, "industry", "f62", rows)
+	s.SetBoard(ts.Add(time.Minute), "industry", "f62", rows)
+	if v := s.Snapshot(ts).Versions[key]; v != 1 {
+		t.Fatalf("identical board bumped version: %d", v)
+	}
+	s.SetBoard(ts.Add(2*time.Minute), "industry", "f62", []eastmoney.TopItem{{Code: "BK1", Value: 2}})
+	if v := s.Snapshot(ts).Versions[key]; v != 2 {
+		t.Fatalf("changed board version=%d", v)
+	}
+
+	s.SetAgg(ts, "industry_sum", "f62", 3)
+	s.SetAgg(ts.Add(time.Minute), "industry_sum", "f62", 3)
+	if v := s.Snapshot(ts).Versions[DatasetKeyAgg("industry_sum", "f62")]; v != 1 {
+		t.Fatalf("agg version=%d", v)
+	}
+}
+
+func TestMetaPerDatasetKey(t *testing.T) {
+	s := New()
+	ts := time.Date(2024, 1, 2, 2, 0, 0, 0, time.UTC)
+	s.SetBoard(ts, "industry", "f62", nil)
+	s.SetBoard(ts.Add(time.Minute), "concept", "f62", nil)
+	s.SetError(DatasetKeyBoard("industry", "f62"), ts.Add(2*time.Minute), errors.New("timeout"))
+
+	ind := s.Meta(DatasetKeyBoard("industry", "f62"))
+	if ind.FetchedAt == nil || !ind.FetchedAt.Equal(ts) || !ind.Failing() || ind.LastError != "timeout" {
+		t.Fatalf("industry meta=%+v", ind)
+	}
+	con := s.Meta(DatasetKeyBoard("concept", "f62"))
+	if con.Failing() || con.Age(ts.Add(2*time.Minute)) != time.Minute {
+		t.Fatalf("concept meta=%+v", con)
+	}
+	if got := s.SnapshotLatest().TSUTC; !got.Equal(ts.Add(time.Minute)) {
+		t.Fatalf("latest ts=%v", got)
+	}
+
+	s.SetNorthbound(ts, eastmoney.NorthboundRT{SH: eastmoney.NorthboundLeg{UpdateTime: 1704160800}})
+	if m := s.Meta(DatasetKeyNorthbound); m.UpstreamAt == nil || m.UpstreamAt.Unix() != 1704160800 {
+		t.Fatalf("northbound meta=%+v", m)
+	}
+}
+
+func TestFundflowKeepsMarketsApart(t *testing.T) {
+	s := New()
+	ts := time.Date(2024, 1, 2, 2, 0, 0, 0, time.UTC)
+	// A row seeded from SQLite has no secid; the live rows replace it.
+	s.SetFundflow(ts, []eastmoney.FundflowRT{{Code: "000001", NetMain: 1}})
+	s.SetFundflow(ts.Add(time.Minute), []eastmoney.FundflowRT{
+		{Code: "000001", RawSecID: "1.000001", NetMain: 1000},
+		{Code: "000001", RawSecID: "0.000001", NetMain: 10},
+	})
+	got := map[string]float64{}
+	for _, r := range s.Snapshot(ts).Fundflow {
+		got[r.RawSecID] = r.NetMain
+	}
+	if len(got) != 2 || got["1.000001"] != 1000 || got["0.000001"] != 10 {
+		t.Fatalf("fundflow=%v", got)
+	}
+}
@@ -0,0 +1,101 @@
+//go:build ignore
+
+// gen writes table.go from the CLDR pinyin collation order as shipped in Perl's
+// Unicode::Collate::CJK::Pinyin (perl-modules): after the data header, characters are listed
+// in pinyin order and a "FDD0-00xx" line starts the characters whose reading begins with the
+// letter xx. Run with: go run gen.go -src /usr/share/perl/5.36.0/Unicode/Collate/CJK/Pinyin.pm
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	lo = 0x4E00 // CJK Unified Ideographs
+	hi = 0x9FFF
+)
+
+func main() {
+	src := flag.String("src", "/usr/share/perl/5.36.0/Unicode/Collate/CJK/Pinyin.pm", "Unicode::Collate::CJK::Pinyin source")
+	out := flag.String("out", "table.go", "output file")
+	flag.Parse()
+
+	f, err := os.Open(*src)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	table := bytes.Repeat([]byte{'-'}, hi-lo+1)
+	var letter byte
+	data := false
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "__DATA__" {
+			data = true
+			continue
+		}
+		if !data {
+			continue
+		}
+		if line == "__END__" {
+			break
+		}
+		for _, tok := range strings.Fields(line) {
+			if sep, ok := strings.CutPrefix(tok, "FDD0-"); ok {
+				v, err := strconv.ParseUint(sep, 16, 8)
+				if err != nil {
+					log.Fatalf("separator %q: %v", tok, err)
+				}
+				letter = byte(v)
+				continue
+			}
+			r, err := strconv.ParseUint(tok, 16, 32)
+			if err != nil {
+				log.Fatalf("code point %q: %v", tok, err)
+			}
+			if letter == 0 {
+				log.Fatalf("%s before the first letter", tok)
+			}
+			// The first (most common) reading wins for characters listed twice.
+			if r >= lo && r <= hi && table[r-lo] == '-' {
+				table[r-lo] = letter
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		log.Fatal(err)
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by gen.go from the CLDR pinyin collation; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package pinyin\n\n")
+	fmt.Fprintf(&b, "const (\n\ttableLo = 0x%X\n\ttableHi = 0x%X\n)\n\n", lo, hi)
+	fmt.Fprintf(&b, "// table holds the initial of each code point from tableLo to tableHi, '-' if it has none.\n")
+	fmt.Fprintf(&b, "const table = \"\" +\n")
+	const width = 64
+	for i := 0; i < len(table); i += width {
+		end := min(i+width, len(table))
+		sep := " +"
+		if end == len(table) {
+			sep = ""
+		}
+		fmt.Fprintf(&b, "\t%q%s // U+%04X\n", table[i:end], sep, lo+i)
+	}
+	code, err := format.Source(b.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*out, code, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,85 @@
+// Package pinyin derives pinyin initials (e.g. 贵州茅台 -> GZMT) for security search.
+//
+// Initials of the CJK Unified Ideographs (U+4E00-U+9FFF, which includes all of GBK's) come from
+// table.go, generated from the CLDR pinyin collation, with overrides for polyphonic characters
+// whose reading in security names differs from the table's.
+package pinyin
+
+//go:generate go run gen.go
+
+import (
+	"strings"
+	"unicode"
+)
+
+// overrides are characters whose usual reading in security and place names isn't the one the
+// CLDR table lists first. Most keep the GB2312 level-1 reading (长城, 厦门, 沈阳, 曾, 黄埔).
+var overrides = map[rune]byte{
+	'行': 'H', // 银行, 行业 (table: xing)
+	'重': 'C', // 重庆 (zhong)
+	'藏': 'Z', // 西藏 (cang)
+	'晟': 'S', // sheng in company names (cheng)
+	'长': 'C', // 长城, 长江 (zhang)
+	'厦': 'X', // 厦门 (sha)
+	'沈': 'S', // 沈阳 (chen)
+	'曾': 'Z', // zeng as a surname (ceng)
+	'埔': 'P', // 黄埔 (bu)
+	'乾': 'Q', // 乾照光电 (gan)
+	'蛤': 'G', // 蛤蜊 (ha)
+	'泊': 'B', // 泊车 (po)
+	'辟': 'B', // (pi)
+	'茄': 'Q', // (jia)
+	'脯': 'F', // (pu)
+	'畜': 'X', // 畜牧 (chu)
+	'轧': 'Z', // 轧钢 (ya)
+	'辗': 'Z', // (nian)
+	'伺': 'S', // (ci)
+	'傀': 'K', // (gui)
+	'匙': 'C', // (shi)
+	'吁': 'Y', // (xu)
+	'呵': 'H', // (a)
+	'咯': 'K', // (ge)
+	'咳': 'K', // (hai)
+	'掠': 'L', // lüe (table: e)
+	'略': 'L', // lüe (e)
+	'椎': 'Z', // (chui)
+	'槛': 'J', // (kan)
+	'炔': 'Q', // (gui)
+}
+
+// Initial returns the upper-case pinyin initial of a Chinese character, or 0 if it has none.
+func Initial(r rune) byte {
+	if b, ok := overrides[r]; ok {
+		return b
+	}
+	if r < tableLo || r > tableHi {
+		return 0
+	}
+	if b := table[r-tableLo]; b != '-' {
+		return b
+	}
+	return 0
+}
+
+// Initials returns the search key of a name: the pinyin initial of each Chinese character and
+// ASCII letters and digits as they are, upper-cased (沪深300 -> HS300, ＳＴ华通 -> STHT).
+// Other characters (spaces, *, -) are dropped.
+func Initials(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= 0xFF01 && r <= 0xFF5E {
+			r -= 0xFEE0 // full-width ASCII
+		}
+		switch {
+		case r < unicode.MaxASCII:
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				b.WriteRune(unicode.ToUpper(r))
+			}
+		default:
+			if c := Initial(r); c != 0 {
+				b.WriteByte(c)
+			}
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,47 @@
+package pinyin
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInitials(t *testing.T) {
+	for name, want := range map[string]string{
+		"贵州茅台":    "GZMT",
+		"平安银行":    "PAYH",
+		"沪深300":   "HS300",
+		"万 科Ａ":    "WKA",
+		"*ST华通":   "STHT",
+		"重庆啤酒":    "CQPJ",
+		"泸州老窖":    "LZLJ",
+		"科创50ETF": "KC50ETF",
+		"酿酒行业":    "NJHY",
+		"鑫科材料":    "XKCL",
+		"赣锋锂业":    "GFLY",
+		"璞泰来":     "PTL",
+		"珀莱雅":     "PLY",
+		"昊华能源":    "HHNY",
+		"长城汽车":    "CCQC",
+		"厦门钨业":    "XMWY",
+		"沈阳机床":    "SYJC",
+		"西藏药业":    "XZYY",
+	} {
+		if got := Initials(name); got != want {
+			t.Errorf("Initials(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestInitialCoversGBK(t *testing.T) {
+	// GBK's ideographs are U+4E00-U+9FA5. Two-syllable unit characters (兙 = 十克, ...) have no
+	// single reading.
+	const none = "兙兡嗧桛烪瓧瓰瓱瓼甅"
+	for r := rune(0x4E00); r <= 0x9FA5; r++ {
+		if strings.ContainsRune(none, r) {
+			continue
+		}
+		if Initial(r) == 0 {
+			t.Errorf("%c (U+%04X) has no initial", r, r)
+		}
+	}
+}
@@ -0,0 +1,339 @@
+// Code generated by gen.go from the CLDR pinyin collation; DO NOT EDIT.
+
+package pinyin
+
+const (
+	tableLo = 0x4E00
+	tableHi = 0x9FFF
+)
+
+// table holds the initial of each code point from tableLo to tableHi, '-' if it has none.
+const table = "" +
+	"YDKQSXHWZSSXJBYMGCCZQPSSQBYCDSCDQLDYLYBSGJGYQZJJFGCCLZZHWDWZJLJP" + // U+4E00
+	"FYYNWJJTMYYZWZHFLYPPQHGCCYYYMJQYXXGJXHSDSJNJJSMHMLZRXYFSNGSYCZGZ" + // U+4E40
+	"GGLLYJLMYZSSECYKYYHQWJSSGGYXYQYJTWKTJHYCHMYXJTLXJYQBYXDLDMRRJJWY" + // U+4E80
+	"SRLDZJPCBZJJBRCFSLBCZSTZFXXTHTRQGGBDLYCCSSYMMRJCYQZPWWJJYFCRWFDF" + // U+4EC0
+	"ZQPYDDWYXKYJAWJFFXJPDFTZYHHYCCSWCCYXSCLCXXWZZXNBGNNXBXLZSQCBSJPY" + // U+4F00
+	"SYZDHMDZBQBZCWDZZYYTZHBTSYYFZGNTNXQYWQSKBPHHLXGYBFMJEBJHHGQTJCYS" + // U+4F40
+	"XSTKZGLYCKGLYSMZXYALMELDCCXGZYRCXSZLTJZCQKCNNJWHJCZZCQLJSTSTBNXB" + // U+4F80
+	"TYXCEQXGKWJYFLZQLYHJQSPSFXLFPBYQXXXYDCCZYLLLSJXFHJXPJBCFFYABYXBH" + // U+4FC0
+	"CZBJYCLWLCZGGBTSSMDTJCXPTHYQTGJJSCJFZKJZJQNLZWLSLHDZBWJNCJZYZSQQ" + // U+5000
+	"YCJYRZCJJWYBRTWPYFTWEXCSKDZCTBXHYZCYYJXZCFBZZMJYXXCDCZOTTBZLJWFC" + // U+5040
+	"GSZSXFYRLNYJMBDTHJXSQJCCSBXYYTSYFBJDZTGBCNCLCYZZBSACYZZSCJCSHZQY" + // U+5080
+	"DXLBPJLLMQXTYDZXSQJTZPXLCGLQCCWJBHCTDJJSFXJEJJTLBGXSXJMYJJQPFZAS" + // U+50C0
+	"YJNCYDJXKJCDJSZCBARTCCLNJQMWNQNCLLLKBYBZZSYHCCLTWLCCRSHLLZNTYLNE" + // U+5100
+	"WYZYXCZXXGDKDMTCEDEJTSYYS-DQDFMSD-JLHRWNQLYBGLXHLGTGXBQJDZFYJSJY" + // U+5140
+	"JCJMRNYMGRCJCZGJMZMGXMMRYXKJNYMSGMZJYMKLFXMBDTGFBHCJHKYLPFMDXLQJ" + // U+5180
+	"JSMTQGZSJLQDLDGJYCYLCMZCSDJLLNXDJFFFFJCZFMZFFPFKHKGDPQXKTACJDHHZ" + // U+51C0
+	"DDDRRCFQYJKQCCWJDXHWJLYLLZGCFCQJSMLZPBJJPLSBCJGGDCKKDEZSQSCKJGCG" + // U+5200
+	"KDJTJLLZYCXKLQSCGJCLTFPCQCZGWBJDQSDJJBYJHSJDDWGFSJGDKCCCTLLPSPKJ" + // U+5240
+	"GQJHZZLJPLGJGJJTHJJYJZCJMLZLYQBGJWMLJKXZDZNJQSYZMLJLLJKYWXMKJLHS" + // U+5280
+	"KJGBMCLYYMKXJQLBMCLKMDXXKWYXWSLMLPSJQJCQXYJFJTJDXMXXLLCRQBSYJBGW" + // U+52C0
+	"YWBGGBCYXPJTGPEPFGDJQBHBNSFJYZJKJKHXQBGQZKFHYGKHDGLLSDJJXPQYKYBN" + // U+5300
+	"QSXQNSZSWHBSXWHXWBZZXDMNDJBSBKBBZKLYLXGWXJJWAQZMYWSJQLCJXXJQWJEQ" + // U+5340
+	"XSCWETLZHLYYYSDZPYHYZCPTLSHTZCFYCYXYLJSDCJJAGYSLCLLYYYSGLRQQELDX" + // U+5380
+	"ZSCCCCADYCJYSFSGBFRSSZQSBXJPSGWSDRCKGJLGDKZJZBDKTCSYQPYHSTCLDJLH" + // U+53C0
+	"MXMCGXYZHJDCTMHLTXZXYLYMOHYJCLTYFBQQJBFBDFEHTKSQHZYWWCNXXCDWHHWG" + // U+5400
+	"YJLEGMDQCWGFJHCSNTFYDOLBYGWQWESJPWNMLRYDZSZTXYQPZGCWXANGPYXSHMDQ" + // U+5440
+	"JHZTDPPBFYHZHHJYFDZWKGKZBLDNTSXHQEEGZXYLZMMZYJZGSZXHHKHTXEXXGYLY" + // U+5480
+	"APSTHXDWHZYDPXAGKYDXBHNHXKDFJNMYHYLPMGOCSLNZHKXXLBZZLBMLSFBHHGSG" + // U+54C0
+	"YYGGBHSCYAJTXWLXTZQCWZYDQDQMMGDQLLSZHLSJZWFJHQSWSCELQAZYNYTLSXTH" + // U+5500
+	"AZNKZZSDHLACXTWWCSGQQTDDYZBCCHYQZFLXPSLZYGPZSZNGLYDQCBDLXJTCTAJD" + // U+5540
+	"KYWNSYZLJHHDZCWNYYZYOMHYCHHHXHJKZWSXHDNXLYSCQYDPCLYZWMYPBKXYJLKZ" + // U+5580
+	"HTYHAXQSYSHXASMCHKDSCRSWJPWQSGZJLWWSCHS-HSQNHZSNGNDAQTBAALZZMSST" + // U+55C0
+	"DQJCJKTSCJAXPLGGXHHGOXZCXPDMMHLDGTYBYSJMXHMRCPLXJZCKZXSHFLQXCCDH" + // U+5600
+	"XEZFCHZCCDYTCJYXQHLXDHYPJQXNLSYYDZOZJNHXQEZYSJYAYJKYPDGHDDXSPPYZ" + // U+5640
+	"NDLTHRHXYDPCJJHTCXMCTLHBYNYHMHZLLHNXMYLLLMDCPPXHMXDKYCYRDLTXJCHH" + // U+5680
+	"ZNXCLCCLYLNZSXZJZZLNNLLWHYQSNJHXYNTTDKYJPYCHHYEGKCTTWLGQRLGGTGTY" + // U+56C0
+	"GYHPYHYLQYQGCWYQKFYYYTTTTLHYHLLTYTTSPLKYZWGYWGPYDQQZZDQXSKCQNMJJ" + // U+5700
+	"ZZBXYQMJRTFBBTKHZKBJDJJKDJJTLBWFZPBTKQTZTGPDGNTPJYFALQMKGXBCCLZF" + // U+5740
+	"HZCLLLLADPMXDJHLCCLGYHDZFGYDDGCYYFGYDXKSSEBDHYKDKDKHNAXXYBFBYYHX" + // U+5780
+	"CQGABFQYJJDMLJCSJZLLBCHBSXGJYNDYBYQSPQWJLZKCDDTACCBKZDYZYPJZQSJN" + // U+57C0
+	"KKTKNJDJGYEPGTLFYQKASDNTCYHBLGDZHBBYDMJRYGKZYHEYYBCMCDTYFZJJHGCJ" + // U+5800
+	"PLXHLDWXJJKYTCYKSSSMTWCTTQZLZBSZDTWZXGZAGYKTYWXLHLCPBCLLOQMMZSSL" + // U+5840
+	"CMBJCSZZKYDCZXGQJDSMCYTZQQLWZQZXSSBPKDFQMDDZDSDDTDMFHTDYZJAQJQKY" + // U+5880
+	"PBDJYYXTLJHDRQXXXHAYDHRJLKLYTWHLLRLLRCXYLBWSRSZZSYMKZZHHKYHXKSMZ" + // U+58C0
+	"SYZGCJFBZBSQLFCXXXNXKXWYMSDDYQWGGQMMYHCDZTTFGYYHGSTTTYBYKJDHKYJB" + // U+5900
+	"ELHDYPJQNFXFDYKZHQKZBYJTZBXHFDXBDASWHAWAJLDYJSFHBLDNNDNQJTJNCHXF" + // U+5940
+	"JSRFWHZFMDRFJYHWZPDJKZYJYMFCYZNYNXFBYTFWFWYGDBNZZZDNYTXZEMMQBSQE" + // U+5980
+	"HXFZMBMFLZZSRSYMJGSXWZJSPRYDJSJGXHJJGLJJYNZJJXHGJKYMLPEYYCSYSGQZ" + // U+59C0
+	"SWHWLYRJLPXSLCXMFSMWKCCTNXNYNPNJSZHDZEPTXMWYWAYYSYWLXJQZQXZDCLAE" + // U+5A00
+	"ELMCPJPCLWBXSQHFWRTFFJTNQJHJQDXHWLBYCNFJLALKYYJLDXHHYCSTDYWNCJTX" + // U+5A40
+	"YWDRMDRQHWQCMFJDYZMHMAYXJWMYZQSXTLMRSPWWJHAQBXTGCYPXYYRRCLMPAMGK" + // U+5A80
+	"QJSZYJRMYJSNXTPLNBAPPYPYLXMYZKYNLDGYJZCZHNLMZHHANQMPGWQTZMXXMLLH" + // U+5AC0
+	"GDZXYHXKRXYCJMFFXYHJFSBSSQLHXNDYCANNMTCJCYPRRNYTYCNYYMBMSXNDLYLY" + // U+5B00
+	"SLJNLQYSHQMLLYZLZJJJKYMZCSFBZXXMSTBJGNXYZHLSNMCQSCYZNFZLXBRNNNYL" + // U+5B40
+	"MNRTGZQYSATSWRYHYJZMZDHZGZDWYBSSCSKXSYHYTSXGCQGXZZBHYXJSCRHMKKBS" + // U+5B80
+	"CZJYJYMKQQZJFNBHMQHYSNJNZYBKNQMCJGQHWLSNZSWXKHLJHYYBQCBFCDSXDLDS" + // U+5BC0
+	"PFZFSKJJZWZXSDDXJSEEEGJSCSSMGCLXXKYWYLLYMWWWGYDKZJGGGTGGSYCKNJWN" + // U+5C00
+	"JPCXBJJTQTJWDSSPJXZXNZXWMELPTFSXTLLXCLJXJJLJSXCTNSWXLEDHLYQRWHSY" + // U+5C40
+	"CSQRYBYAYWJEJQFWQCQQCJQGXALDBZZYJGKGXPLTQYFXJLTPADKYQHPMATLCPDHK" + // U+5C80
+	"XMTXYBHBLEFXDLEEGQDYMSAWHZMLJTWYGXLYJZLJEEYXBQQFFNLYXHDSCTGJHXYY" + // U+5CC0
+	"LKLLXQKCCTLHJLQMKKZGCYYGLLLJDZGYDHZWXPYSJBZKDZGYZZHYWYFQYTYZSZYE" + // U+5D00
+	"ZKLYMHJJHTSMQWYZLKYYWZCSRKQYTLTDXWCDRJKLWSQZWBDCQYNCJSRSZJLKCDCD" + // U+5D40
+	"TLZZZACQQCZDDXYPLXCBQJYLZLLLJDDZJGYJYJZYXNYYYNXJXKXDAZWYRDLZYYYR" + // U+5D80
+	"JLGLLDRXJCYKYWNQCCLDDNYYYKYCKCZHJXCCLGZQJGJWPPCQQJYSBZZXYJXJBXJF" + // U+5DC0
+	"ZBSBDSFNSFPZXHDWZTDMPPTBLZZBZDMYYPQJRSDZSQZSQXBDGCPZSWDWCSQZGMDH" + // U+5E00
+	"ZXMWWFYBPDGPHTMJTHZSMMBGZMBZJCFZHFCBBZMQCFMBCMCJXLGPNJBBXGYHYYJG" + // U+5E40
+	"PTZGZMQBQDCGYBJXLWZKYDPDYMGCFTPFXYZTZXDZXTGKMTYBBCLBJASKYTSSQYYM" + // U+5E80
+	"SCXFJEGLSLLSZPQJJJAKLYLDLYCCTSXMCWFGKKBQXLLLLJYXTYLTYXYTDPJHNHGN" + // U+5EC0
+	"KBYQNFJYYZBYYESSESSGDYHFHWTCJBSDZJTFDMXHCNJZYMQWSRXJDZJQPDQBBSDJ" + // U+5F00
+	"GGFBKJBXDGJHMGWJJJGDLLTHZHHYYYYYYSXWTYYYCCBDBPYPZYCCZTJFZYWCBDLF" + // U+5F40
+	"WZCWJDXXHYHLHWCZXJTCZLCDPXDJCZCZLYXJJSJBHFXWPYWXZPTDZZBDCCJHJHML" + // U+5F80
+	"XBQXXBYLRDDGJRRCTTTGQSCZWMXFYTMWZCWJWXJYWCSKYBZQCCTTQNHXNKXXKHKF" + // U+5FC0
+	"HTSWOCCJYBCMPZZYJBNNZPBTHHJDLSCDDYTYFJPXYNGFXBYQXCBHXCBSXTYZDMZY" + // U+6000
+	"SNXSXLHKMZXLTHDHKGHXJSSHQYHHCJYXGLHZXCSNHEKDTGQXQYPKDHEXTYKCNYMY" + // U+6040
+	"YYPKQYYTJXZLTHHQTBYQHXBMYHSQCKWWYLLHCYYLNNEQXQWMCFBDCCMSJGGXDQKT" + // U+6080
+	"LXKGNQCDGZJWYJJLYHHQTTTNWCHHXCXWHESZJYDJCCDBQCDGDNYXZDHCQRXCBMZT" + // U+60C0
+	"QCBXWGQWYYBXHMBYMYKDYECMQKYAQYNGYZSLFYKKQGYSSQYSHJGJCNXKZYCXSBKY" + // U+6100
+	"XHYYLSTYCXQTHYSMGSCPMMGCCCCCMTZTASMGQZJHKLOSQYLSWTMQSYQKDZLJQQYP" + // U+6140
+	"LCYCZTCQQPBBQJZCLPKHQCYYXXDTDDDSJCXFFLLCHQXMJLWCJCXTSPYCXNDTJSHJ" + // U+6180
+	"WXDQQJCKXYAMYLSJHMLALYKXCYYDMAMDQMLMCZNNYYBZKKYFLMCHCMLHXRCJJHSY" + // U+61C0
+	"LNMTJGGZGYWJXSRXCWJGJQHQZDQJDZJJZKJKGDZQGJJYJYLHZXXCDQHHHESTMHLF" + // U+6200
+	"SBDJSYYSHFYSSCZQLPBDRFRZTZDKYKGSCTGKWDQZRKMSYNBCRXQBJYFAXPZZEDZC" + // U+6240
+	"JYKBCJWHYJBQDZYWNYSZPTDKZPFPBAZTKLQYHBBZPTBPTYZZYBHNYDCPJMMCYCQM" + // U+6280
+	"CJFZZDCMNLFPBPLNGQJTBTTAJZPZBBDNJKLJQYLNBZQHKSJZNGGQSCZKYXCHPZSN" + // U+62C0
+	"BCGZKDDZQANZGJKDNTLZLDWJLJZLYWTXNDJZJHXYATNCBGTZCSSKMLJPJYTSRWXC" + // U+6300
+	"FJWJJTKHTZPLBHSNJZSYJBWBZYZLSTLSBJHDWWQPSLMMFBJDWAJYZCCJTBNNRZWX" + // U+6340
+	"XCDSLQGDSDPDZHJTQQPSQLYYJZLGYHSZECTCBJTKTYCZJTQKBPJLGMGZDMCSGPYN" + // U+6380
+	"JZJJYYKNHRPWSZXMTNCSZZYXYBYHYZAXYWKCJTLLCKJJTJHGCXDXYQYCZBYWBLWQ" + // U+63C0
+	"CGLZGJGQRQCCZSSBCRBCSKYDZNLJSQGXSSJMECNSTZTPBDLTHZWHQWQTZEXNQCZG" + // U+6400
+	"WESKSSBYBSTSCSJCCGBFSDQSZLCCGLLLZGHZCTHCNMJGYZAZNMCKCSTJMMZCKBJY" + // U+6440
+	"GQLJYJPPLDXRGZYXCCSNHSHGDZNLZHZJJCDDCBCJFLBFQBCZZWPQDNHXLJCTHQWJ" + // U+6480
+	"GYLNLSZZPCJDSCQQHJQKDXKPBAJYEMSMJTZDXLCJYRYYNWJBNGZZKMJXLTBSLLRT" + // U+64C0
+	"PYLCSZNXJHLLHYLLQQZQLXYMRCYCXSLJMLZLTZLDWDJJLLNZGGQXPSSKYGYGGBFZ" + // U+6500
+	"PDKMWGHCXMCGDXJMCJSDYCABXJDLNBCDDYGSKYDJTXDJJYXMSAQAZDZFSLQXYJSJ" + // U+6540
+	"ZYLBLXXWXQQZBJZLFBBLYLWDSLJHXJYZJWTDJCYFQZQZZDCSXZZQLZCDZFCHYSPY" + // U+6580
+	"MPQZMLPPLFFXJJNZZYLSJYYQZFPFZKSYWJJJHRDJZZXTXXGLGHTDXCSKYSWMMTCW" + // U+65C0
+	"YBAZBJKSHFHGCXMHFQHYXXYZFTSJYZBXYXPZLCHMZMBXHZZSSYFDMNCWDABAZLXK" + // U+6600
+	"TCSHHXKXJJZJSTHYGXSXYYHHHJWXKZXCSBZZWHHHCWTZZZPJXSNXQQJGZYZAWLLC" + // U+6640
+	"WXZFXGYXYHXMKYYSWSQMNJNAYCYSJMJKGWCQHYLAJJMZXHMMCNZHBHXCLXDJPLTX" + // U+6680
+	"YJHDYYLTTXFSZHYXXSJBJYAYRSMXYPLCKDLYHLXRLNLLSTYZYYQYGYHHSCCSMCCT" + // U+66C0
+	"ZCXHYQFPYYRPFFLFQTNTSZLLZMHWTCJQYZWTLLMLMDWMBZSSMZRBPDDDLGJJBXCC" + // U+6700
+	"SRZQQYGWCSXFWZLXCCRBTDZMCYGGDLQSGTJSWLJMYMMSYHFBJDGYXCCPSHXCZCSB" + // U+6740
+	"SJWJGJMPBWAFFYFNXHYDXZYLREMZGZCYZDSZDLLJCSQFNXXKPTXZGXJJGBMYYYSN" + // U+6780
+	"BDYLBNLHBFZDCYFBMGQRRMSSZXYSGTZNNYDZZCDGBJAFJBDKNZBLCSSCPSGZYCJS" + // U+67C0
+	"ZLMLRZZBZZLDLSLLYSXSQZQLYXZLSGKBRXBRBZCYCXZJZEEYFGKLZLYYHGYSGZLF" + // U+6800
+	"JHGTGWKRAAJYZKZQTSSHJJXDZYZ-YJLZYRZDQQHGJZXSSZBTKJPBFRTJXLLFQWJG" + // U+6840
+	"SLQTYMBLPZDXTZAGBDHZZRBGJHWNJTJXLHSCFSMWLLDQYSJTXKZSCFWJLBXFTZLL" + // U+6880
+	"JZLLQBLCQMQQCGCDFPBBHZCZJLPYYGJDTGWDCFCZQYYYQYSRCLQZFKLZZZGFFSQN" + // U+68C0
+	"WGLHJYCJJCZLQZCYJBJZZBPDCCMHJGXDQDGDLZQMFGPZYTSDYFWWDJZJYSXYYCJC" + // U+6900
+	"YHZWPBYHXRYLYBHKJKSFXTZJMMCHHLLTNYYMSXXYZPYJJYCDYZWMTJJKQYRHLLQX" + // U+6940
+	"PSGTLWYCLJSCPXJYZFNMLRGJJTYZBSYZMSJYJHGFZQMSYXRSZCYTLRTQZSSTKXGQ" + // U+6980
+	"GGSPTGXDNJSGCQCQHMXGGZTQYDJKZDLBZSXJLHYQGGGTHQSCPYHJHHGNYGKGGCMJ" + // U+69C0
+	"DZLLCCLXQSFTGZSLLLMLCSKCTBLJZZSZMMNYTPZSXQHJCJYQXYEXZQZCPSHKZZYS" + // U+6A00
+	"XCDFGMWQRLLQXRFZTLYSDCTMJCSJJDHJNXTNRZTZFQRHQGLLGCXSZSJDJLJCYTSJ" + // U+6A40
+	"TLNYXSSZXCGJZYQPYLFHDJSBPCCZGJJJQZJQDYBSSLLCMYTTMQTBHJQNNYGKYNQY" + // U+6A80
+	"QMZGCJKPDCGMYZHQLLSLLCLMHOLZGDYLFZSLJCQZLYLZCJESHNYLLJXGJXLYJYYY" + // U+6AC0
+	"XNBCLJSSWCQQCJYLLCLDJYLLZLLBNYLGQCHXYYQOXCCQKYJXXHYKLKSXAYQCCQKK" + // U+6B00
+	"KKCSGYXXYQXYGWTJOHTHXPXXCSSHCYEYCHZZCBWQBBWJQCSCSZSSLCYLGDESJZMM" + // U+6B40
+	"YMCYTSDSXXSCJPQQSQYLYFZYCHDJDZYWCBTJSYDJHCYDDJLBDJJSODZYQYSQKXXD" + // U+6B80
+	"HHGQJYOHDYXWGMMMAJDYBBBPPBCMHCPLJZSMTXERXJMHQDSTPJDCBSSMSSYTHJTS" + // U+6BC0
+	"LMMTRCPLZSZMLQDSDMJMQPNQDXCFYNBFSDQQYXHYAYKQYDDLQYYYSSZBYDSLNTFG" + // U+6C00
+	"TZQBZMCHDHCZCWFDXTMQQSPHQWWXSRGJCWTJTZZQMGWJJRJHTQJBBGWZFXJHNQFX" + // U+6C40
+	"XQYWYYHYCCDYDHHQMNMDMMCPBSZPPZZGLMZFOLLCFWHMMSJZTTTHLMYFFYTZZGZY" + // U+6C80
+	"SKJJXQYJZQPHMBZZLYGHGFMSHPCFZSNCLPBQSNJSZSLXJFPMTYJYGBXLLDLXPZJY" + // U+6CC0
+	"PJYHHZCYWHJYLSJEXFSSZYWXKZJLLADTMLYMQJPWXXHXSKTQJEZRPXXZGHMHWQPW" + // U+6D00
+	"QLYJJQJJZSZCFHJLCHHNXJLQWZJHBMZYXBDHHYPYLHLHLGFWLCFYYTLHJJCJMSCP" + // U+6D40
+	"XSTKPNHJXSNTYXXTESTJCTLSSLSTDLLLWWYHDHRJZSFGXSSYCZYKWHTDHWJSLHTZ" + // U+6D80
+	"DQDJZXXQGGYLTZPHCSQFZLNJTCLZPFSTPDYNYLGMJLLYCQHYNSBCHYLHQYQTMZYM" + // U+6DC0
+	"BYWRFQYKJSYSLZDQJMPXYYSSRHZJNYQTQDFZBWWDWWRXCWHGYHXMKMYYYHMSMZHN" + // U+6E00
+	"GCEPMLQQMTCWCTMHMXJPJJHFXYYZSJCHTYBMSTSYJDTJJQYTLHYNBYQZLCYCNZWS" + // U+6E40
+	"MYLKFJXLWGXYPJYTYSYLYMZCKTTWLGSMZSYLMPWLCWXWQZSSAQSYXYRHSSNTSRAP" + // U+6E80
+	"CCPWCMGDHHXZDZXFJHGZTTSBJHGYGLZYSMYCLLLXBTYXHBBZJKSSDMALHHYCFYGM" + // U+6EC0
+	"QYPJYCQXJLLLJGCLZGQLYCJCCTOTYXMTMSHLLWCGFXYMZMKLPSZZZXHHJYSLCTYJ" + // U+6F00
+	"CYHXSGYXZKXLZWPYJPDHJWPJPWSQQXLXXDHMRSLZCYZWSTCXKYSTZSHBSCCSTPLW" + // U+6F40
+	"SSCJCHJLCGCHSSPHYLHFHHXJSXYLLNYLMZDHZXYLSXLWZYHCLDYAHZCMDDYSPJTQ" + // U+6F80
+	"JZLNGJFSJSHCTSDSZLBLMSSMNYYMJQBJHRCWTYYDCHJLJAPZWBGQYBKFCMJWLZLL" + // U+6FC0
+	"YYLSZYDWHXPSBCMLJPSCGBHXLQHYRLJXYSWXHXZLLDFHLSLYMJLJYFLYJYCDRJLF" + // U+7000
+	"SYZFSLLCQYQFGQYHYSZLYLMSTDJCYHBZLLNWLXXYGYYHBMGDHXXHHLZZJZXCZZZC" + // U+7040
+	"YQZFNJWPYLCPKPYKPMCLGKDGXZGGWQBDXZZKZFBXDLZXJTPJPTTBYTHZZDWSLCHZ" + // U+7080
+	"HSLTJXHQLHYXXXYWZYSWTMZKHLXZXZPYHGCHKCFSYH-TJRLXFJXPTZTWHPLYXFCR" + // U+70C0
+	"HXSHXKJXXYHZJDXJWYLHYHMJDBFLKHTXCWHCFWJCFPQRXQXCYYYJYGRPXWSCSXNG" + // U+7100
+	"WCHKZDXHFLXXHJJBYZWTSXNNCYJJYMSWZXQRMHXZWFQSYLZJGGBHYXSLBGTTCSEB" + // U+7140
+	"HXXWXYHHXYXNSQYXMLYWRGYQLXBBCLJSYLPSYTJZYHYZAWLHORJMKSCZJXXXYXCH" + // U+7180
+	"CYTRYXQJDDSJFSLYLTSFFYXLMTYJMJJYYYXLTZCSXQCLHZXLWYXZHDNLRXKXJCDY" + // U+71C0
+	"HLBRLMBRLLAXKSLLLJLYXXLYCRYLCJCGJCMTLZLLCYZZPZPCYAWHJJFYBDYYZSEP" + // U+7200
+	"CKZDQYQPBPCJPDCYZBDBBCYYDYCNNPJMTMLRMFMMGWYGBSJGYGSMDQQQZTXMKQWG" + // U+7240
+	"XLLPJGZBQCDJJJFPKJKCXBLJMSWMDTQJXLDLPPBXCWKCQQBFQJCZAGZGMYKBHYYH" + // U+7280
+	"ZYKNDQZMBPJYSPXTHLFPNYYGXJDBKXNHHJHZJXSTRSTLDXSKZYSYBMXJLXYSLBZY" + // U+72C0
+	"SLHXJPFXBQNBYLLJQKYGZMCYZZYMCCSLDLHZGWFWYXZMWCXTYNXJHBYYMCYSBMHY" + // U+7300
+	"SMYDYSHQYZCHMJJMZCAAHCBJBBHPLXTYLSXSDJGJDHKXXTXXNPHNMLNGSLTXMRHN" + // U+7340
+	"LXQJXMZLLYSWQGDLBJHDCGJYQYCMGWFWJYBBBYJMJWJMDPWHXQLDYAPDFXXBCGJS" + // U+7380
+	"PCKRSSYZJMSLBZZJFLJJJLGXZGYXYXLSZQYXBEXYXHGCXBPLDYHWECDWWCJMBTXC" + // U+73C0
+	"HXYQXLLXFLYXLLJLSSFWDPZSMYJCLWSWTCZBCHQEKCQBWLCGYDBLQPPQZQFJQDJH" + // U+7400
+	"YMMCXTXDRMJWRHXCJZCLQXDYYNHYYHRSLSRSYWWZJYMTLTLLGZQCJZYABSCKZCJY" + // U+7440
+	"CCQLYSQXALMZYHYWLWDXZXQDLLQSHGPJFJLJHJABCQZDJGTHHSSTCYJLBSWZLXZX" + // U+7480
+	"RWGLDLZRLZQTGSLLLLZLYMXQGDZHGBDBHZPBRLW-XQBPFDWO--WHLYPCBJCC-DMB" + // U+74C0
+	"ZPBZZ-CYQXLDOMZBLZWPDWYYGDSTTHCSQSCCRSSSYSLFYBFNTYJSZDFNDPTHTZZM" + // U+7500
+	"BQLXLCMYFFGTJJQWFTMDPJWDNLBZCMMCTGBDZEQLPYFHSYMJYLSDCHDZJWJCCTLJ" + // U+7540
+	"CLDTLJJCPDDPJDSSZYNNDBJLGGJZXSXNLYCYBJJQXCBYLZCFZPPGKCXZDZFZTJJF" + // U+7580
+	"JSJXZBNZYJQTTYJWHTYCZHYMDJXTTMPXSFLZCDWSLSHXYBZGTFMLCJTACBBMGDEW" + // U+75C0
+	"YCYZCDSZCYHFLYCTYGWHKJYYLSJCXGYWJCBHLCSNDDBTZBSCLYZCZZSSQDLLMQYY" + // U+7600
+	"HFLLQLLXFDYHABXGGNYWYYPLLSDLDLLBJCYXJZMLHLJDXYYQYTDLLLBBGBFDFBBQ" + // U+7640
+	"JZZMDPJHGCLGMJJPGAEHHBWCQXAXHHHZCHXYPHJAXHLPHJPGPZJQCQZGJJZZGZDM" + // U+7680
+	"QYYBZZPHYHYBWHAZYJHYKFGDPFQSDLZMLJXJPGALXZDAGLMDGXMWZQYTXDXXPFDM" + // U+76C0
+	"MSSYMPFMDMMKXKSYZYSHDZKJSYSMMZZZMSYDNZZCZXBMLSTMDDNMXCKJMZTYYMZM" + // U+7700
+	"ZZMSSHHDCCJEMXXKLJSTGWLSQLYJZLLSJSSDBPMHNLYJCZYHMXXHGZCJMDHXTKGR" + // U+7740
+	"MXFWMCKMWKDCKSXQMMMSZZYDKMSCLCMPCGMHRPXQPZDSSLCXKYXTMLGJYAHZJGZQ" + // U+7780
+	"MCSNXYHMMPMLKJXMHLMLGMXCTKZMJLYSZJSYSZHSYJZJCDAJZYBSDQJZGWZKGXFK" + // U+77C0
+	"DMSDJLFMEHKZQKJBEYPZYSZCDPYJFFMZJYKTTDZZEFMZLBNPPLPLPBPSZALLTYLK" + // U+7800
+	"CKQZKGENQLWAGXXYDPXLHSXQQWQYKXQCLHYXXMLYCCWLYMQYSKYCHLCJNSZKPYZK" + // U+7840
+	"CQZQLJBDMDJHLASQLBYDWQLWDNBQCRYDDDTJYBKBWSZDXDTNPJDTCTQDFXQQMGNS" + // U+7880
+	"ECLSTBHPWSLCTXXLPWYDZKLZQGZCQAPLLKCCYLBQMQCZQCLJSLQZDJXLDTHPZQDL" + // U+78C0
+	"JJXZQDJYZHKZLKCYQDYJPPYPEAKJYRMPCBYMCXKLLZLLFQPYLLLMBSGLZYSSLRSY" + // U+7900
+	"SQTMXYXQQZBDZRYSYZTFFMZZSMZQHZSSCCMLYXWTPZGXZJGZGSJSGKDDHTQGGZLL" + // U+7940
+	"BJDZLCBZHYXYZHZFYWXYZYMSDBZZYJGTSMTFXQYXJSCDGSLNMDLRYTZLRYYLXQHT" + // U+7980
+	"XSRTZCGYXBNQQZFHYKMZJBZYMKBPNLYZPBLMCNQYZZZSJZHJCTZHHYZZJRDYZHNF" + // U+79C0
+	"XKLFXSLKGJTCTSSYLLGZRZBBJZZKLPKBCZYSLXYXBJFPNJZZXCDWXZYJXZZDJJGG" + // U+7A00
+	"GRSRJKMCMZJLSJYWQSHYHQJSXPJZZZLSNSHRNYPJTWCHKLBSRZLCXWJQXQKYSJYC" + // U+7A40
+	"ZTLQZYBBYBWZJQDWGYZCYTJCJXCKCWDKKZXSGKDZXWWYYJQYYTCYTDJLXWKCZKKL" + // U+7A80
+	"CCPZCQQDZLQLCSFQCHQHSFSMQZZLLBJJZBSJHTSJDYSJQJPDSZCDCWJKJZZLPYCG" + // U+7AC0
+	"MZWDJXBSJQZSYZYHHXCBBJYDSSDDZNCGLQMBTSFCBPDZDLZNFGFJGFSMPTJQLMBL" + // U+7B00
+	"GQCYYXBQKDXJQSRFKZTJDHCZKLBSDZCFYTPLLJGJHTXZCSSZZXSTCYGKGCKGYOQX" + // U+7B40
+	"JPLZBBBGTGYJDGCZQSZLBJLSJFZGKQQJCGYCZBZQTLDXRJXBSXXPZXHYZYCLWDSJ" + // U+7B80
+	"JHXMFCZPFZHQHQMQGKSLYHTYCGFRZGNQXCLPDLBZCSCZQLLJBLHBDCYPCZPPDYMT" + // U+7BC0
+	"ZSGYHCKCPZJGSLCLNSCDSLDLXBMSDLDDFJMKDJDHSLZXLSZQPQPGJDLYBDSZLQLB" + // U+7C00
+	"ZLSLKYYHZTTNCJYQTZZFSZQZTLLJTYYLLQLLQYZQLBDZLSLYYZYMDFSZSNHLXZNC" + // U+7C40
+	"ZQZBBWSKRFBCYZCTHBLGJPMCZZLSTLXSHTZCYZLZBLFEQHLXFLCJLYLJQCBZLZJG" + // U+7C80
+	"HSSTBRMHXZHJZCLXFNBGXGTQJCZTMSFZKJMSSNXLJKBHSZXNTNLZDNTLMSJXGZJY" + // U+7CC0
+	"JCZXYHYHWRWWQNZTNFJSCPZSHZJFYRDJSFSCJZBJFZCZCHZLXFXSBZQLZSGYFTZD" + // U+7D00
+	"CSZXZJBQMSZKJRHXJZCGBJKHCHGTJKJQGLXBXFGDRTYLXJXGDTSJXHJZJJCMZLCQ" + // U+7D40
+	"SBTXHQGXTTXHXFTSDKFJHZYJFJXRZCDLLLCQSQQZQWQXSWQTWGWBZCGCLLQZBCLM" + // U+7D80
+	"QQTZGZXZXLJFRMYZFLXYSQXXJKXRMJDCDMMYXBSQBHGCMWFWTGMXLZBYYTGZYCCD" + // U+7DC0
+	"XYZXYWGXYJYZNBGPZJCQSYXCXRTFYCGRHZTXSZZTHCBFCLSYXZLJQMZLMPLMXZJS" + // U+7E00
+	"SFLBYSMYQHXJSXRXSQZZZSSLYFLCZJRCRXHHZXQYDSHXSJJHZCXJBDYNSYSXJBQL" + // U+7E40
+	"PXZQPYMLXZKYXLXCJLCYCRXZZLLDLLLSJYHZXGYJWKJRWYHCPSGNRZLFZWFZZNSX" + // U+7E80
+	"GXFLZSXZZZBFCSYJDBRJKRDHHGXJLJJTGXJXXSTJTJXLYXQFCSGSWMSBCTLQZZWL" + // U+7EC0
+	"ZZKXJMLTMJYHSDDBXGZHDLBMYJFRZFCGCLYJBPMLYSMSXLSZJQQHJZFXGFQFQBPX" + // U+7F00
+	"ZGYYQXGZTCQWYLTLGWWGWHLLFMFGZJMGMGBGTJFSYZZGZYZAFLSSPMLBFLCWBJZC" + // U+7F40
+	"LJJMZLPJJLYMQDMYYYFBGYGQZGLYZDXQYXRQQQHSXYYQQYGJTYXFSFSLLGNQCYGY" + // U+7F80
+	"CWFHCCCFXBYLYPLLZQXXXXXKQHHXSHJDCFDSCZJXCPZWHHHHHAPYLHALPQAFYHXD" + // U+7FC0
+	"YLLKMZQGGGDDESRNNDLTZGCHYBPYSQJJHCLLJTOLNJPZLJLHYMHEYDYDSQYCDDHG" + // U+8000
+	"ZPNDZCLZYWLLZNTEYTGXLHSLPJJBDGWXPCDNTJCKLKCLWKLLCASSTKNZDNQNTTLY" + // U+8040
+	"YZSSYSSZZRYLJQKCGBHHYRXRZYDGRGCWCGZHFFFPPJFZYNAKRGYWYQPQXXFKJTSZ" + // U+8080
+	"ZXSWZDDFBBQTBGTZKZNPZFPZXZPJSZBMQHKCYXYLDKLJNYPKYGHGDCJXXEAHPNZG" + // U+80C0
+	"CTZCMXCXMMJXNKSZQNMNLWBWWXJJYHCLSTMCSQDJCXXTPCNPDTNNPGLLLZCJLSPB" + // U+8100
+	"LPLKCDTNJNLYYRSCFFJFQWDPGZDWMNZCCLODAXNSSNYZRESTYJWJYJDBCFXNMWTT" + // U+8140
+	"BQLWSTSZGYBLJPXGLBOCLGPCBJFTMXZLJYLZXCLTPNCLCGXTFZJSHCRXSFYSZDKN" + // U+8180
+	"TLBYJCYJLLSTGQCBXNWZXBXKLYLHZLQZLNZCQWGZLGZJNCJGCMNZZGJDZXTZJXYC" + // U+81C0
+	"YYCXXJYYXJJXSSSJSTSSTTPPGHTCSXWZDCSYFPTFBCHFBBLZJCLZZDBXGCXLQPXK" + // U+8200
+	"FZFLSYLTYWBMNJHSKBMDDBCYSCCLDXYCDDQLYJJHMQLLCSGLJJSYFPYYCCYLTJAN" + // U+8240
+	"TJJPWYCMMGQYYSQDHQMZHSZXPFTWWZQSWQRFKJLXJQQYFBRXJHHFWJGZYQACMYFR" + // U+8280
+	"HCYYBYQWLPEXCCZSTYRLTSDMQLYKMBBGMYYJPRKNNBBSXYXBHYZDJDNGHPMFSGBW" + // U+82C0
+	"FZMFJMMBCMZDCJJLCNYXYQGMLRYGQCCYHZLWJGCJCGGMCJJFYZZJHYCFRRCMTZQZ" + // U+8300
+	"XHFQGDJXCCJEAQCRJTHPLJLSZDJRBZQHJDYRHXLYXJSYMHZYDWLDFRYHBBYDTSSC" + // U+8340
+	"CWBXGLPZMLZZTQSSCPJMMXJCSJYTYCGHYCJWSNSXLFEMWJNMKLLSWTXHYYYGCMMC" + // U+8380
+	"WJDQDJZGLLJWJNKHPZGGFLCCSCZMCBLTBHBQJXQDJPDJQTGHGLFQAWBZYJJLTSTD" + // U+83C0
+	"HQHCTCBCHFLQMPWDSHYYTQWCNZTJTLBYMBPDYYYXSQKXWYYFLXXNCWCXYBMAELYK" + // U+8400
+	"KJMZZZBRXYAQJFLJPFHHHYTZZXRGQQMHSPGDZJWBWPJHZJDYSCQWZKTHXSQLZYYM" + // U+8440
+	"YSDZGRXCKKHJLWPYSYSCSYZLRMLQSYLJXBCXTLHDQZPCYCYKPPPNSXFYZJJRCEMH" + // U+8480
+	"SZMSXLXGLRWGCSTLRSXBYGBZGZTCPLDJLSLYLYMDTMTCPALCXPQJCJWTCYYZLBLX" + // U+84C0
+	"BZLQMYLJBGHDSLSSDMXMBDCZSXWHAMLCZCPJMCNHJYJNSYGCHSKQMZZQDLLKABLW" + // U+8500
+	"JQSFMOCDXJRRLYQCHJMYBYQLRHETFJZFRFKSRYXFJDWDSXXLWSQJYSLYXWJHSNLX" + // U+8540
+	"YYXHBHAWHHJCXWMYLJCSQLKYDTTXBZSXFDXGXSJHHSXXYBSSXDPWNCMRPTJZCZEN" + // U+8580
+	"YGCXQFJXKJBDMLJCMQQXLOXSLYXXLYLLJDZBTYMHBFSTTQQWLHOGYBLSCALZXQLH" + // U+85C0
+	"TWRRQHLSTMYPYXJJXMQSJFNBRYXYJLLYQYLTWYLQYFMHKLJDMLLHFZWKZHLJMLHL" + // U+8600
+	"JKLJSTLQXYLMBHHLNLSXQCHXCFXXLHYHJJGBYZZKBXSCQDJQDSXJZSYHZHHMGSXC" + // U+8640
+	"SYMXFEBCQWWRBPYYJQTYQCYJHQQZYHMWFFHGZFRJFCDBXNTQYZPCYHHJLFRZGPPX" + // U+8680
+	"ZDBBGZQSTLGDGYLCQMGCHHMFYWLZYXKJLYPQHSYWMQQGQZMLZJNSQXJQSYJTCBEH" + // U+86C0
+	"SXFSSFXZWFLLBCYYJDYTDTHWZSFJMQQYJLMQSXLLDTTKHHYBFPWDYYSQQRNQWLGW" + // U+8700
+	"DEBDWCYYGCDLKJXTMXMYJSXHYBRWFYMWFRXYQMXYSCTZZTFYKMLDHQDLWYQNLCRY" + // U+8740
+	"JBLPSXCXYWLSBRRJWXHQYBHTYDNHHGMMYWYTZCSQMTSSCCDALWZTCPQPYJLLQZYJ" + // U+8780
+	"SWXWZZMMGLMXCLMXCZMXMZSQTZPPJQBLPGXJZHFLJJHYCJSNXWCXSCCDLXSYJDCQ" + // U+87C0
+	"CXSLQYCLZXLZZXMXQRJMHRHZJPHMFLJLMLCLQNLDXZLLLFYBNGJYSXCQQDCMQJZZ" + // U+8800
+	"XHNPNXZMEKMXXYKYQLXSXTXJXYHWDCWDZHQYYBGYBCYSCFGFSJNZDYZZJZXRZRQJ" + // U+8840
+	"JYMCANHRJTLDBPYZBSTJHXXZYPBDWFGZZRPYMTNGXZQBGXNBBFCCKRJJJBJEGRZG" + // U+8880
+	"YCLKXZDXKKNSJKCLJSPGYYZLQQJYBZSSQLLLKJFCBKTYLCCCDBLSPPFYLGYDTZJY" + // U+88C0
+	"JZGKQTTFCXBDKDXXHYBBFYTYHBCLPDYTGDHRYRNJSBTCSNYJQHKLLLZSLYDXXWBC" + // U+8900
+	"JQSBXBFJZJCJDZFBXXBRMLAZGCSNCLBJDSTBLPRZDSWSBXBCLLXXLZDJZSJPYLYX" + // U+8940
+	"XYFTFFFBHJJJGBYGJPMMMMSSCLJMTLYZJXSWXTYLEDQPJMYGQZJGDJLQJWJQLLSD" + // U+8980
+	"GJGYGMSCLJJXDTYGJQJQJCJZCJGDZDSHQGSJGGCJHQXSNJLZZBXHSGZXCXYLJXYX" + // U+89C0
+	"YYDFQQJHJFXDHCTXJYRXYSQTJXYEFYYSSYXJXNCYZXFXCSXSZXYYSCHSHXZZZGZZ" + // U+8A00
+	"ZGFJDLDYLNPZGYJYZYYQZPBXQBDZTZCZYXXYHHSCXSHCGGQHJHGXWSZTMZMEHYXG" + // U+8A40
+	"EBTYLZKKWYTJZRCLEKESTDBCYKQQSAYXCJXWWGSBHJSZSDHCSJKQCXSWXFCTYNYD" + // U+8A80
+	"PZCCZJQTZWJQDZZZQZLJCHLSBHPYDXPSXSHHEZDXFPTJQYZZXHYAXNCFZYYHXGNQ" + // U+8AC0
+	"MYWXTZSJPKHHGYMXMXQCXTSBCQSJYXHTYYZYBCQLMMSZMJZJLLCOGXZAAJZYHJMC" + // U+8B00
+	"HHCXZSXZDZNLEYJJZJBHZWZZSQTZPSXZTDSXJJJZNYAZPHHYYSRNQZTHZHAYJYJH" + // U+8B40
+	"DZXZLSWCLYBZYECWCYCRYLCXNHZYDZYDYJDFRJJHTRSQTXYXJRJHOJYNXELXSFSF" + // U+8B80
+	"JZGHPZSXZSZDZCQZBYYKLSGSJHCZSHDGQGXYZGXCHXZJWYQWGYHKSSEQZZNDZFKW" + // U+8BC0
+	"YSSDCLZSTSYMCDHJXXYWEYXCZAYDMPXMDSXYBSQMJMZJMTZQLPJYQZCGQHXJHHHX" + // U+8C00
+	"XHLHDLDJQSLDWBSXFZZYYSCHTYTYJBHECXHJKGJFXBHYZJFXBWHBDZFYZBCAPNPG" + // U+8C40
+	"NYDMSXHKHHMHMLNBYJTMPXEJMCTHJBZYFCGTYHWPHFTGZZEZSBZEGPBMDSKFTYCM" + // U+8C80
+	"HBLLHGPZJXZJGZJYXZSBBQSCZZLZCCSTPGXMJSFTCCZJZDJXCYBZLFCJSYZFGSZL" + // U+8CC0
+	"YBCWZZBYZDZYPSWYJGXZBDSYSXLGZBZFYGCZXBZHZFTPBGZGEJBSTGKDMFHYZZJH" + // U+8D00
+	"ZLLZZGJQZLSFDJSSCBZGPDLFZFZSZYZYZSYGCXSNTXCHCZXTZZLJFZGQSQYXCJQC" + // U+8D40
+	"CCCDJCDXZJYQJCCGXZTDLGSCXZSYJJQTCCLQDQZTQCHQQJZTEZZZPBKKDJFCJFZT" + // U+8D80
+	"YBQYQTTYNLMBDKTJCPQZJDZFPJSBNJLGYJDXJDZQKZGQKXCLPZJTCJTQBXDJJJST" + // U+8DC0
+	"CJNXBXCMSLYJCQMTJQWWCJJNJJLLLHJCWQTBZQYCZCZPZZDZYDDCYZDZCCJGTJFZ" + // U+8E00
+	"DPRNTCTJDCQTQNDTJNPLZBCLLCTDSXKJZQDPZLBZNBTJDCXFCZDBCCJJLTQJPLDC" + // U+8E40
+	"KZDBBZJCQDCJWYNLLZLZCCDWLLXWZLXRSNTQJCCXKJLSGDFQTDDGLRLAJJTKLYMK" + // U+8E80
+	"QLLDZYTDYYCYGJWYXDXFRSKSTCDENQMRRQZHHQKDLDAZFKYPBGGPZREBZZYKYZSP" + // U+8EC0
+	"EGJJGHKQZZZSLYSYWYZWFQZNLZZLZHWCGKYPQGNPGBLPLRRJYXCCCGYHSFZFWBZY" + // U+8F00
+	"WTGZXYLJCZWHXZJZBLFFLGSKHYJZEYJHLPLLLLCYGXDRZELRHGKLZZYHZLYQSZZJ" + // U+8F40
+	"ZQLJZFLNBHGWLCZCFJWSPYXNLZLXGCCPZBLLCXBBBBXBBCBBCRNNCCCYRBBSRLDC" + // U+8F80
+	"GQYYQXYGMQZWTZYTYJHYFWDEHZZJYWLCCNTZYJJCDEDPZDZTSTQJHDYMBJNYJZLX" + // U+8FC0
+	"TSSTPHNDJXXBYXQTZQDDTJTDYZTGWSCSZQFLSHLGLBCJBHDLYZJYCKWTYDYLBNYD" + // U+9000
+	"SDSYCCTYSZYYEBGEXHQDDWNYGYCLXTDCYSTQMYGZASCCSZZDDLCCLZRQXYYWLJSB" + // U+9040
+	"YMXSHZTEMBBLLYYLLYTDQYSHYMRQWKFKBFXNXSBYCHXBWJYHTQBPBSBWDZYLKGZS" + // U+9080
+	"KYGHQZJHHXJXGNLJKZLYYCDXLFWFGHLJGJYBXBLYBXQPQGZTZPLNCYBXDJYQYDYM" + // U+90C0
+	"RBESJYYHKXXSTMXRCZZYWXYHYBMCFLYZHQYZMQXDBXBZWZMSLPDMYCKFMZKLZCYJ" + // U+9100
+	"YCCLHXFZLYDQZPZYGYJYZMZXDZFYFYTTQTCHGSFCZMLCCYTZXJCYTJMKSLPZHYSN" + // U+9140
+	"WLLYTPZCTZZCKTXDHXXTQCYPKSMQCCYYAZHTJPCYLZLYJBJXTFNYLJYYNRXCYLMM" + // U+9180
+	"NXJSMYBCSYSSLZYLLJJQYLDZDPQBFZZBLFNDSQKCZFHHHGQMRDSXYCSTXNQQJPYJ" + // U+91C0
+	"BFCXDYQFPNXEJDGYQBSRCNFYJQPGHYJSYZXGRHTKYLEWDZNTSMGKLBSGBPYSZBYT" + // U+9200
+	"JZSSZJCSSXZBHBSCSBZCZPTQFZLQFLYPYBBJGSZMXXDJMTHYSKKBJTXHJCELBSMJ" + // U+9240
+	"YJZCXTMLJYXRZZQSCXXQPTZXMKYXXXJCLJPRMYYGADYSKQLSADHRSKQXZXZTCGHZ" + // U+9280
+	"TLMLWXYBWSYCDBHJHCFCWZSXHYTGZLXQSHLYCZJXTMPLPRCGLTBZZTLZJCYJGDTC" + // U+92C0
+	"LGLBLLQPJMZPAPXYZLKKTKDNCZZBNZCTDQQZJYJGMCTXLTGCSZLMLHBGLKFWNWZH" + // U+9300
+	"DXPHLFMKYDLGXDTWZFRJEJCTZHYDXYKXHWFZCQSHKTMQQHTCHYMJDJSKHXDJZBZZ" + // U+9340
+	"XYMPAJQMSDBXLSKLYYNWRTSQLSCBPDBSGZWYHTLKSSSWHZZLYYTNXJGMJSZSXFWN" + // U+9380
+	"LSOZTXGXLSAMMLBWLDSZYLAKQCQCTMYCFJBSLXCLZJCLXXKSBZQCLHJPHQPLSXSC" + // U+93C0
+	"KSLNHPSFQQYTXJJZLQLDXZJJZDYYDJNZPTFZDSKJFSLJHYLZQJZLBTHYDGDJFDBY" + // U+9400
+	"AZXDZHZJNHHQBYKNXJJQCZMLLJZKSPLDSCLBBLXKLELXJLBJYCXJXGCNLCQPLZLZ" + // U+9440
+	"NJTSLJGYZDZPLTQCSJFDMNYCXGBTJDCZNBGBQYQJWGKFHTNBYQZQGBEPBBYZMTJD" + // U+9480
+	"YTBLSQMBSXTBNPDXKLEMYYCJYNZDTLDYKZZXDDXHQSHDGMZSJYCCTAYRZLPWLTLK" + // U+94C0
+	"XSLZCGGEXCLFXLKJRTLQJAQZNCMBQDKKCXGLCZJZXJHPTDJJMZQYKQSECQZDSHHA" + // U+9500
+	"DMLZFMMZBGNTJNNLGBYJBRBTMLBYJDZXLCJLPLDLPCQDHLHZLYCBLCXZCJADQLMZ" + // U+9540
+	"MMSSHMYBHBSKKBHRSXXJMXSDZNZPXLBBRAGGGFCHGMSKLLTSJYYCQLCSKYWYEHYW" + // U+9580
+	"XBHQYWBAWYKQLDQFTNTKHQCGDQKTGPKXHCPDHTWTMSSYHBWCRWXHJMKMZNGWTMLK" + // U+95C0
+	"FGHKJYLDYYCXWHYECLQHKQHTDQHHFFLDXQWGZYYDESBPKYRZPJFYYZJCEQDZZDLA" + // U+9600
+	"TTBBFJLLCXDLMJSDXEGYGSJQXCFBXSSZPDYZCXDNYXPFZYDLYJCCPLTXLSXYZYRX" + // U+9640
+	"CYYSDYLWWNDSAHJSYGYHGYWKAXTJZDAXYSRLTDJSSAXFNEJDXYEHLXLLLZHZSJNY" + // U+9680
+	"QYQQXYJGHZGJCYJCHZLYCDSHWSGCZYJXCLLNXZJJYYXNFSMWFPYLCYLLABWDDHWD" + // U+96C0
+	"XJMCXZTZPMLQZHSFHZYNZTLLDYWLSLXHYMMYLMBWWKYXYADTSYLLDJPYBPWFXJMM" + // U+9700
+	"MLLHAFDLLAFLBHHHBQQJTZJCQJJDJTFFKMMMBYTHYGDCQRDDWRQJXNBYSNMZDBYY" + // U+9740
+	"TBJHPYBYGTJXAAHGQDQTMYSTQXKBTSBKJLXRBEQQHXMJJBDJWTGTBXPGBKTLGQXJ" + // U+9780
+	"JJCDHXQDWJLWRFMQGWQHCKRYSWGBTGYGBWSDWDWRFHWYTJJXXXJYZYSLPHYYPAYX" + // U+97C0
+	"HYDQKXSHXYXESKQHYWBDDDPPLCJLHQEEWXKSYSHDYPLFJTHKJLTCYYHHJTTPLTZZ" + // U+9800
+	"CDLTHQKCXQYSTEEYWKYZYXXYYSDDJKLLPWMCYHQGXYHCRMBXPLLNQYDQHXSXXWGD" + // U+9840
+	"QBSHYLLPJJJTHYJKYPHTHYYKTYEZYENMDSHLCRPQFBGFXZBSBTLGXSJBSWYYSKSF" + // U+9880
+	"LXLPPLBBBLBSFXFYZBSJSSYLPBBFFFFSSCJDSTZSXTRYJCYFFSYTYZBJTLCTSBSD" + // U+98C0
+	"HRTJJBYTCXYJEYLXCBNEBJDSYSYHGSJZBXBYTFZWGENYHHTHJHATFWGCSTBGXKLS" + // U+9900
+	"TYYMTMBYXJSKZSCDYJRCYTWXZFHMYMCXLZNSDJTTTXRYCFYJSBSDYERXHLJXBBDE" + // U+9940
+	"YNJGHXGCKGSCYMBLXJMSZNSKGXFBNBBTHFJAAFXYXFPXMYFHDTZCXZZPXRSYWZDL" + // U+9980
+	"YBBJTYQPQJPZYPZJZNJPZJLZTFYSBTTSLMPTZRTDXQSJEHBZYLZDXLJSQMLHTXTJ" + // U+99C0
+	"ECXALZZSPKTLZKQQYFSYGYWPCPQFHQHYTQXZKRSGTGSQCZLPTXCDYYZSSLZSLXLZ" + // U+9A00
+	"MACBCQBZYXHBSXLZDLTCDJTYLZJYYTPZYLLTXJSJXHLBMYTXCQRBLZSSFJZZTNJY" + // U+9A40
+	"DXMYJHLHPBLCYXQJQQKZZSCPZKSWALQSBLCCZJSXGWWWYGYATJBBCTDKHQHKGTGP" + // U+9A80
+	"BKQYSLBXBBCKBMLLXDZSTBKLGGQKQLSBKKDFXRMDKBFTPZFRTBBMFERQGXKJPZSS" + // U+9AC0
+	"TLBZDPSZQZSJTHLJQLZBPMSMMSXLQQNHKNBLRDDNHXDHDDJCYYGYFQGZLGSYGMJQ" + // U+9B00
+	"GKHBPMXYXLYTQWLWGCPBMJXCYZYDRJBHTDJXEESHTMJSBYPLWHLZFFNYPMHXQHPL" + // U+9B40
+	"TBQPFBCWJDBYGPNXTBFZJGSDDTJSHXEAWZZYLLTTYBWJKGXGHLFKXDJTMSZSQYNZ" + // U+9B80
+	"GGSWQSPHTLSSKMCLZXYNZQZXNCJDQGZDLFNYKLJCJLLZLMZZNHYDSSHTHXZLZZBB" + // U+9BC0
+	"HQZWWYCRDHLYQQJBEYFSGXTHSRXWQHWFSLMSSGZTTYEYQQWRSLALHMJTQJSMXQBJ" + // U+9C00
+	"JZJXZYZKXBYQXBJXSHZSSFGLXMXZXFGHKZSZGGYLCLSARJXHSLLLMZXELGLXYDJY" + // U+9C40
+	"TLFBHBPNLYZFBBHPTGJKWETZHKJJXZXXGLLJLSTGSHJJYQLQZFKCGNNDJSSZFDBC" + // U+9C80
+	"TWWSEQFHQJBSAQTGYPJLBXBMMYWXGSLZHGLZGNYFLJBYFDJFRGSFMBYZHQFBWJSY" + // U+9CC0
+	"FYJJPHZBYYZFFWODGRLMFTMLBZGYCQXCDJYGDYYRYTYTYDWEGAZYHXJLZYTHLRMG" + // U+9D00
+	"RJXZZLHNELJJTHTBWJYBJXBXJJTJTEEKHWSLJPLPSFAZPQQBDLQJJTYYQLYZKDKS" + // U+9D40
+	"QJYYJZLDQCGJJYZJSYCMRAQTHTEJMFCTYHYPKMHYCWJDCFHYYXWSHCTXRLJGJSHC" + // U+9D80
+	"CYYYJLTKTTYTMJGTCJTZAYYOCZLYLBSZYWJYTSJYHBYSHFJLYGJXXTMZYYLTXXYP" + // U+9DC0
+	"CLXYJZYZYYPNHMYMDYYLBLHLSYYGQLLNJJYMSOYCBZGDLYXYLCQYXTSZEGXHZGLH" + // U+9E00
+	"WBLJGEYXTWQMAKBPQCGYSHHEGQCMWYYWLJYJHYYZLLJJYLHZYHMGSLJLJXCJJYCL" + // U+9E40
+	"YCJPCPZJZJMMYLCJLNQLJJJLXXJMLSZLJQLYCMMHCFMMFPQQMFXLQMCFFQMMMMHM" + // U+9E80
+	"ZNFHHJGTTHHKHSLNCHHYQDXTMMQDCYDYXYQMYQYLDDCYYYDAZDCYMZYDLZFFFMMY" + // U+9EC0
+	"CQCWZZMABTBYCTDMNDZGGDFTYPCGQYTTSSFFWBDTZQSSYSTWNJHJYTSXXYLBYQHW" + // U+9F00
+	"WHXEZXWZNNQZJZJJQJCCCHYYXBZXCCYJTLLCQXKNJYCKYCYNZZQYYOEWYCZDCJYC" + // U+9F40
+	"CHYJLBTZKYCQWLPGPYLLGKDLDLGKGQBGYCHJXY--------------------------" + // U+9F80
+	"----------------------------------------------------------------" // U+9FC0
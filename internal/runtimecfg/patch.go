@@ -29,6 +29,13 @@ type Patch struct {
 	BoardTrendGapMS                     *int    `json:"board_trend_gap_ms,omitempty"`
 	BoardTrendAfterCloseMode            *string `json:"board_trend_after_close_mode,omitempty"`
 	BoardTrendAfterCloseIntervalSeconds *int    `json:"board_trend_after_close_interval_seconds,omitempty"`
+
+	AuctionEnabled         *bool `json:"auction_enabled,omitempty"`
+	AuctionIntervalSeconds *int  `json:"auction_interval_seconds,omitempty"`
+	AuctionTopSize         *int  `json:"auction_top_size,omitempty"`
+
+	DailyJobEnabled *bool   `json:"daily_job_enabled,omitempty"`
+	DailyJobRunAt   *string `json:"daily_job_run_at,omitempty"`
 }
 
 func (p Patch) Apply(cfg *config.Config) {
@@ -90,4 +97,21 @@ func (p Patch) Apply(cfg *config.Config) {
 	if p.BoardTrendAfterCloseIntervalSeconds != nil {
 		cfg.BoardTrend.AfterCloseIntervalSeconds = *p.BoardTrendAfterCloseIntervalSeconds
 	}
+
+	if p.AuctionEnabled != nil {
+		cfg.Auction.Enabled = *p.AuctionEnabled
+	}
+	if p.AuctionIntervalSeconds != nil {
+		cfg.Auction.IntervalSeconds = *p.AuctionIntervalSeconds
+	}
+	if p.AuctionTopSize != nil {
+		cfg.Auction.TopSize = *p.AuctionTopSize
+	}
+
+	if p.DailyJobEnabled != nil {
+		cfg.DailyJob.Enabled = p.DailyJobEnabled
+	}
+	if p.DailyJobRunAt != nil {
+		cfg.DailyJob.RunAt = *p.DailyJobRunAt
+	}
 }
@@ -0,0 +1,195 @@
+// Package postgres runs the queries of internal/store/sqlite against PostgreSQL (or TimescaleDB,
+// which is used as plain Postgres). Those queries stick to SQL both databases accept; the
+// differences left are bridged here: "?" placeholders are rewritten to "$n" by a wrapping
+// database/sql driver, and the SQLite migrations are translated to Postgres column types.
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/stdlib"
+
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/store/sqlite"
+)
+
+const driverName = "aof-postgres"
+
+func init() {
+	sql.Register(driverName, rebindDriver{stdlib.GetDefaultDriver()})
+}
+
+// Open connects to dsn (a postgres:// URL or key=value string). Unlike SQLite, several
+// connections are kept so concurrent web queries don't queue behind the persist loop.
+func Open(dsn string) (*sql.DB, error) {
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(8)
+	db.SetMaxIdleConns(4)
+	return db, nil
+}
+
+// Migrate applies pending migrations (the SQLite list, translated by ddl).
+func Migrate(db *sql.DB) error {
+	return sqlite.RunMigrations(db, migrations(), nil)
+}
+
+// MigrationStatus lists every known migration with its applied time.
+func MigrationStatus(db *sql.DB) ([]sqlite.MigrationState, error) {
+	return sqlite.MigrationStatusOf(db, migrations(), nil)
+}
+
+func migrations() []sqlite.Migration {
+	ms := sqlite.Migrations()
+	for i, m := range ms {
+		stmts := make([]string, len(m.Stmts))
+		for j, s := range m.Stmts {
+			stmts[j] = ddl(s)
+		}
+		ms[i].Stmts = stmts
+	}
+	return ms
+}
+
+var (
+	reAutoincrement = regexp.MustCompile(`\bINTEGER PRIMARY KEY AUTOINCREMENT\b`)
+	reReal          = regexp.MustCompile(`\bREAL\b`)
+	reInteger       = regexp.MustCompile(`\bINTEGER\b`)
+)
+
+// ddl translates a SQLite schema statement: REAL is 4 bytes in Postgres, so it becomes
+// DOUBLE PRECISION, INTEGER widens to BIGINT, and AUTOINCREMENT keys become identity columns.
+// Timestamps stay TEXT so fixed-width RFC3339Nano comparisons behave the same on both backends.
+func ddl(stmt string) string {
+	stmt = reAutoincrement.ReplaceAllString(stmt, "BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY")
+	stmt = reReal.ReplaceAllString(stmt, "DOUBLE PRECISION")
+	return reInteger.ReplaceAllString(stmt, "BIGINT")
+}
+
+// rebind rewrites "?" placeholders to "$1", "$2", ... and numbered "?N" ones to "$N",
+// leaving string literals, quoted identifiers and comments alone.
+func rebind(query string) string {
+	if !strings.Contains(query, "?") {
+		return query
+	}
+	var b strings.Builder
+	b.Grow(len(query) + 16)
+	n := 0
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"':
+			j := strings.IndexByte(query[i+1:], c)
+			if j < 0 {
+				b.WriteString(query[i:])
+				return b.String()
+			}
+			b.WriteString(query[i : i+j+2])
+			i += j + 1
+		case c == '-' && i+1 < len(query) && query[i+1] == '-':
+			j := strings.IndexByte(query[i:], '\n')
+			if j < 0 {
+				b.WriteString(query[i:])
+				return b.String()
+			}
+			b.WriteString(query[i : i+j+1])
+			i += j
+		case c == '?':
+			j := i + 1
+			for j < len(query) && query[j] >= '0' && query[j] <= '9' {
+				j++
+			}
+			b.WriteByte('$')
+			if j > i+1 {
+				b.WriteString(query[i+1 : j])
+				i = j - 1
+			} else {
+				n++
+				b.WriteString(strconv.Itoa(n))
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// rebindDriver wraps the pgx driver and rebinds every query text before it reaches it.
+type rebindDriver struct{ driver.Driver }
+
+func (d rebindDriver) Open(name string) (driver.Conn, error) {
+	c, err := d.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return rebindConn{c}, nil
+}
+
+// rebindConn forwards the optional driver interfaces pgx implements.
+type rebindConn struct{ driver.Conn }
+
+func (c rebindConn) Prepare(query string) (driver.Stmt, error) {
+	return c.Conn.Prepare(rebind(query))
+}
+
+func (c rebindConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if p, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return p.PrepareContext(ctx, rebind(query))
+	}
+	return c.Conn.Prepare(rebind(query))
+}
+
+func (c rebindConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if e, ok := c.Conn.(driver.ExecerContext); ok {
+		return e.ExecContext(ctx, rebind(query), args)
+	}
+	return nil, driver.ErrSkip
+}
+
+func (c rebindConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if q, ok := c.Conn.(driver.QueryerContext); ok {
+		return q.QueryContext(ctx, rebind(query), args)
+	}
+	return nil, driver.ErrSkip
+}
+
+func (c rebindConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if b, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return b.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+func (c rebindConn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+func (c rebindConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if ch, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return ch.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+func (c rebindConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c rebindConn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
@@ -0,0 +1,435 @@
+package sqlite
+
+import (
+	"bufio"
+	"compress/gzip"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Archive layout: <dir>/<trade_date>/<table>.jsonl.gz plus <dir>/<trade_date>/manifest.json.
+// Each line is one row as a JSON object keyed by column name. A table archived again for the same
+// date (cleanup cutoffs aren't aligned to trade dates, and a delete can fail after the export) is
+// merged: the day file is rewritten with the new rows replacing archived rows of the same primary
+// key, so repeating an export never duplicates rows.
+
+// ArchiveManifest describes one trade date directory.
+type ArchiveManifest struct {
+	TradeDate string                  `json:"trade_date"`
+	Tables    map[string]ArchiveTable `json:"tables"`
+}
+
+type ArchiveTable struct {
+	File      string `json:"file"`
+	Rows      int    `json:"rows"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+const archiveManifestName = "manifest.json"
+
+// archiveSpec is how cleanup exports one table: the column that dates a row (UTC timestamp
+// columns are bucketed by their Asia/Shanghai date) and the primary key used to merge re-exports.
+type archiveSpec struct {
+	col string
+	key []string
+}
+
+// archivableTables are the tables cleanup may export.
+var archivableTables = map[string]archiveSpec{
+	"northbound_rt":    {"ts_utc", []string{"ts_utc"}},
+	"fundflow_rt":      {"ts_utc", []string{"ts_utc", "code"}},
+	"toplist_rt":       {"ts_utc", []string{"ts_utc", "fid", "rank"}},
+	"board_rt":         {"ts_utc", []string{"ts_utc", "board_type", "fid", "code"}},
+	"market_agg_rt":    {"ts_utc", []string{"ts_utc", "source", "fid"}},
+	"auction_rt":       {"ts_utc", []string{"ts_utc", "source", "code"}},
+	"fundflow_delta":   {"ts_utc", []string{"ts_utc", "code"}},
+	"rt_bars_1m":       {"bar_utc", []string{"dataset", "key", "field", "bar_utc"}},
+	"rt_bars_5m":       {"bar_utc", []string{"dataset", "key", "field", "bar_utc"}},
+	"northbound_daily": {"trade_date", []string{"trade_date"}},
+	"fundflow_daily":   {"trade_date", []string{"trade_date", "secid"}},
+	"board_daily":      {"trade_date", []string{"trade_date", "board_type", "fid", "code"}},
+	"market_agg_daily": {"trade_date", []string{"trade_date", "source", "fid"}},
+	"margin_daily":     {"trade_date", []string{"trade_date", "code"}},
+}
+
+// archiveBefore exports the rows of table whose date column is < cutoff into dir, reading them
+// from src (table itself, or one of its day partitions). It returns the number of rows written.
+// Nothing is committed to dir unless every row was exported.
+func archiveBefore(db *sql.DB, dir, table, src, cutoff string) (int, error) {
+	spec, ok := archivableTables[table]
+	if !ok {
+		return 0, fmt.Errorf("table %q is not archivable", table)
+	}
+	col := spec.col
+	rows, err := db.Query(fmt.Sprintf(`SELECT * FROM %s WHERE %s < ? ORDER BY %s`, src, col, col), cutoff)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+	dateIdx := -1
+	for i, c := range cols {
+		if c == col {
+			dateIdx = i
+		}
+	}
+
+	loc, _ := time.LoadLocation("Asia/Shanghai")
+	writers := make(map[string]*archiveWriter)
+	abortAll := func() {
+		for _, w := range writers {
+			w.abort()
+		}
+	}
+
+	vals := make([]any, len(cols))
+	ptrs := make([]any, len(cols))
+	for i := range vals {
+		ptrs[i] = &vals[i]
+	}
+	total := 0
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			abortAll()
+			return 0, err
+		}
+		obj := make(map[string]any, len(cols))
+		for i, c := range cols {
+			if b, ok := vals[i].([]byte); ok {
+				obj[c] = string(b)
+			} else {
+				obj[c] = vals[i]
+			}
+		}
+		date := fmt.Sprint(obj[cols[dateIdx]])
+		if col != "trade_date" {
+			if t, err := time.Parse(time.RFC3339Nano, date); err == nil {
+				date = t.In(loc).Format("2006-01-02")
+			}
+		}
+		w, ok := writers[date]
+		if !ok {
+			w, err = openArchiveWriter(dir, date, table)
+			if err != nil {
+				abortAll()
+				return 0, err
+			}
+			writers[date] = w
+		}
+		if err := w.write(obj); err != nil {
+			abortAll()
+			return 0, err
+		}
+		total++
+	}
+	if err := rows.Err(); err != nil {
+		abortAll()
+		return 0, err
+	}
+	dates := make([]string, 0, len(writers))
+	for date := range writers {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+	for i, date := range dates {
+		if err := writers[date].commit(); err != nil {
+			for _, d := range dates[i+1:] {
+				writers[d].abort()
+			}
+			return 0, err
+		}
+	}
+	return total, nil
+}
+
+// archiveWriter writes the rows of one table and date to a temporary file; commit merges in the
+// rows already archived under other keys and replaces the day file.
+type archiveWriter struct {
+	dir, date, table string
+	key              []string
+
+	f    *os.File
+	buf  *bufio.Writer
+	gz   *gzip.Writer
+	enc  *json.Encoder
+	keys map[string]struct{}
+	rows int
+}
+
+func openArchiveWriter(dir, date, table string) (*archiveWriter, error) {
+	if err := os.MkdirAll(filepath.Join(dir, date), 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.Create(filepath.Join(dir, date, table+".jsonl.gz.tmp"))
+	if err != nil {
+		return nil, err
+	}
+	buf := bufio.NewWriter(f)
+	gz := gzip.NewWriter(buf)
+	return &archiveWriter{
+		dir: dir, date: date, table: table, key: archivableTables[table].key,
+		f: f, buf: buf, gz: gz, enc: json.NewEncoder(gz), keys: make(map[string]struct{}),
+	}, nil
+}
+
+func (w *archiveWriter) rowKey(obj map[string]any) string {
+	parts := make([]string, len(w.key))
+	for i, c := range w.key {
+		parts[i] = fmt.Sprint(obj[c])
+	}
+	return strings.Join(parts, "\x00")
+}
+
+func (w *archiveWriter) write(obj map[string]any) error {
+	k := w.rowKey(obj)
+	if _, dup := w.keys[k]; dup {
+		return nil
+	}
+	if err := w.enc.Encode(obj); err != nil {
+		return err
+	}
+	w.keys[k] = struct{}{}
+	w.rows++
+	return nil
+}
+
+func (w *archiveWriter) path() string {
+	return filepath.Join(w.dir, w.date, w.table+".jsonl.gz")
+}
+
+// mergeArchived copies the rows of the existing day file whose key wasn't written again.
+func (w *archiveWriter) mergeArchived() error {
+	f, err := os.Open(w.path())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(bufio.NewReader(f)) // reads all members, including older appended ones
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+	dec := json.NewDecoder(gz)
+	dec.UseNumber()
+	for dec.More() {
+		var obj map[string]any
+		if err := dec.Decode(&obj); err != nil {
+			return fmt.Errorf("%s: %w", w.path(), err)
+		}
+		if err := w.write(obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *archiveWriter) commit() error {
+	err := w.mergeArchived()
+	if cerr := w.closeFile(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(w.f.Name(), w.path())
+	}
+	if err != nil {
+		os.Remove(w.f.Name())
+		return err
+	}
+	return writeArchiveManifest(w.dir, w.date, w.table, w.rows)
+}
+
+func (w *archiveWriter) abort() {
+	w.closeFile()
+	os.Remove(w.f.Name())
+}
+
+func (w *archiveWriter) closeFile() error {
+	err := w.gz.Close()
+	if ferr := w.buf.Flush(); err == nil {
+		err = ferr
+	}
+	if serr := w.f.Sync(); err == nil {
+		err = serr
+	}
+	if cerr := w.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// ReadArchiveManifest loads the manifest of one archived trade date.
+func ReadArchiveManifest(dir, date string) (ArchiveManifest, error) {
+	m := ArchiveManifest{TradeDate: date, Tables: make(map[string]ArchiveTable)}
+	b, err := os.ReadFile(filepath.Join(dir, date, archiveManifestName))
+	if err != nil {
+		return m, err
+	}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return m, fmt.Errorf("archive manifest %s: %w", date, err)
+	}
+	if m.Tables == nil {
+		m.Tables = make(map[string]ArchiveTable)
+	}
+	return m, nil
+}
+
+// writeArchiveManifest records the row count of a rewritten day file.
+func writeArchiveManifest(dir, date, table string, rows int) error {
+	m, err := ReadArchiveManifest(dir, date)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	t := m.Tables[table]
+	t.File = table + ".jsonl.gz"
+	t.Rows = rows
+	t.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+	m.Tables[table] = t
+
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, date, archiveManifestName)
+	if err := os.WriteFile(path+".tmp", b, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// RestoreArchive loads every table of an archived trade date into db (which must be migrated)
+// and returns the rows read per table. Rows replace existing ones with the same primary key.
+func RestoreArchive(db *sql.DB, dir, date string) (map[string]int, error) {
+	m, err := ReadArchiveManifest(dir, date)
+	if err != nil {
+		return nil, err
+	}
+	tables := make([]string, 0, len(m.Tables))
+	for t := range m.Tables {
+		if _, ok := archivableTables[t]; !ok {
+			return nil, fmt.Errorf("archive %s: unknown table %q", date, t)
+		}
+		tables = append(tables, t)
+	}
+	sort.Strings(tables)
+
+	out := make(map[string]int, len(tables))
+	for _, t := range tables {
+		n, err := restoreArchiveTable(db, filepath.Join(dir, date, m.Tables[t].File), t)
+		if err != nil {
+			return out, fmt.Errorf("restore %s: %w", t, err)
+		}
+		out[t] = n
+	}
+	return out, nil
+}
+
+func restoreArchiveTable(db *sql.DB, path, table string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(bufio.NewReader(f)) // reads all appended members
+	if err != nil {
+		return 0, err
+	}
+	defer gz.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	dec := json.NewDecoder(gz)
+	dec.UseNumber()
+	stmts := make(map[string]*sql.Stmt)
+	ensured := make(map[string]bool)
+	n := 0
+	for dec.More() {
+		var obj map[string]any
+		if err := dec.Decode(&obj); err != nil {
+			return n, err
+		}
+		cols := make([]string, 0, len(obj))
+		for c := range obj {
+			if !isIdent(c) {
+				return n, fmt.Errorf("bad column name %q", c)
+			}
+			cols = append(cols, c)
+		}
+		sort.Strings(cols)
+		sig := strings.Join(cols, ",")
+		target := table
+		if isPartitioned(table) {
+			ts, err := time.Parse(time.RFC3339Nano, fmt.Sprint(obj["ts_utc"]))
+			if err != nil {
+				return n, fmt.Errorf("row without valid ts_utc: %w", err)
+			}
+			p := partitionFor(table, ts)
+			if !ensured[p.Table] {
+				if err := ensurePartition(tx, table, p); err != nil {
+					return n, err
+				}
+				ensured[p.Table] = true
+			}
+			target = p.Table
+		}
+		stmt, ok := stmts[target+":"+sig]
+		if !ok {
+			stmt, err = tx.Prepare(fmt.Sprintf(`INSERT OR REPLACE INTO %s(%s) VALUES (%s)`,
+				target, sig, strings.TrimSuffix(strings.Repeat("?, ", len(cols)), ", ")))
+			if err != nil {
+				return n, err
+			}
+			defer stmt.Close()
+			stmts[target+":"+sig] = stmt
+		}
+		args := make([]any, len(cols))
+		for i, c := range cols {
+			args[i] = jsonArg(obj[c])
+		}
+		if _, err := stmt.Exec(args...); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, tx.Commit()
+}
+
+// jsonArg turns a decoded JSON value back into an int64/float64 SQL argument where possible.
+func jsonArg(v any) any {
+	num, ok := v.(json.Number)
+	if !ok {
+		return v
+	}
+	if i, err := num.Int64(); err == nil {
+		return i
+	}
+	f, _ := num.Float64()
+	return f
+}
+
+func isIdent(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,111 @@
+package sqlite
+
+import (
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/eastmoney"
+)
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := Open(filepath.Join(t.TempDir(), "aof.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestArchiveRestoreRoundTrip(t *testing.T) {
+	db := openTestDB(t)
+	dir := t.TempDir()
+
+	for _, r := range []eastmoney.FundflowDaily{
+		{TradeDate: "2024-01-02", SecID: "1.600519", Code: "600519", Name: "贵州茅台", NetMain: 1.5e8},
+		{TradeDate: "2024-01-02", SecID: "0.000001", Code: "000001", Name: "平安银行", NetMain: -2e7},
+		{TradeDate: "2024-03-01", SecID: "1.600519", Code: "600519", Name: "贵州茅台", NetMain: 3e7},
+	} {
+		if err := UpsertFundflowDaily(db, r.TradeDate, r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	boards := map[string][]eastmoney.TopItem{"industry:f62": {
+		{Code: "BK0477", Name: "酿酒行业", Price: 12345.67, Pct: 1.23, Value: 1.5e9},
+		{Code: "BK0428", Name: "电力行业", Price: 2345.1, Pct: -0.5, Value: -3e8},
+	}}
+	// 2024-01-02 as board_rt rows, 2024-01-03 as compact board_rt_blob.
+	tsRows := time.Date(2024, 1, 2, 2, 0, 0, 0, time.UTC)
+	tsBlob := time.Date(2024, 1, 3, 2, 0, 0, 0, time.UTC)
+	if _, err := WriteRTSnapshot(db, tsRows, RTWrite{Boards: boards}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := WriteRTSnapshot(db, tsBlob, RTWrite{Boards: boards, CompactBoards: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	// An export whose delete never happened is repeated by the next cleanup; it must not duplicate rows.
+	for i := 0; i < 2; i++ {
+		if n, err := archiveBefore(db, dir, "fundflow_daily", "fundflow_daily", "2024-02-01"); err != nil || n != 2 {
+			t.Fatalf("archive #%d: n=%d err=%v", i+1, n, err)
+		}
+	}
+
+	now := time.Date(2024, 3, 2, 8, 0, 0, 0, time.UTC)
+	if err := RollupRT(db, now); err != nil {
+		t.Fatal(err)
+	}
+	if err := CleanupOldData(db, now, Retention{DailyDays: 30, RawDays: 30, ArchiveDir: dir}); err != nil {
+		t.Fatal(err)
+	}
+	if rows, _, err := QueryFundflowDailyByCode(db, "000001", 10); err != nil || len(rows) != 0 {
+		t.Fatalf("fundflow_daily after cleanup: %+v err=%v", rows, err)
+	}
+	if ps, err := partitionsBetween(db, "board_rt", "", maxTSUTC, false); err != nil || len(ps) != 0 {
+		t.Fatalf("board partitions after cleanup: %+v err=%v", ps, err)
+	}
+
+	wantRows := map[string]map[string]int{
+		"2024-01-02": {"fundflow_daily": 2, "board_rt": 2},
+		"2024-01-03": {"board_rt": 2},
+	}
+	restored := openTestDB(t)
+	for date, want := range wantRows {
+		m, err := ReadArchiveManifest(dir, date)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for table, n := range want {
+			if m.Tables[table].Rows != n {
+				t.Errorf("%s manifest %s rows=%d want %d", date, table, m.Tables[table].Rows, n)
+			}
+		}
+		got, err := RestoreArchive(restored, dir, date)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s restored %v want %v", date, got, want)
+		}
+	}
+
+	ff, _, err := QueryFundflowDailyByCode(restored, "600519", 10)
+	if err != nil || len(ff) != 1 || ff[0].TradeDate != "2024-01-02" || ff[0].NetMain != 1.5e8 {
+		t.Fatalf("restored fundflow_daily=%+v err=%v", ff, err)
+	}
+	for _, ts := range []time.Time{tsRows, tsBlob} {
+		got, err := QueryBoardsRTAt(restored, fixedRFC3339Nano(ts))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, boards) {
+			t.Fatalf("restored boards at %v = %+v", ts, got)
+		}
+	}
+}
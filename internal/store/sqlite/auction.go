@@ -0,0 +1,159 @@
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/eastmoney"
+)
+
+type AuctionRow struct {
+	TSUTC        string  `json:"ts_utc"`
+	TradeDate    string  `json:"trade_date"`
+	Phase        string  `json:"phase"`
+	Source       string  `json:"source"`
+	Code         string  `json:"code"`
+	Name         string  `json:"name"`
+	Rank         int     `json:"rank"`
+	Price        float64 `json:"price"`
+	Pct          float64 `json:"pct"`
+	Volume       float64 `json:"volume"`
+	Amount       float64 `json:"amount"`
+	BidVol       float64 `json:"bid_vol"`
+	AskVol       float64 `json:"ask_vol"`
+	Unmatched    float64 `json:"unmatched"`
+	UnmatchedAmt float64 `json:"unmatched_amt"`
+}
+
+// auctionOrderBy maps API sort keys to SQL; keys not listed fall back to "flow".
+var auctionOrderBy = map[string]string{
+	"flow":      "unmatched_amt DESC",
+	"outflow":   "unmatched_amt ASC",
+	"unmatched": "unmatched DESC",
+	"amount":    "amount DESC",
+	"volume":    "volume DESC",
+	"pct":       "pct DESC",
+}
+
+func UpsertAuctionRT(db *sql.DB, tsUTC time.Time, tradeDate, phase, source string, rows []eastmoney.AuctionItem) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO auction_rt(
+			ts_utc, trade_date, phase, source, code, name, rank,
+			price, pct, volume, amount, bid_vol, ask_vol, unmatched, unmatched_amt
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(ts_utc, source, code) DO UPDATE SET
+			trade_date=excluded.trade_date,
+			phase=excluded.phase,
+			name=excluded.name,
+			rank=excluded.rank,
+			price=excluded.price,
+			pct=excluded.pct,
+			volume=excluded.volume,
+			amount=excluded.amount,
+			bid_vol=excluded.bid_vol,
+			ask_vol=excluded.ask_vol,
+			unmatched=excluded.unmatched,
+			unmatched_amt=excluded.unmatched_amt
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	ts := fixedRFC3339Nano(tsUTC)
+	for _, r := range rows {
+		if r.Code == "" {
+			continue
+		}
+		if _, err := stmt.Exec(ts, tradeDate, phase, source, r.Code, r.Name, r.Rank,
+			r.Price, r.Pct, r.Volume, r.Amount, r.BidVol, r.AskVol, r.Unmatched(), r.UnmatchedAmt()); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// QueryAuctionLatestTradeDate returns the most recent trade date with auction samples for phase.
+func QueryAuctionLatestTradeDate(db *sql.DB, phase string) (string, error) {
+	var d sql.NullString
+	if err := db.QueryRow(`
+		SELECT MAX(trade_date)
+		FROM auction_rt
+		WHERE phase = ?
+	`, phase).Scan(&d); err != nil {
+		return "", err
+	}
+	if !d.Valid {
+		return "", nil
+	}
+	return d.String, nil
+}
+
+// QueryAuctionRank returns the latest auction sample of tradeDate/phase ranked by orderBy.
+// source "" merges watchlist and toplist rows; a code present in both is returned once.
+func QueryAuctionRank(db *sql.DB, tradeDate, phase, source, orderBy string, limit int) (string, []AuctionRow, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	order, ok := auctionOrderBy[orderBy]
+	if !ok {
+		order = auctionOrderBy["flow"]
+	}
+
+	var ts sql.NullString
+	if err := db.QueryRow(`
+		SELECT MAX(ts_utc)
+		FROM auction_rt
+		WHERE trade_date = ? AND phase = ? AND (? = '' OR source = ?)
+	`, tradeDate, phase, source, source).Scan(&ts); err != nil {
+		return "", nil, err
+	}
+	if !ts.Valid || ts.String == "" {
+		return "", nil, nil
+	}
+
+	rows, err := db.Query(`
+		SELECT ts_utc, trade_date, phase, source, code, name, rank,
+			price, pct, volume, amount, bid_vol, ask_vol, unmatched, unmatched_amt
+		FROM auction_rt
+		WHERE ts_utc = ? AND (? = '' OR source = ?)
+		ORDER BY `+order+`, code
+	`, ts.String, source, source)
+	if err != nil {
+		return "", nil, err
+	}
+	defer rows.Close()
+
+	out := make([]AuctionRow, 0, limit)
+	seen := make(map[string]struct{}, limit)
+	for rows.Next() {
+		var r AuctionRow
+		var name sql.NullString
+		if err := rows.Scan(&r.TSUTC, &r.TradeDate, &r.Phase, &r.Source, &r.Code, &name, &r.Rank,
+			&r.Price, &r.Pct, &r.Volume, &r.Amount, &r.BidVol, &r.AskVol, &r.Unmatched, &r.UnmatchedAmt); err != nil {
+			return "", nil, err
+		}
+		if _, dup := seen[r.Code]; dup {
+			continue
+		}
+		seen[r.Code] = struct{}{}
+		r.Name = name.String
+		out = append(out, r)
+		if len(out) >= limit {
+			break
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return "", nil, err
+	}
+	return ts.String, out, nil
+}
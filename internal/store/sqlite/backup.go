@@ -0,0 +1,121 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// BackupTo writes a consistent copy of the database to path (which must not exist yet).
+// It is an online backup: VACUUM INTO reads one snapshot, so concurrent writers are fine.
+func BackupTo(db *sql.DB, path string) error {
+	_, err := db.Exec(`VACUUM INTO '` + strings.ReplaceAll(path, "'", "''") + `'`)
+	return err
+}
+
+// CheckBackup opens the database file at path read-only, runs PRAGMA integrity_check and
+// returns its schema version. Backups from a newer build than this one are rejected.
+func CheckBackup(path string) (version int, err error) {
+	if _, err := os.Stat(path); err != nil {
+		return 0, err
+	}
+	db, err := sql.Open("sqlite", "file:"+path+"?mode=ro")
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+	return checkBackup(db)
+}
+
+func checkBackup(db *sql.DB) (int, error) {
+	rows, err := db.Query(`PRAGMA integrity_check`)
+	if err != nil {
+		return 0, err
+	}
+	var problems []string
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if s != "ok" {
+			problems = append(problems, s)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(problems) > 0 {
+		return 0, fmt.Errorf("integrity check failed: %s", strings.Join(problems, "; "))
+	}
+
+	var version sql.NullInt64
+	if err := db.QueryRow(`SELECT MAX(version) FROM schema_migrations`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("not an aof database: %w", err)
+	}
+	if latest := migrations[len(migrations)-1].Version; int(version.Int64) > latest {
+		return 0, fmt.Errorf("backup has schema v%d, newer than this build (v%d)", version.Int64, latest)
+	}
+	return int(version.Int64), nil
+}
+
+// RestoreFrom replaces the database at dbPath with the backup at src once the backup passes
+// CheckBackup. The current database is copied to <dbPath>.pre-restore-<utc>.bak first
+// (previous is "" if dbPath didn't exist). It fails while another process, e.g. `aof web`,
+// has dbPath open. An older backup is brought up to date by the next Migrate.
+func RestoreFrom(dbPath, src string) (previous string, err error) {
+	if _, err := CheckBackup(src); err != nil {
+		return "", fmt.Errorf("%s: %w", src, err)
+	}
+
+	// Copy through VACUUM INTO rather than the file system so a -wal next to src is included.
+	tmp := dbPath + ".restore.tmp"
+	_ = os.Remove(tmp)
+	srcDB, err := sql.Open("sqlite", "file:"+src+"?mode=ro")
+	if err != nil {
+		return "", err
+	}
+	err = BackupTo(srcDB, tmp)
+	srcDB.Close()
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp)
+
+	if _, err := os.Stat(dbPath); err == nil {
+		previous = fmt.Sprintf("%s.pre-restore-%s.bak", dbPath, time.Now().UTC().Format("20060102T150405Z"))
+		if err := backupUnused(dbPath, previous); err != nil {
+			return "", err
+		}
+	} else if !os.IsNotExist(err) {
+		return "", err
+	}
+
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := os.Remove(dbPath + suffix); err != nil && !os.IsNotExist(err) {
+			return previous, err
+		}
+	}
+	return previous, os.Rename(tmp, dbPath)
+}
+
+// backupUnused copies dbPath to out while holding an exclusive lock on it,
+// so it fails instead of waiting if another connection has the database open.
+func backupUnused(dbPath, out string) error {
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	for _, s := range []string{`PRAGMA busy_timeout=0`, `PRAGMA locking_mode=EXCLUSIVE`, `BEGIN EXCLUSIVE`, `COMMIT`} {
+		if _, err := db.Exec(s); err != nil {
+			return fmt.Errorf("%s is in use (stop aof web/rt first): %w", dbPath, err)
+		}
+	}
+	return BackupTo(db, out)
+}
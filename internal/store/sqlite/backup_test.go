@@ -0,0 +1,177 @@
+package sqlite
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// countRows returns the number of northbound_rt rows in the database file at path.
+func countRows(t *testing.T, path string) int {
+	t.Helper()
+	db, err := sql.Open("sqlite", "file:"+path+"?mode=ro")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var n int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM northbound_rt`).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func insertNorthbound(t *testing.T, db *sql.DB, ts string) {
+	t.Helper()
+	if _, err := db.Exec(`INSERT INTO northbound_rt(ts_utc, trade_date) VALUES (?, '2024-01-02')`, ts); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBackupRoundTrip(t *testing.T) {
+	db := openTestDB(t)
+	insertNorthbound(t, db, "2024-01-02T02:00:00Z")
+
+	out := filepath.Join(t.TempDir(), "copy.db")
+	if err := BackupTo(db, out); err != nil {
+		t.Fatal(err)
+	}
+	version, err := CheckBackup(out)
+	if err != nil || version != migrations[len(migrations)-1].Version {
+		t.Fatalf("version=%d err=%v", version, err)
+	}
+	if n := countRows(t, out); n != 1 {
+		t.Fatalf("backup has %d rows", n)
+	}
+	// VACUUM INTO never overwrites.
+	if err := BackupTo(db, out); err == nil {
+		t.Fatal("backup over an existing file succeeded")
+	}
+}
+
+func TestCheckBackupRejects(t *testing.T) {
+	db := openTestDB(t)
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.db")
+	if err := BackupTo(db, good); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(good)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Page 2 onwards overwritten: the header still says SQLite.
+	corrupt := filepath.Join(dir, "corrupt.db")
+	bad := append([]byte(nil), b...)
+	for i := 4096; i < len(bad) && i < 3*4096; i++ {
+		bad[i] = 0xA5
+	}
+	if err := os.WriteFile(corrupt, bad, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	garbage := filepath.Join(dir, "garbage.db")
+	if err := os.WriteFile(garbage, []byte(strings.Repeat("not a database ", 512)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	other := filepath.Join(dir, "other.db")
+	odb, err := sql.Open("sqlite", other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := odb.Exec(`CREATE TABLE notes (body TEXT)`); err != nil {
+		t.Fatal(err)
+	}
+	odb.Close()
+
+	newer := filepath.Join(dir, "newer.db")
+	if err := BackupTo(db, newer); err != nil {
+		t.Fatal(err)
+	}
+	ndb, err := sql.Open("sqlite", newer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ndb.Exec(`INSERT INTO schema_migrations VALUES (?, 'from the future', '2030-01-01T00:00:00Z')`,
+		migrations[len(migrations)-1].Version+1); err != nil {
+		t.Fatal(err)
+	}
+	ndb.Close()
+
+	for _, tc := range []struct{ path, want string }{
+		{corrupt, ""},
+		{garbage, ""},
+		{other, "not an aof database"},
+		{newer, "newer than this build"},
+		{filepath.Join(dir, "missing.db"), ""},
+	} {
+		if _, err := CheckBackup(tc.path); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: err=%v, want %q", filepath.Base(tc.path), err, tc.want)
+		}
+	}
+}
+
+func TestRestoreFrom(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "aof.db")
+	db, err := Open(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	insertNorthbound(t, db, "2024-01-02T02:00:00Z")
+	src := filepath.Join(dir, "backup.db")
+	if err := BackupTo(db, src); err != nil {
+		t.Fatal(err)
+	}
+	insertNorthbound(t, db, "2024-01-02T02:01:00Z")
+
+	// The open connection holds the database.
+	if _, err := RestoreFrom(dbPath, src); err == nil || !strings.Contains(err.Error(), "in use") {
+		t.Fatalf("restore over an open database: %v", err)
+	}
+	if err := backupUnused(dbPath, filepath.Join(dir, "x.bak")); err == nil {
+		t.Fatal("backupUnused succeeded while the database is open")
+	}
+	db.Close()
+
+	// Leftovers of the replaced database must not be replayed into the restored one.
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := os.WriteFile(dbPath+suffix, []byte("stale"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	previous, err := RestoreFrom(dbPath, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(previous, dbPath+".pre-restore-") || !strings.HasSuffix(previous, ".bak") {
+		t.Fatalf("previous=%q", previous)
+	}
+	if n := countRows(t, previous); n != 2 {
+		t.Fatalf("pre-restore copy has %d rows, want 2", n)
+	}
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if b, err := os.ReadFile(dbPath + suffix); err == nil && string(b) == "stale" {
+			t.Fatalf("%s kept", suffix)
+		}
+	}
+	if n := countRows(t, dbPath); n != 1 {
+		t.Fatalf("restored database has %d rows, want 1", n)
+	}
+	if _, err := os.Stat(dbPath + ".restore.tmp"); !os.IsNotExist(err) {
+		t.Fatalf("temp file left: %v", err)
+	}
+
+	// Restoring to a new path makes no pre-restore copy.
+	fresh := filepath.Join(dir, "fresh.db")
+	if previous, err := RestoreFrom(fresh, src); err != nil || previous != "" || countRows(t, fresh) != 1 {
+		t.Fatalf("fresh restore previous=%q err=%v", previous, err)
+	}
+}
@@ -0,0 +1,371 @@
+package sqlite
+
+import (
+	"bytes"
+	"compress/flate"
+	"database/sql"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/eastmoney"
+)
+
+// Compact board storage (persist.board_format: compact) writes one row per (ts_utc, board_type, fid)
+// into the day partitions of board_rt_blob instead of one row per board. Codes and names live once
+// in board_dict; the blob holds dictionary ids and the price, pct and value columns:
+//
+//	version byte (1) | uvarint n | n uvarint ids | price column | pct column | value column
+//
+// A column is a scale byte s followed by n zigzag varints of v*10^s when every value has at most
+// s (<= 4) decimals, or 0xff and n little-endian float64s otherwise. The whole payload is deflated.
+// Readers merge both formats, so the mode can be switched at any time.
+
+const (
+	boardBlobVersion = 1
+	boardBlobMaxDec  = 4
+	boardBlobRawCol  = 0xff
+)
+
+var pow10 = [boardBlobMaxDec + 1]float64{1, 10, 100, 1000, 10000}
+
+// boardSnap is one persisted board list: the boards of one board_type+fid at one ts_utc.
+type boardSnap struct {
+	TSUTC     string
+	BoardType string
+	FID       string
+	Items     []eastmoney.TopItem
+}
+
+func (s boardSnap) key() string { return s.BoardType + ":" + s.FID }
+
+type boardDictEntry struct {
+	Code string
+	Name string
+}
+
+func encodeBoardBlob(ids []int64, rows []eastmoney.TopItem) ([]byte, error) {
+	var raw []byte
+	raw = append(raw, boardBlobVersion)
+	raw = binary.AppendUvarint(raw, uint64(len(rows)))
+	for _, id := range ids {
+		raw = binary.AppendUvarint(raw, uint64(id))
+	}
+	col := make([]float64, len(rows))
+	for _, field := range []func(eastmoney.TopItem) float64{
+		func(it eastmoney.TopItem) float64 { return it.Price },
+		func(it eastmoney.TopItem) float64 { return it.Pct },
+		func(it eastmoney.TopItem) float64 { return it.Value },
+	} {
+		for i, it := range rows {
+			col[i] = field(it)
+		}
+		raw = appendBlobColumn(raw, col)
+	}
+
+	var buf bytes.Buffer
+	zw, err := flate.NewWriter(&buf, flate.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := zw.Write(raw); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// appendBlobColumn stores vals as scaled integers when that round-trips exactly.
+func appendBlobColumn(b []byte, vals []float64) []byte {
+	for s := 0; s <= boardBlobMaxDec; s++ {
+		if ints, ok := scaleColumn(vals, s); ok {
+			b = append(b, byte(s))
+			for _, v := range ints {
+				b = binary.AppendVarint(b, v)
+			}
+			return b
+		}
+	}
+	b = append(b, boardBlobRawCol)
+	for _, v := range vals {
+		b = binary.LittleEndian.AppendUint64(b, math.Float64bits(v))
+	}
+	return b
+}
+
+func scaleColumn(vals []float64, s int) ([]int64, bool) {
+	out := make([]int64, len(vals))
+	for i, v := range vals {
+		r := math.Round(v * pow10[s])
+		if math.Abs(r) > 1<<53 || r/pow10[s] != v {
+			return nil, false
+		}
+		out[i] = int64(r)
+	}
+	return out, true
+}
+
+func decodeBoardBlob(data []byte, dict map[int64]boardDictEntry) ([]eastmoney.TopItem, error) {
+	raw, err := io.ReadAll(flate.NewReader(bytes.NewReader(data)))
+	if err != nil {
+		return nil, fmt.Errorf("board blob: %w", err)
+	}
+	r := bytes.NewReader(raw)
+	if v, err := r.ReadByte(); err != nil || v != boardBlobVersion {
+		return nil, fmt.Errorf("board blob: unknown version %d", v)
+	}
+	n, err := binary.ReadUvarint(r)
+	if err != nil || n > uint64(len(raw)) {
+		return nil, errors.New("board blob: bad length")
+	}
+	out := make([]eastmoney.TopItem, n)
+	for i := range out {
+		id, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, fmt.Errorf("board blob: %w", err)
+		}
+		e, ok := dict[int64(id)]
+		if !ok {
+			return nil, fmt.Errorf("board blob: id %d not in board_dict", id)
+		}
+		out[i].Code, out[i].Name = e.Code, e.Name
+	}
+	for _, set := range []func(*eastmoney.TopItem, float64){
+		func(it *eastmoney.TopItem, v float64) { it.Price = v },
+		func(it *eastmoney.TopItem, v float64) { it.Pct = v },
+		func(it *eastmoney.TopItem, v float64) { it.Value = v },
+	} {
+		s, err := r.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("board blob: %w", err)
+		}
+		for i := range out {
+			var v float64
+			switch {
+			case s == boardBlobRawCol:
+				var bits [8]byte
+				if _, err := io.ReadFull(r, bits[:]); err != nil {
+					return nil, fmt.Errorf("board blob: %w", err)
+				}
+				v = math.Float64frombits(binary.LittleEndian.Uint64(bits[:]))
+			case int(s) <= boardBlobMaxDec:
+				iv, err := binary.ReadVarint(r)
+				if err != nil {
+					return nil, fmt.Errorf("board blob: %w", err)
+				}
+				v = float64(iv) / pow10[s]
+			default:
+				return nil, fmt.Errorf("board blob: bad column scale %d", s)
+			}
+			set(&out[i], v)
+		}
+	}
+	// Same order as board_rt queries.
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Value > out[j].Value })
+	return out, nil
+}
+
+// ensureBoardDict returns the board_dict ids of rows, adding new codes and updating renamed boards.
+func ensureBoardDict(tx *sql.Tx, rows []eastmoney.TopItem) ([]int64, error) {
+	dict, err := loadBoardDict(tx)
+	if err != nil {
+		return nil, err
+	}
+	byCode := make(map[string]int64, len(dict))
+	for id, e := range dict {
+		byCode[e.Code] = id
+	}
+	ids := make([]int64, len(rows))
+	for i, r := range rows {
+		id, ok := byCode[r.Code]
+		switch {
+		case !ok:
+			if err := tx.QueryRow(`INSERT INTO board_dict(code, name) VALUES (?, ?) RETURNING id`, r.Code, r.Name).Scan(&id); err != nil {
+				return nil, err
+			}
+			byCode[r.Code] = id
+		case r.Name != "" && dict[id].Name != r.Name:
+			if _, err := tx.Exec(`UPDATE board_dict SET name = ? WHERE id = ?`, r.Name, id); err != nil {
+				return nil, err
+			}
+			dict[id] = boardDictEntry{Code: r.Code, Name: r.Name}
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
+
+func loadBoardDict(db queryer) (map[int64]boardDictEntry, error) {
+	rows, err := db.Query(`SELECT id, code, COALESCE(name, '') FROM board_dict`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make(map[int64]boardDictEntry)
+	for rows.Next() {
+		var id int64
+		var e boardDictEntry
+		if err := rows.Scan(&id, &e.Code, &e.Name); err != nil {
+			return nil, err
+		}
+		out[id] = e
+	}
+	return out, rows.Err()
+}
+
+func boardDictID(db queryer, code string) (int64, bool, error) {
+	var id int64
+	if err := db.QueryRow(`SELECT id FROM board_dict WHERE code = ?`, code).Scan(&id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	return id, true, nil
+}
+
+func upsertBoardBlob(tx *sql.Tx, tsUTC time.Time, boardType, fid string, rows []eastmoney.TopItem) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	ids, err := ensureBoardDict(tx, rows)
+	if err != nil {
+		return err
+	}
+	data, err := encodeBoardBlob(ids, rows)
+	if err != nil {
+		return err
+	}
+	p := partitionFor("board_rt_blob", tsUTC)
+	if err := ensurePartition(tx, "board_rt_blob", p); err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+		INSERT INTO `+p.Table+`(ts_utc, board_type, fid, n, data)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(ts_utc, board_type, fid) DO UPDATE SET
+			n=excluded.n,
+			data=excluded.data
+	`, fixedRFC3339Nano(tsUTC), boardType, fid, len(rows), data)
+	return err
+}
+
+// queryBoardBlobs decodes the snapshots in one board_rt_blob partition matching where,
+// which may end in ORDER BY / LIMIT. A nil *dict is loaded first (not while rows are open:
+// the SQLite writer has a single connection).
+func queryBoardBlobs(db queryer, dict *map[int64]boardDictEntry, table, where string, args ...any) ([]boardSnap, error) {
+	var out []boardSnap
+	err := eachBoardBlob(db, dict, table, where, func(s boardSnap) error {
+		out = append(out, s)
+		return nil
+	}, args...)
+	return out, err
+}
+
+// eachBoardBlob is queryBoardBlobs calling fn for each snapshot as it is decoded instead of
+// collecting them; fn must not use db while the rows are open.
+func eachBoardBlob(db queryer, dict *map[int64]boardDictEntry, table, where string, fn func(boardSnap) error, args ...any) error {
+	if *dict == nil {
+		d, err := loadBoardDict(db)
+		if err != nil {
+			return err
+		}
+		*dict = d
+	}
+	rows, err := db.Query(`SELECT ts_utc, board_type, fid, data FROM `+table+` WHERE `+where, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var s boardSnap
+		var data []byte
+		if err := rows.Scan(&s.TSUTC, &s.BoardType, &s.FID, &data); err != nil {
+			return err
+		}
+		if s.Items, err = decodeBoardBlob(data, *dict); err != nil {
+			return fmt.Errorf("%s %s: %w", table, s.TSUTC, err)
+		}
+		if err := fn(s); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// boardBlobsBetween decodes the board_rt_blob snapshots with fromUTC <= ts_utc <= toUTC matching
+// the extra condition and (for limit > 0) stops after limit snapshots, newest first if desc.
+func boardBlobsBetween(db queryer, fromUTC, toUTC string, desc bool, limit int, cond string, args ...any) ([]boardSnap, error) {
+	ps, err := partitionsBetween(db, "board_rt_blob", fromUTC, toUTC, desc)
+	if err != nil {
+		return nil, err
+	}
+	order := "ASC"
+	if desc {
+		order = "DESC"
+	}
+	var dict map[int64]boardDictEntry
+	var out []boardSnap
+	for _, p := range ps {
+		where := `ts_utc >= ? AND ts_utc <= ?`
+		if cond != "" {
+			where += ` AND ` + cond
+		}
+		where += ` ORDER BY ts_utc ` + order
+		qargs := append([]any{fromUTC, toUTC}, args...)
+		if limit > 0 {
+			where += ` LIMIT ?`
+			qargs = append(qargs, limit-len(out))
+		}
+		snaps, err := queryBoardBlobs(db, &dict, p.Table, where, qargs...)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, snaps...)
+		if limit > 0 && len(out) >= limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+// archiveBoardBlobs exports a board_rt_blob partition as plain board_rt rows, so archives and
+// restore-archive don't depend on the compact format or board_dict. Snapshots are decoded and
+// written one at a time, so a day of collect_all concept lists is never held in memory.
+func archiveBoardBlobs(db *sql.DB, dir string, p rtPartition) error {
+	var dict map[int64]boardDictEntry
+	var w *archiveWriter
+	err := eachBoardBlob(db, &dict, p.Table, `1 = 1 ORDER BY ts_utc`, func(s boardSnap) error {
+		if w == nil {
+			var err error
+			if w, err = openArchiveWriter(dir, p.Day, "board_rt"); err != nil {
+				return err
+			}
+		}
+		for _, it := range s.Items {
+			if err := w.write(map[string]any{
+				"ts_utc": s.TSUTC, "board_type": s.BoardType, "fid": s.FID,
+				"code": it.Code, "name": it.Name, "price": it.Price, "pct": it.Pct, "value": it.Value,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if w == nil {
+		return err
+	}
+	if err != nil {
+		w.abort()
+		return err
+	}
+	return w.commit()
+}
@@ -0,0 +1,38 @@
+package sqlite
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/eastmoney"
+)
+
+func TestBoardBlobRoundTrip(t *testing.T) {
+	dict := map[int64]boardDictEntry{
+		1: {Code: "BK0477", Name: "酿酒行业"},
+		2: {Code: "BK1036", Name: "半导体"},
+		3: {Code: "BK0428", Name: "电力行业"},
+	}
+	rows := []eastmoney.TopItem{
+		{Code: "BK0477", Name: "酿酒行业", Price: 12345.67, Pct: 1.23, Value: 1.5e9},
+		{Code: "BK0428", Name: "电力行业", Price: 2345.1, Pct: -0.5, Value: 1.0 / 3}, // value column falls back to float64
+		{Code: "BK1036", Name: "半导体", Price: 999, Pct: 0, Value: -2.75e8},
+	}
+	data, err := encodeBoardBlob([]int64{1, 3, 2}, rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := decodeBoardBlob(data, dict)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Decoded lists come back ordered by value, like board_rt queries.
+	want := []eastmoney.TopItem{rows[0], rows[1], rows[2]}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("decoded %+v\nwant %+v", got, want)
+	}
+
+	if _, err := decodeBoardBlob(data, map[int64]boardDictEntry{1: dict[1]}); err == nil {
+		t.Fatal("expected an error for ids missing from board_dict")
+	}
+}
@@ -1,6 +1,11 @@
 package sqlite
 
-import "database/sql"
+import (
+	"database/sql"
+	"sort"
+
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/eastmoney"
+)
 
 type BoardSumRTPoint struct {
 	TSUTC string  `json:"ts_utc"`
@@ -16,30 +21,37 @@ func QueryBoardSumRT(db *sql.DB, boardType, fid string, limit int) ([]BoardSumRT
 	if limit <= 0 {
 		limit = 200
 	}
-	rows, err := db.Query(`
-		SELECT ts_utc, SUM(value) AS v
-		FROM board_rt
-		WHERE board_type = ? AND fid = ?
-		GROUP BY ts_utc
-		ORDER BY ts_utc DESC
-		LIMIT ?
-	`, boardType, fid, limit)
+	ps, err := partitionsBetween(db, "board_rt", "", maxTSUTC, true)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
 	out := make([]BoardSumRTPoint, 0, limit)
-	for rows.Next() {
-		var p BoardSumRTPoint
-		if err := rows.Scan(&p.TSUTC, &p.Value); err != nil {
+	for _, part := range ps {
+		if len(out) >= limit {
+			break
+		}
+		pts, err := queryBoardSums(db, `
+			SELECT ts_utc, SUM(value) AS v
+			FROM `+part.Table+`
+			WHERE board_type = ? AND fid = ?
+			GROUP BY ts_utc
+			ORDER BY ts_utc DESC
+			LIMIT ?
+		`, boardType, fid, limit-len(out))
+		if err != nil {
 			return nil, err
 		}
-		out = append(out, p)
+		out = append(out, pts...)
 	}
-	if err := rows.Err(); err != nil {
+	snaps, err := boardBlobsBetween(db, "", maxTSUTC, true, limit, `board_type = ? AND fid = ?`, boardType, fid)
+	if err != nil {
 		return nil, err
 	}
+	out = mergeBoardSums(out, snaps, func(it eastmoney.TopItem) float64 { return it.Value })
+	sort.SliceStable(out, func(i, j int) bool { return out[i].TSUTC > out[j].TSUTC })
+	if len(out) > limit {
+		out = out[:limit]
+	}
 	reverse(out)
 	return out, nil
 }
@@ -48,20 +60,75 @@ func QueryBoardPriceSumRT(db *sql.DB, boardType, fid, startUTC, endUTC string, l
 	if limit <= 0 {
 		limit = 1200
 	}
-	rows, err := db.Query(`
-		SELECT ts_utc, SUM(price) AS v
-		FROM board_rt
-		WHERE board_type = ? AND fid = ? AND ts_utc >= ? AND ts_utc <= ?
-		GROUP BY ts_utc
-		ORDER BY ts_utc ASC
-		LIMIT ?
-	`, boardType, fid, startUTC, endUTC, limit)
+	return boardSumsBetween(db, boardType, fid, "price", startUTC, endUTC, limit)
+}
+
+// boardSumsBetween sums col ("value" or "price") over the boards of each snapshot with
+// startUTC <= ts_utc <= endUTC, oldest first, stopping after limit points unless limit is 0.
+func boardSumsBetween(db *sql.DB, boardType, fid, col, startUTC, endUTC string, limit int) ([]BoardSumRTPoint, error) {
+	ps, err := partitionsBetween(db, "board_rt", startUTC, endUTC, false)
+	if err != nil {
+		return nil, err
+	}
+	var out []BoardSumRTPoint
+	for _, part := range ps {
+		if limit > 0 && len(out) >= limit {
+			break
+		}
+		query := `
+			SELECT ts_utc, SUM(` + col + `) AS v
+			FROM ` + part.Table + `
+			WHERE board_type = ? AND fid = ? AND ts_utc >= ? AND ts_utc <= ?
+			GROUP BY ts_utc
+			ORDER BY ts_utc ASC`
+		args := []any{boardType, fid, startUTC, endUTC}
+		if limit > 0 {
+			query += ` LIMIT ?`
+			args = append(args, limit-len(out))
+		}
+		pts, err := queryBoardSums(db, query, args...)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, pts...)
+	}
+	snaps, err := boardBlobsBetween(db, startUTC, endUTC, false, limit, `board_type = ? AND fid = ?`, boardType, fid)
+	if err != nil {
+		return nil, err
+	}
+	field := func(it eastmoney.TopItem) float64 { return it.Value }
+	if col == "price" {
+		field = func(it eastmoney.TopItem) float64 { return it.Price }
+	}
+	out = mergeBoardSums(out, snaps, field)
+	sort.SliceStable(out, func(i, j int) bool { return out[i].TSUTC < out[j].TSUTC })
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+// mergeBoardSums appends the per-snapshot sum of field over compact snapshots to pts.
+func mergeBoardSums(pts []BoardSumRTPoint, snaps []boardSnap, field func(eastmoney.TopItem) float64) []BoardSumRTPoint {
+	for _, s := range snaps {
+		var v float64
+		for _, it := range s.Items {
+			v += field(it)
+		}
+		pts = append(pts, BoardSumRTPoint{TSUTC: s.TSUTC, Value: v})
+	}
+	return pts
+}
+
+// queryBoardSums runs a query selecting (ts_utc, value) points, e.g. sums over a board_rt partition.
+func queryBoardSums(db *sql.DB, query string, args ...any) ([]BoardSumRTPoint, error) {
+	rows, err := db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	out := make([]BoardSumRTPoint, 0, limit)
+	var out []BoardSumRTPoint
 	for rows.Next() {
 		var p BoardSumRTPoint
 		if err := rows.Scan(&p.TSUTC, &p.Value); err != nil {
@@ -6,41 +6,122 @@ import (
 	"time"
 )
 
-// CleanupOldData deletes rows older than retentionDays from both realtime and daily tables.
+// Retention is the number of days kept per data tier; 0 keeps a bar tier forever.
+type Retention struct {
+	DailyDays  int // *_daily tables, daily runs, auction and fundflow deltas
+	RawDays    int // raw *_rt snapshots
+	Bars1mDays int
+	Bars5mDays int
+	// ArchiveDir, if set, receives every row before it is deleted.
+	ArchiveDir string
+}
+
+type cleanupStmt struct {
+	table  string
+	col    string
+	cutoff string
+}
+
+// CleanupOldData deletes rows older than each tier's retention.
 // Realtime tables use a fixed-width RFC3339Nano format stored as TEXT, so lexicographic compare works.
-func CleanupOldData(db *sql.DB, nowUTC time.Time, retentionDays int) error {
-	if retentionDays < 1 {
-		return fmt.Errorf("retentionDays must be >= 1")
+// Raw rows that the rollup (see RollupRT) hasn't turned into bars yet are never deleted.
+// Partitioned tables (see partition.go) lose whole days: a partition is dropped once all of it is past the cutoff.
+// With ret.ArchiveDir set, rows are exported there (see archive.go) before they are deleted;
+// cleanup stops at the first table whose export fails, before deleting anything from it.
+func CleanupOldData(db *sql.DB, nowUTC time.Time, ret Retention) error {
+	if ret.DailyDays < 1 || ret.RawDays < 1 {
+		return fmt.Errorf("retention days must be >= 1")
 	}
 
-	utcCutoff := nowUTC.AddDate(0, 0, -retentionDays)
-	utcCutoffStr := fixedRFC3339Nano(utcCutoff)
+	utcCutoffStr := fixedRFC3339Nano(nowUTC.AddDate(0, 0, -ret.DailyDays))
+	rawCutoffStr := fixedRFC3339Nano(nowUTC.AddDate(0, 0, -ret.RawDays))
+	rolledCutoffStr := rawCutoffStr
+	done, err := rollupDoneUTC(db)
+	if err != nil {
+		return err
+	}
+	if done < rolledCutoffStr {
+		rolledCutoffStr = done
+	}
 
 	loc, _ := time.LoadLocation("Asia/Shanghai")
-	dateCutoff := nowUTC.In(loc).AddDate(0, 0, -retentionDays).Format("2006-01-02")
+	dateCutoff := nowUTC.In(loc).AddDate(0, 0, -ret.DailyDays).Format("2006-01-02")
 
-	stmts := []struct {
-		sql  string
-		args []any
-	}{
-		{`DELETE FROM northbound_rt WHERE ts_utc < ?`, []any{utcCutoffStr}},
-		{`DELETE FROM fundflow_rt WHERE ts_utc < ?`, []any{utcCutoffStr}},
-		{`DELETE FROM toplist_rt WHERE ts_utc < ?`, []any{utcCutoffStr}},
-		{`DELETE FROM board_rt WHERE ts_utc < ?`, []any{utcCutoffStr}},
-		{`DELETE FROM market_agg_rt WHERE ts_utc < ?`, []any{utcCutoffStr}},
+	stmts := []cleanupStmt{
+		{"northbound_rt", "ts_utc", rolledCutoffStr},
+		{"fundflow_rt", "ts_utc", rolledCutoffStr},
+		{"toplist_rt", "ts_utc", rawCutoffStr},
+		{"market_agg_rt", "ts_utc", rolledCutoffStr},
+		{"auction_rt", "ts_utc", utcCutoffStr},
+		{"fundflow_delta", "ts_utc", utcCutoffStr},
 
-		{`DELETE FROM northbound_daily WHERE trade_date < ?`, []any{dateCutoff}},
-		{`DELETE FROM fundflow_daily WHERE trade_date < ?`, []any{dateCutoff}},
-		{`DELETE FROM board_daily WHERE trade_date < ?`, []any{dateCutoff}},
-		{`DELETE FROM market_agg_daily WHERE trade_date < ?`, []any{dateCutoff}},
-		{`DELETE FROM margin_daily WHERE trade_date < ?`, []any{dateCutoff}},
+		{"northbound_daily", "trade_date", dateCutoff},
+		{"fundflow_daily", "trade_date", dateCutoff},
+		{"board_daily", "trade_date", dateCutoff},
+		{"market_agg_daily", "trade_date", dateCutoff},
+		{"margin_daily", "trade_date", dateCutoff},
+	}
+	if ret.Bars1mDays > 0 {
+		stmts = append(stmts, cleanupStmt{"rt_bars_1m", "bar_utc", fixedRFC3339Nano(nowUTC.AddDate(0, 0, -ret.Bars1mDays))})
+	}
+	if ret.Bars5mDays > 0 {
+		stmts = append(stmts, cleanupStmt{"rt_bars_5m", "bar_utc", fixedRFC3339Nano(nowUTC.AddDate(0, 0, -ret.Bars5mDays))})
 	}
 
+	for _, base := range boardBases {
+		if err := dropPartitionsBefore(db, base, rolledCutoffStr, ret.ArchiveDir); err != nil {
+			return err
+		}
+	}
 	for _, st := range stmts {
-		if _, err := db.Exec(st.sql, st.args...); err != nil {
+		if ret.ArchiveDir != "" {
+			if _, err := archiveBefore(db, ret.ArchiveDir, st.table, st.table, st.cutoff); err != nil {
+				return fmt.Errorf("archive %s: %w", st.table, err)
+			}
+		}
+		if _, err := db.Exec(fmt.Sprintf(`DELETE FROM %s WHERE %s < ?`, st.table, st.col), st.cutoff); err != nil {
 			return err
 		}
 	}
+	// Run logs are operational metadata and aren't archived.
+	if _, err := db.Exec(`DELETE FROM daily_run_items WHERE run_id IN (SELECT id FROM daily_runs WHERE trade_date < ?)`, dateCutoff); err != nil {
+		return err
+	}
+	if _, err := db.Exec(`DELETE FROM daily_runs WHERE trade_date < ?`, dateCutoff); err != nil {
+		return err
+	}
+	if _, err := db.Exec(`DELETE FROM rt_snapshots WHERE ts_utc < ?`, rolledCutoffStr); err != nil {
+		return err
+	}
 	return nil
 }
 
+// dropPartitionsBefore drops the partitions of base that end at or before cutoff,
+// exporting each to archiveDir first unless it is "".
+func dropPartitionsBefore(db *sql.DB, base, cutoff, archiveDir string) error {
+	ps, err := partitionsBetween(db, base, "", cutoff, false)
+	if err != nil {
+		return err
+	}
+	for _, p := range ps {
+		if p.HiUTC > cutoff {
+			break
+		}
+		if archiveDir != "" {
+			archive := func() error {
+				_, err := archiveBefore(db, archiveDir, base, p.Table, p.HiUTC)
+				return err
+			}
+			if base == "board_rt_blob" {
+				archive = func() error { return archiveBoardBlobs(db, archiveDir, p) }
+			}
+			if err := archive(); err != nil {
+				return fmt.Errorf("archive %s: %w", p.Table, err)
+			}
+		}
+		if err := dropPartition(db, base, p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
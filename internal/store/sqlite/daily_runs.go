@@ -0,0 +1,172 @@
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Daily run item statuses.
+const (
+	DailyItemOK     = "ok"
+	DailyItemFailed = "failed"
+)
+
+type DailyRun struct {
+	ID         int64          `json:"id"`
+	TradeDate  string         `json:"trade_date"`
+	Trigger    string         `json:"trigger"`
+	Status     string         `json:"status"`
+	StartedAt  string         `json:"started_at"`
+	FinishedAt string         `json:"finished_at,omitempty"`
+	Total      int            `json:"total"`
+	OK         int            `json:"ok"`
+	Failed     int            `json:"failed"`
+	Items      []DailyRunItem `json:"items,omitempty"`
+}
+
+type DailyRunItem struct {
+	RunID     int64  `json:"run_id"`
+	Dataset   string `json:"dataset"`
+	Key       string `json:"key"`
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+	Attempts  int    `json:"attempts"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+func CreateDailyRun(db *sql.DB, tradeDate, trigger string, startedAt time.Time, total int) (int64, error) {
+	var id int64
+	err := db.QueryRow(`
+		INSERT INTO daily_runs(trade_date, trigger, status, started_at, total, ok, failed)
+		VALUES (?, ?, 'running', ?, ?, 0, 0)
+		RETURNING id
+	`, tradeDate, trigger, fixedRFC3339Nano(startedAt), total).Scan(&id)
+	return id, err
+}
+
+// UpsertDailyRunItem records the outcome of one item; re-running it in the same run bumps attempts.
+func UpsertDailyRunItem(db *sql.DB, runID int64, dataset, key, status, errMsg string, at time.Time) error {
+	_, err := db.Exec(`
+		INSERT INTO daily_run_items(run_id, dataset, key, status, error, attempts, updated_at)
+		VALUES (?, ?, ?, ?, ?, 1, ?)
+		ON CONFLICT(run_id, dataset, key) DO UPDATE SET
+			status=excluded.status,
+			error=excluded.error,
+			attempts=daily_run_items.attempts + 1,
+			updated_at=excluded.updated_at
+	`, runID, dataset, key, status, errMsg, fixedRFC3339Nano(at))
+	return err
+}
+
+// FinishDailyRun recomputes ok/failed counts from the run's items and sets the final status.
+func FinishDailyRun(db *sql.DB, runID int64, finishedAt time.Time) error {
+	_, err := db.Exec(`
+		UPDATE daily_runs SET
+			ok = (SELECT COUNT(*) FROM daily_run_items WHERE run_id = daily_runs.id AND status = 'ok'),
+			failed = (SELECT COUNT(*) FROM daily_run_items WHERE run_id = daily_runs.id AND status = 'failed'),
+			finished_at = ?
+		WHERE id = ?
+	`, fixedRFC3339Nano(finishedAt), runID)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`
+		UPDATE daily_runs SET status = CASE
+			WHEN failed = 0 THEN 'ok'
+			WHEN ok = 0 THEN 'failed'
+			ELSE 'partial'
+		END
+		WHERE id = ?
+	`, runID)
+	return err
+}
+
+// QueryDailyRuns returns runs for tradeDate (newest first), or the most recent runs when tradeDate is "".
+func QueryDailyRuns(db *sql.DB, tradeDate string, limit int) ([]DailyRun, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	rows, err := db.Query(`
+		SELECT id, trade_date, trigger, status, started_at, finished_at, total, ok, failed
+		FROM daily_runs
+		WHERE (? = '' OR trade_date = ?)
+		ORDER BY id DESC
+		LIMIT ?
+	`, tradeDate, tradeDate, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]DailyRun, 0, limit)
+	for rows.Next() {
+		var r DailyRun
+		var trigger, startedAt, finishedAt sql.NullString
+		var total, ok, failed sql.NullInt64
+		if err := rows.Scan(&r.ID, &r.TradeDate, &trigger, &r.Status, &startedAt, &finishedAt, &total, &ok, &failed); err != nil {
+			return nil, err
+		}
+		r.Trigger = trigger.String
+		r.StartedAt = startedAt.String
+		r.FinishedAt = finishedAt.String
+		r.Total = int(total.Int64)
+		r.OK = int(ok.Int64)
+		r.Failed = int(failed.Int64)
+		out = append(out, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func QueryDailyRunItems(db *sql.DB, runID int64) ([]DailyRunItem, error) {
+	return queryDailyRunItems(db, `
+		SELECT run_id, dataset, key, status, error, attempts, updated_at
+		FROM daily_run_items
+		WHERE run_id = ?
+		ORDER BY dataset, key
+	`, runID)
+}
+
+// QueryDailyItemStatus returns the latest outcome of every item ever run for tradeDate,
+// i.e. a later successful retry supersedes an earlier failure.
+func QueryDailyItemStatus(db *sql.DB, tradeDate string) ([]DailyRunItem, error) {
+	return queryDailyRunItems(db, `
+		SELECT i.run_id, i.dataset, i.key, i.status, i.error, i.attempts, i.updated_at
+		FROM daily_run_items i
+		JOIN daily_runs r ON r.id = i.run_id
+		WHERE r.trade_date = ?
+			AND i.run_id = (
+				SELECT MAX(i2.run_id)
+				FROM daily_run_items i2
+				JOIN daily_runs r2 ON r2.id = i2.run_id
+				WHERE r2.trade_date = r.trade_date AND i2.dataset = i.dataset AND i2.key = i.key
+			)
+		ORDER BY i.dataset, i.key
+	`, tradeDate)
+}
+
+func queryDailyRunItems(db *sql.DB, query string, args ...any) ([]DailyRunItem, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]DailyRunItem, 0, 32)
+	for rows.Next() {
+		var it DailyRunItem
+		var errMsg, updatedAt sql.NullString
+		if err := rows.Scan(&it.RunID, &it.Dataset, &it.Key, &it.Status, &errMsg, &it.Attempts, &updatedAt); err != nil {
+			return nil, err
+		}
+		it.Error = errMsg.String
+		it.UpdatedAt = updatedAt.String
+		out = append(out, it)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,80 @@
+package sqlite
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDailyRunItems(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "aof.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+
+	t0 := time.Date(2024, 1, 2, 8, 0, 0, 0, time.UTC)
+	run1, err := CreateDailyRun(db, "2024-01-02", "schedule", t0, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, it := range []struct{ dataset, key, status, err string }{
+		{"northbound", "", DailyItemOK, ""},
+		{"margin", "600519.SH", DailyItemFailed, "timeout"},
+		{"margin", "000001.SZ", DailyItemFailed, "timeout"},
+		// A retry inside the same run overwrites the outcome and counts the attempt.
+		{"margin", "000001.SZ", DailyItemOK, ""},
+	} {
+		if err := UpsertDailyRunItem(db, run1, it.dataset, it.key, it.status, it.err, t0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := FinishDailyRun(db, run1, t0.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	runs, err := QueryDailyRuns(db, "2024-01-02", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 1 || runs[0].Status != "partial" || runs[0].OK != 2 || runs[0].Failed != 1 || runs[0].FinishedAt == "" {
+		t.Fatalf("runs=%+v", runs)
+	}
+	items, err := QueryDailyRunItems(db, run1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 3 || items[0].Key != "000001.SZ" || items[0].Attempts != 2 || items[0].Status != DailyItemOK {
+		t.Fatalf("items=%+v", items)
+	}
+
+	// A later run fixing the failed item supersedes the failure in the per-date status.
+	run2, err := CreateDailyRun(db, "2024-01-02", "retry", t0.Add(time.Hour), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := UpsertDailyRunItem(db, run2, "margin", "600519.SH", DailyItemOK, "", t0.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := FinishDailyRun(db, run2, t0.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	status, err := QueryDailyItemStatus(db, "2024-01-02")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, it := range status {
+		if it.Status != DailyItemOK {
+			t.Fatalf("item %s/%s still %s", it.Dataset, it.Key, it.Status)
+		}
+	}
+	if len(status) != 3 || status[1].RunID != run2 {
+		t.Fatalf("status=%+v", status)
+	}
+	if runs, _ := QueryDailyRuns(db, "2024-01-02", 10); runs[0].ID != run2 || runs[0].Status != "ok" {
+		t.Fatalf("latest run=%+v", runs[0])
+	}
+}
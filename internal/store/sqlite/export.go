@@ -0,0 +1,274 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ExportQuery selects daily or intraday history for `aof export` and /api/v1/export.
+type ExportQuery struct {
+	Dataset string // see ExportDatasets
+	// Codes restricts the rows: sources for market_agg(_rt), board types for board_sum(_rt) and
+	// board_price_sum_rt, board or stock codes for board_daily and fundflow. Empty means all;
+	// northbound has no codes.
+	Codes     []string
+	FID       string // every dataset but fundflow and northbound; default f62
+	BoardType string // board_daily only; empty means both
+	From, To  string // trade_date range (YYYY-MM-DD), inclusive; empty is unbounded
+}
+
+// ExportTable is the result of QueryExport: database column names and one row per trade date
+// or snapshot (and code). Cells are a string for key columns and a float64 or nil (NULL) for values.
+type ExportTable struct {
+	Columns []string
+	Rows    [][]any
+}
+
+type exportSpec struct {
+	table  string
+	keys   []string // text columns, ordered trade_date first
+	values []string // REAL columns
+	code   string   // column Codes filters on
+	fid    bool     // table has a fid column
+	sum    bool     // sum values over the other keys (board_sum)
+}
+
+var exportSpecs = map[string]exportSpec{
+	"market_agg": {table: "market_agg_daily", keys: []string{"trade_date", "source", "fid"}, values: []string{"value"},
+		code: "source", fid: true},
+	"board_sum": {table: "board_daily", keys: []string{"trade_date", "board_type", "fid"}, values: []string{"value"},
+		code: "board_type", fid: true, sum: true},
+	"board_daily": {table: "board_daily", keys: []string{"trade_date", "board_type", "fid", "code", "name"},
+		values: []string{"price", "pct", "value"}, code: "code", fid: true},
+	"fundflow": {table: "fundflow_daily", keys: []string{"trade_date", "code", "name"},
+		values: []string{"net_main", "net_xl", "net_l", "net_m", "net_s"}, code: "code"},
+	"northbound": {table: "northbound_daily", keys: []string{"trade_date"},
+		values: []string{
+			"sh_day_net_amt_in", "sh_net_buy_amt", "sh_buy_amt", "sh_sell_amt", "sh_buy_sell_amt",
+			"sz_day_net_amt_in", "sz_net_buy_amt", "sz_buy_amt", "sz_sell_amt", "sz_buy_sell_amt",
+		}},
+}
+
+// exportRTSpecs are the intraday datasets behind the history endpoints' kind=rt and
+// board_price_sum: one row per persisted snapshot (and code) whose Asia/Shanghai trade date is
+// within From..To, keyed by trade_date, time (HH:MM:SS), code column and fid.
+type exportRTSpec struct {
+	code  string // key column Codes filters on
+	value string
+	// codes lists every code when Codes is empty.
+	codes func(db *sql.DB, fid string) ([]string, error)
+	// points returns the series of one code with fromUTC <= ts_utc <= toUTC, oldest first.
+	points func(db *sql.DB, code, fid, fromUTC, toUTC string) ([]BoardSumRTPoint, error)
+}
+
+var exportBoardTypes = func(*sql.DB, string) ([]string, error) { return []string{"concept", "industry"}, nil }
+
+var exportRTSpecs = map[string]exportRTSpec{
+	"market_agg_rt": {code: "source", value: "value",
+		codes: func(db *sql.DB, fid string) ([]string, error) {
+			rows, err := db.Query(`SELECT DISTINCT source FROM market_agg_rt WHERE fid = ? ORDER BY source`, fid)
+			if err != nil {
+				return nil, err
+			}
+			defer rows.Close()
+			var out []string
+			for rows.Next() {
+				var s string
+				if err := rows.Scan(&s); err != nil {
+					return nil, err
+				}
+				out = append(out, s)
+			}
+			return out, rows.Err()
+		},
+		points: func(db *sql.DB, source, fid, fromUTC, toUTC string) ([]BoardSumRTPoint, error) {
+			return queryBoardSums(db, `
+				SELECT ts_utc, value FROM market_agg_rt
+				WHERE source = ? AND fid = ? AND ts_utc >= ? AND ts_utc <= ?
+				ORDER BY ts_utc
+			`, source, fid, fromUTC, toUTC)
+		}},
+	"board_sum_rt": {code: "board_type", value: "value", codes: exportBoardTypes,
+		points: func(db *sql.DB, boardType, fid, fromUTC, toUTC string) ([]BoardSumRTPoint, error) {
+			return boardSumsBetween(db, boardType, fid, "value", fromUTC, toUTC, 0)
+		}},
+	"board_price_sum_rt": {code: "board_type", value: "price", codes: exportBoardTypes,
+		points: func(db *sql.DB, boardType, fid, fromUTC, toUTC string) ([]BoardSumRTPoint, error) {
+			return boardSumsBetween(db, boardType, fid, "price", fromUTC, toUTC, 0)
+		}},
+}
+
+// ErrInvalidExport wraps the errors of an ExportQuery that can't be answered (unknown dataset,
+// codes on a dataset without codes).
+var ErrInvalidExport = errors.New("invalid export")
+
+// ExportDatasets lists the dataset names QueryExport accepts.
+func ExportDatasets() []string {
+	out := make([]string, 0, len(exportSpecs)+len(exportRTSpecs))
+	for k := range exportSpecs {
+		out = append(out, k)
+	}
+	for k := range exportRTSpecs {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func QueryExport(db *sql.DB, q ExportQuery) (ExportTable, error) {
+	if spec, ok := exportRTSpecs[q.Dataset]; ok {
+		return queryExportRT(db, spec, q)
+	}
+	spec, ok := exportSpecs[q.Dataset]
+	if !ok {
+		return ExportTable{}, fmt.Errorf("%w: unknown dataset %q (want one of %s)", ErrInvalidExport, q.Dataset, strings.Join(ExportDatasets(), ", "))
+	}
+	if len(q.Codes) > 0 && spec.code == "" {
+		return ExportTable{}, fmt.Errorf("%w: %s has no codes to filter on", ErrInvalidExport, q.Dataset)
+	}
+
+	sel := append([]string(nil), spec.keys...)
+	for _, v := range spec.values {
+		if spec.sum {
+			v = "SUM(" + v + ")"
+		}
+		sel = append(sel, v)
+	}
+	where := []string{"1 = 1"}
+	var args []any
+	if q.From != "" {
+		where = append(where, "trade_date >= ?")
+		args = append(args, q.From)
+	}
+	if q.To != "" {
+		where = append(where, "trade_date <= ?")
+		args = append(args, q.To)
+	}
+	if spec.fid {
+		fid := q.FID
+		if fid == "" {
+			fid = "f62"
+		}
+		where = append(where, "fid = ?")
+		args = append(args, fid)
+	}
+	if q.BoardType != "" && q.Dataset == "board_daily" {
+		where = append(where, "board_type = ?")
+		args = append(args, q.BoardType)
+	}
+	if len(q.Codes) > 0 {
+		where = append(where, spec.code+" IN (?"+strings.Repeat(", ?", len(q.Codes)-1)+")")
+		for _, c := range q.Codes {
+			args = append(args, c)
+		}
+	}
+	query := `SELECT ` + strings.Join(sel, ", ") + ` FROM ` + spec.table + ` WHERE ` + strings.Join(where, " AND ")
+	if spec.sum {
+		query += ` GROUP BY ` + strings.Join(spec.keys, ", ")
+	}
+	query += ` ORDER BY trade_date`
+	if spec.code != "" {
+		query += `, ` + spec.code
+	}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return ExportTable{}, err
+	}
+	defer rows.Close()
+
+	out := ExportTable{Columns: append(append([]string(nil), spec.keys...), spec.values...)}
+	keys := make([]sql.NullString, len(spec.keys))
+	vals := make([]sql.NullFloat64, len(spec.values))
+	dest := make([]any, 0, len(out.Columns))
+	for i := range keys {
+		dest = append(dest, &keys[i])
+	}
+	for i := range vals {
+		dest = append(dest, &vals[i])
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return ExportTable{}, err
+		}
+		row := make([]any, 0, len(out.Columns))
+		for _, k := range keys {
+			row = append(row, k.String)
+		}
+		for _, v := range vals {
+			if v.Valid {
+				row = append(row, v.Float64)
+			} else {
+				row = append(row, nil)
+			}
+		}
+		out.Rows = append(out.Rows, row)
+	}
+	return out, rows.Err()
+}
+
+func queryExportRT(db *sql.DB, spec exportRTSpec, q ExportQuery) (ExportTable, error) {
+	fid := q.FID
+	if fid == "" {
+		fid = "f62"
+	}
+	codes := q.Codes
+	if len(codes) == 0 {
+		var err error
+		if codes, err = spec.codes(db, fid); err != nil {
+			return ExportTable{}, err
+		}
+	}
+	// Trade dates are Asia/Shanghai days: [From 00:00, To+1 00:00) in UTC.
+	fromUTC, toUTC := "", maxTSUTC
+	if q.From != "" {
+		d, err := time.ParseInLocation("2006-01-02", q.From, partitionZone)
+		if err != nil {
+			return ExportTable{}, fmt.Errorf("%w: from: %v", ErrInvalidExport, err)
+		}
+		fromUTC = fixedRFC3339Nano(d)
+	}
+	if q.To != "" {
+		d, err := time.ParseInLocation("2006-01-02", q.To, partitionZone)
+		if err != nil {
+			return ExportTable{}, fmt.Errorf("%w: to: %v", ErrInvalidExport, err)
+		}
+		toUTC = fixedRFC3339Nano(d.AddDate(0, 0, 1).Add(-time.Nanosecond))
+	}
+
+	type point struct {
+		ts, code string
+		v        float64
+	}
+	var pts []point
+	for _, code := range codes {
+		series, err := spec.points(db, code, fid, fromUTC, toUTC)
+		if err != nil {
+			return ExportTable{}, err
+		}
+		for _, p := range series {
+			pts = append(pts, point{p.TSUTC, code, p.Value})
+		}
+	}
+	sort.SliceStable(pts, func(i, j int) bool {
+		if pts[i].ts != pts[j].ts {
+			return pts[i].ts < pts[j].ts
+		}
+		return pts[i].code < pts[j].code
+	})
+
+	out := ExportTable{Columns: []string{"trade_date", "time", spec.code, "fid", spec.value}}
+	for _, p := range pts {
+		ts, err := time.Parse(time.RFC3339Nano, p.ts)
+		if err != nil {
+			return ExportTable{}, err
+		}
+		local := ts.In(partitionZone)
+		out.Rows = append(out.Rows, []any{local.Format("2006-01-02"), local.Format("15:04:05"), p.code, fid, p.v})
+	}
+	return out, nil
+}
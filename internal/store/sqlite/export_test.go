@@ -0,0 +1,67 @@
+package sqlite
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/eastmoney"
+)
+
+func TestQueryExportRT(t *testing.T) {
+	db := openTestDB(t)
+	boards := []eastmoney.TopItem{{Code: "BK0001", Price: 10, Value: 1}, {Code: "BK0002", Price: 20, Value: 2}}
+	// 01:30 UTC = 09:30 Asia/Shanghai; day 2 is stored compact.
+	for _, w := range []struct {
+		ts      time.Time
+		compact bool
+	}{
+		{time.Date(2024, 1, 2, 1, 30, 0, 0, time.UTC), false},
+		{time.Date(2024, 1, 3, 1, 30, 0, 0, time.UTC), true},
+	} {
+		_, err := WriteRTSnapshot(db, w.ts, RTWrite{
+			Boards:        map[string][]eastmoney.TopItem{"industry:f62": boards},
+			CompactBoards: w.compact,
+			Agg:           map[string]float64{"industry_sum:f62": 3, "all:f62": 5},
+			Datasets:      []string{"board:industry:f62", "agg:industry_sum:f62", "agg:all:f62"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := QueryExport(db, ExportQuery{Dataset: "board_price_sum_rt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ExportTable{
+		Columns: []string{"trade_date", "time", "board_type", "fid", "price"},
+		Rows: [][]any{
+			{"2024-01-02", "09:30:00", "industry", "f62", 30.0},
+			{"2024-01-03", "09:30:00", "industry", "f62", 30.0},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("board_price_sum_rt = %+v", got)
+	}
+
+	got, err = QueryExport(db, ExportQuery{Dataset: "board_sum_rt", Codes: []string{"industry"}, From: "2024-01-03", To: "2024-01-03"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Rows) != 1 || got.Rows[0][0] != "2024-01-03" || got.Rows[0][4] != 3.0 {
+		t.Fatalf("board_sum_rt = %+v", got.Rows)
+	}
+
+	got, err = QueryExport(db, ExportQuery{Dataset: "market_agg_rt", To: "2024-01-02"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantRows := [][]any{
+		{"2024-01-02", "09:30:00", "all", "f62", 5.0},
+		{"2024-01-02", "09:30:00", "industry_sum", "f62", 3.0},
+	}
+	if !reflect.DeepEqual(got.Rows, wantRows) || got.Columns[2] != "source" {
+		t.Fatalf("market_agg_rt = %+v", got)
+	}
+}
@@ -0,0 +1,166 @@
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+)
+
+// FundflowDelta is the change of a stock's cumulative "today net" values between two persisted samples.
+type FundflowDelta struct {
+	Code      string
+	Name      string
+	Source    string    // "watchlist" | "toplist"
+	PrevTSUTC time.Time // zero on the first sample of a trade date
+	DtSec     float64
+	DMain     float64
+	DXL       float64
+	DL        float64
+	DM        float64
+	DS        float64
+	NetMain   float64 // cumulative value at tsUTC
+}
+
+type FlowVelocity struct {
+	Code    string  `json:"code"`
+	Name    string  `json:"name"`
+	Source  string  `json:"source"`
+	NetMain float64 `json:"net_main"`
+	// Inflow is the main net inflow within the window, Prev the one in the window before it.
+	Inflow float64 `json:"inflow"`
+	Prev   float64 `json:"prev"`
+	// Rate is Inflow per minute; Accel is (Inflow-Prev) per minute.
+	Rate    float64 `json:"rate"`
+	Accel   float64 `json:"accel"`
+	Samples int     `json:"samples"`
+}
+
+// flowVelocityOrderBy maps API sort keys to SQL; keys not listed fall back to "accel".
+var flowVelocityOrderBy = map[string]string{
+	"accel":   "(cur - prev) DESC",
+	"decel":   "(cur - prev) ASC",
+	"inflow":  "cur DESC",
+	"outflow": "cur ASC",
+}
+
+func UpsertFundflowDelta(db *sql.DB, tsUTC time.Time, tradeDate string, rows []FundflowDelta) error {
+	return withTx(db, func(tx *sql.Tx) error { return upsertFundflowDelta(tx, tsUTC, tradeDate, rows) })
+}
+
+func upsertFundflowDelta(tx *sql.Tx, tsUTC time.Time, tradeDate string, rows []FundflowDelta) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	stmt, err := tx.Prepare(`
+		INSERT INTO fundflow_delta(
+			ts_utc, code, trade_date, name, source, prev_ts_utc, dt_sec,
+			d_main, d_xl, d_l, d_m, d_s, net_main
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(ts_utc, code) DO UPDATE SET
+			trade_date=excluded.trade_date,
+			name=excluded.name,
+			source=excluded.source,
+			prev_ts_utc=excluded.prev_ts_utc,
+			dt_sec=excluded.dt_sec,
+			d_main=excluded.d_main,
+			d_xl=excluded.d_xl,
+			d_l=excluded.d_l,
+			d_m=excluded.d_m,
+			d_s=excluded.d_s,
+			net_main=excluded.net_main
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	ts := fixedRFC3339Nano(tsUTC)
+	for _, r := range rows {
+		var prev any
+		if !r.PrevTSUTC.IsZero() {
+			prev = fixedRFC3339Nano(r.PrevTSUTC)
+		}
+		if _, err := stmt.Exec(ts, r.Code, tradeDate, r.Name, r.Source, prev, r.DtSec,
+			r.DMain, r.DXL, r.DL, r.DM, r.DS, r.NetMain); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// QueryFundflowVelocity ranks stocks by main net inflow within window ending at the latest delta sample,
+// compared with the window before it. Each delta is attributed to the sample it ends at.
+// flowDeltas writes no row while a stock's flow is unchanged, so a stock with deltas only in the
+// previous window (e.g. halted or gone quiet) is still listed with an inflow of 0 and samples 0.
+// source "" covers both watchlist and toplist stocks.
+func QueryFundflowVelocity(db *sql.DB, window time.Duration, source, orderBy string, limit int) (string, []FlowVelocity, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	order, ok := flowVelocityOrderBy[orderBy]
+	if !ok {
+		order = flowVelocityOrderBy["accel"]
+	}
+
+	var last sql.NullString
+	var tradeDate sql.NullString
+	if err := db.QueryRow(`
+		SELECT ts_utc, trade_date
+		FROM fundflow_delta
+		ORDER BY ts_utc DESC
+		LIMIT 1
+	`).Scan(&last, &tradeDate); err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil, nil
+		}
+		return "", nil, err
+	}
+	end, err := time.Parse(time.RFC3339Nano, last.String)
+	if err != nil {
+		return "", nil, err
+	}
+	curFrom := fixedRFC3339Nano(end.Add(-window))
+	prevFrom := fixedRFC3339Nano(end.Add(-2 * window))
+
+	// The aggregate is wrapped so ORDER BY can use its aliases on every backend.
+	rows, err := db.Query(`
+		SELECT code, name, source, cur, prev, samples, net_main FROM (
+			SELECT code, MAX(name) AS name, MAX(source) AS source,
+				SUM(CASE WHEN ts_utc > ? THEN d_main ELSE 0 END) AS cur,
+				SUM(CASE WHEN ts_utc <= ? THEN d_main ELSE 0 END) AS prev,
+				SUM(CASE WHEN ts_utc > ? THEN 1 ELSE 0 END) AS samples,
+				(SELECT d.net_main FROM fundflow_delta d
+					WHERE d.code = fundflow_delta.code AND d.trade_date = ?
+					ORDER BY d.ts_utc DESC LIMIT 1) AS net_main
+			FROM fundflow_delta
+			WHERE trade_date = ? AND ts_utc > ? AND (? = '' OR source = ?)
+			GROUP BY code
+		) v
+		ORDER BY `+order+`, code
+		LIMIT ?
+	`, curFrom, curFrom, curFrom, tradeDate.String, tradeDate.String, prevFrom, source, source, limit)
+	if err != nil {
+		return "", nil, err
+	}
+	defer rows.Close()
+
+	minutes := window.Minutes()
+	out := make([]FlowVelocity, 0, limit)
+	for rows.Next() {
+		var v FlowVelocity
+		var name, src sql.NullString
+		var netMain sql.NullFloat64
+		if err := rows.Scan(&v.Code, &name, &src, &v.Inflow, &v.Prev, &v.Samples, &netMain); err != nil {
+			return "", nil, err
+		}
+		v.Name = name.String
+		v.Source = src.String
+		v.NetMain = netMain.Float64
+		v.Rate = v.Inflow / minutes
+		v.Accel = (v.Inflow - v.Prev) / minutes
+		out = append(out, v)
+	}
+	if err := rows.Err(); err != nil {
+		return "", nil, err
+	}
+	return last.String, out, nil
+}
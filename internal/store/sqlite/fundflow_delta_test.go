@@ -0,0 +1,41 @@
+package sqlite
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQueryFundflowVelocityKeepsQuietStocks(t *testing.T) {
+	db := openTestDB(t)
+	end := time.Date(2024, 1, 2, 3, 0, 0, 0, time.UTC)
+	write := func(ts time.Time, rows ...FundflowDelta) {
+		t.Helper()
+		if err := UpsertFundflowDelta(db, ts, "2024-01-02", rows); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 600519 keeps flowing; 000001 moved only in the previous window and then stopped.
+	write(end.Add(-8*time.Minute),
+		FundflowDelta{Code: "600519", Source: "watchlist", DMain: 100, NetMain: 100},
+		FundflowDelta{Code: "000001", Source: "watchlist", DMain: 300, NetMain: 300})
+	write(end.Add(-2*time.Minute), FundflowDelta{Code: "600519", Source: "watchlist", DMain: 200, NetMain: 300})
+	write(end, FundflowDelta{Code: "600519", Source: "watchlist", DMain: 100, NetMain: 400})
+	// Outside both windows.
+	write(end.Add(-20*time.Minute), FundflowDelta{Code: "300750", Source: "watchlist", DMain: 50, NetMain: 50})
+
+	last, rows, err := QueryFundflowVelocity(db, 5*time.Minute, "", "decel", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last != fixedRFC3339Nano(end) || len(rows) != 2 {
+		t.Fatalf("last=%s rows=%+v", last, rows)
+	}
+	quiet, busy := rows[0], rows[1]
+	if quiet.Code != "000001" || quiet.Inflow != 0 || quiet.Prev != 300 || quiet.Samples != 0 ||
+		quiet.Accel != -60 || quiet.NetMain != 300 {
+		t.Fatalf("quiet=%+v", quiet)
+	}
+	if busy.Code != "600519" || busy.Inflow != 300 || busy.Prev != 100 || busy.Samples != 2 || busy.Rate != 60 {
+		t.Fatalf("busy=%+v", busy)
+	}
+}
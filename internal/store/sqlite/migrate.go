@@ -0,0 +1,327 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Migration is one numbered schema change. Applied migrations are recorded in schema_migrations
+// and never run again, so statements don't need to be idempotent (ALTER TABLE ADD COLUMN is fine).
+type Migration struct {
+	Version int
+	Name    string
+	Stmts   []string
+	// Run, if set, runs after Stmts in the same transaction, for changes that need code.
+	// It must stick to SQL every backend accepts (see internal/store/postgres).
+	Run func(tx *sql.Tx) error
+}
+
+// migrations are applied in order. Append only: never renumber or edit a released migration.
+var migrations = []Migration{
+	{Version: 1, Name: "baseline", Stmts: baselineSchema},
+	{Version: 2, Name: "northbound quota and turnover", Stmts: []string{
+		`ALTER TABLE northbound_rt ADD COLUMN sh_day_amt_remain REAL;`,
+		`ALTER TABLE northbound_rt ADD COLUMN sh_day_amt_threshold REAL;`,
+		`ALTER TABLE northbound_rt ADD COLUMN sh_buy_sell_amt REAL;`,
+		`ALTER TABLE northbound_rt ADD COLUMN sz_day_amt_remain REAL;`,
+		`ALTER TABLE northbound_rt ADD COLUMN sz_day_amt_threshold REAL;`,
+		`ALTER TABLE northbound_rt ADD COLUMN sz_buy_sell_amt REAL;`,
+		`ALTER TABLE northbound_daily ADD COLUMN sh_buy_sell_amt REAL;`,
+		`ALTER TABLE northbound_daily ADD COLUMN sz_buy_sell_amt REAL;`,
+	}},
+	{Version: 3, Name: "rt rollup bars", Stmts: []string{
+		`CREATE TABLE rt_bars_1m (
+			bar_utc TEXT NOT NULL, -- bar start, fixed-width RFC3339Nano
+			dataset TEXT NOT NULL, -- "board" | "fundflow" | "agg" | "northbound"
+			key TEXT NOT NULL, -- board: type:fid:code, fundflow: code, agg: source:fid, northbound: sh|sz
+			field TEXT NOT NULL,
+			open REAL,
+			high REAL,
+			low REAL,
+			close REAL, -- last sample
+			samples INTEGER,
+			PRIMARY KEY (dataset, key, field, bar_utc)
+		);`,
+		`CREATE INDEX idx_rt_bars_1m_bar ON rt_bars_1m(bar_utc);`,
+		`CREATE TABLE rt_bars_5m (
+			bar_utc TEXT NOT NULL, -- bar start, fixed-width RFC3339Nano
+			dataset TEXT NOT NULL, -- "board" | "fundflow" | "agg" | "northbound"
+			key TEXT NOT NULL, -- board: type:fid:code, fundflow: code, agg: source:fid, northbound: sh|sz
+			field TEXT NOT NULL,
+			open REAL,
+			high REAL,
+			low REAL,
+			close REAL, -- last sample
+			samples INTEGER,
+			PRIMARY KEY (dataset, key, field, bar_utc)
+		);`,
+		`CREATE INDEX idx_rt_bars_5m_bar ON rt_bars_5m(bar_utc);`,
+		`CREATE TABLE rollup_state (
+			period TEXT PRIMARY KEY, -- "1m" | "5m"
+			done_utc TEXT NOT NULL -- raw rows before this are rolled up
+		);`,
+	}},
+	{Version: 4, Name: "rt snapshots", Stmts: []string{
+		`CREATE TABLE rt_snapshots (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			ts_utc TEXT NOT NULL, -- ts_utc of every row the snapshot wrote
+			datasets TEXT NOT NULL, -- comma separated dataset keys written; "" for backfilled rows
+			status TEXT NOT NULL, -- "complete" | "failed" (rolled back, nothing written)
+			error TEXT
+		);`,
+		`CREATE INDEX idx_rt_snapshots_ts ON rt_snapshots(ts_utc);`,
+		// Existing rows predate atomic writes; each distinct ts_utc becomes one snapshot.
+		`INSERT INTO rt_snapshots(ts_utc, datasets, status)
+			SELECT ts_utc, '', 'complete' FROM (
+				SELECT ts_utc FROM northbound_rt
+				UNION SELECT ts_utc FROM fundflow_rt
+				UNION SELECT ts_utc FROM toplist_rt
+				UNION SELECT ts_utc FROM board_rt
+				UNION SELECT ts_utc FROM market_agg_rt
+			) t
+			ORDER BY ts_utc;`,
+	}},
+	{Version: 5, Name: "board_rt day partitions", Stmts: []string{
+		`CREATE TABLE rt_partitions (
+			base_table TEXT NOT NULL, -- logical table, e.g. "board_rt"
+			day TEXT NOT NULL, -- Asia/Shanghai trade day, YYYY-MM-DD
+			table_name TEXT NOT NULL, -- e.g. board_rt_20240102
+			lo_utc TEXT NOT NULL, -- rows have lo_utc <= ts_utc < hi_utc
+			hi_utc TEXT NOT NULL,
+			PRIMARY KEY (base_table, day)
+		);`,
+	}, Run: func(tx *sql.Tx) error {
+		// Existing rows move into day partitions; the unpartitioned table is dropped.
+		return splitIntoPartitions(tx, "board_rt", "ts_utc, board_type, fid, code, name, price, pct, value")
+	}},
+	{Version: 6, Name: "board dictionary", Stmts: []string{
+		// Boards referenced by compact board_rt_blob snapshots; ids are never reused.
+		`CREATE TABLE board_dict (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			code TEXT NOT NULL UNIQUE,
+			name TEXT
+		);`,
+	}},
+	{Version: 7, Name: "security master", Stmts: []string{
+		// Stocks, indices, ETFs and boards for /api/search; refreshed from Eastmoney lists.
+		`CREATE TABLE securities (
+			secid TEXT PRIMARY KEY, -- Eastmoney secid, e.g. 1.600519, 90.BK0457
+			code TEXT NOT NULL,
+			name TEXT NOT NULL,
+			market TEXT NOT NULL, -- "SH" | "SZ" | "BJ" | "BK" (boards)
+			kind TEXT NOT NULL, -- "stock" | "index" | "etf" | "industry" | "concept"
+			pinyin TEXT NOT NULL, -- name initials, e.g. GZMT
+			updated_utc TEXT NOT NULL
+		);`,
+		`CREATE INDEX idx_securities_code ON securities(code);`,
+		`CREATE INDEX idx_securities_pinyin ON securities(pinyin);`,
+	}},
+}
+
+// MigrationState is a migration and when it was applied (empty while pending).
+type MigrationState struct {
+	Version   int    `json:"version"`
+	Name      string `json:"name"`
+	AppliedAt string `json:"applied_at,omitempty"`
+}
+
+// sqliteSetup runs before migrations on every open.
+var sqliteSetup = []string{
+	`PRAGMA journal_mode=WAL;`,
+	`PRAGMA synchronous=NORMAL;`,
+}
+
+// Migrations returns the SQLite migrations in order. Other backends translate them to their own dialect.
+func Migrations() []Migration {
+	return append([]Migration(nil), migrations...)
+}
+
+func ensureMigrationsTable(db *sql.DB, setup []string) error {
+	stmts := append(append([]string(nil), setup...), `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TEXT NOT NULL
+	);`)
+	for _, s := range stmts {
+		if _, err := db.Exec(s); err != nil {
+			return fmt.Errorf("migrate: %w", err)
+		}
+	}
+	return nil
+}
+
+// MigrationStatus lists every known migration with its applied time.
+func MigrationStatus(db *sql.DB) ([]MigrationState, error) {
+	return MigrationStatusOf(db, migrations, sqliteSetup)
+}
+
+// MigrationStatusOf is MigrationStatus for the migration list ms of any backend;
+// setup statements run before schema_migrations is read.
+func MigrationStatusOf(db *sql.DB, ms []Migration, setup []string) ([]MigrationState, error) {
+	if err := ensureMigrationsTable(db, setup); err != nil {
+		return nil, err
+	}
+	rows, err := db.Query(`SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]string)
+	for rows.Next() {
+		var v int
+		var at string
+		if err := rows.Scan(&v, &at); err != nil {
+			return nil, err
+		}
+		applied[v] = at
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	out := make([]MigrationState, 0, len(ms))
+	for _, m := range byVersion(ms) {
+		out = append(out, MigrationState{Version: m.Version, Name: m.Name, AppliedAt: applied[m.Version]})
+	}
+	return out, nil
+}
+
+func pendingMigrations(db *sql.DB, ms []Migration, setup []string) ([]Migration, error) {
+	status, err := MigrationStatusOf(db, ms, setup)
+	if err != nil {
+		return nil, err
+	}
+	var out []Migration
+	for i, m := range byVersion(ms) {
+		if status[i].AppliedAt == "" {
+			out = append(out, m)
+		}
+	}
+	return out, nil
+}
+
+// byVersion returns ms sorted by version, whatever order a backend lists them in.
+func byVersion(ms []Migration) []Migration {
+	out := append([]Migration(nil), ms...)
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+	return out
+}
+
+// Migrate applies all pending migrations, each in its own transaction.
+func Migrate(db *sql.DB) error {
+	if err := recordBaseline(db); err != nil {
+		return err
+	}
+	return RunMigrations(db, migrations, sqliteSetup)
+}
+
+// baselineTables are the tables created by baselineSchema.
+var baselineTables = func() []string {
+	re := regexp.MustCompile(`^CREATE TABLE IF NOT EXISTS (\w+)`)
+	var out []string
+	for _, s := range baselineSchema {
+		if m := re.FindStringSubmatch(s); m != nil {
+			out = append(out, m[1])
+		}
+	}
+	return out
+}()
+
+// recordBaseline marks migration 1 as applied, without running it, on a database created before
+// versioned migrations that already has every baseline table. Older databases missing some of them
+// run the (idempotent) baseline instead.
+func recordBaseline(db *sql.DB) error {
+	if err := ensureMigrationsTable(db, sqliteSetup); err != nil {
+		return err
+	}
+	var applied int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM schema_migrations`).Scan(&applied); err != nil || applied > 0 {
+		return err
+	}
+	args := make([]any, len(baselineTables))
+	for i, name := range baselineTables {
+		args[i] = name
+	}
+	var existing int
+	if err := db.QueryRow(`
+		SELECT COUNT(*) FROM sqlite_master
+		WHERE type = 'table' AND name IN (?`+strings.Repeat(", ?", len(args)-1)+`)
+	`, args...).Scan(&existing); err != nil {
+		return err
+	}
+	if existing < len(baselineTables) {
+		return nil
+	}
+	m := migrations[0]
+	_, err := db.Exec(`INSERT INTO schema_migrations(version, name, applied_at) VALUES (?, ?, ?)`,
+		m.Version, m.Name, time.Now().UTC().Format(time.RFC3339))
+	return err
+}
+
+// RunMigrations is Migrate for the migration list ms of any backend.
+func RunMigrations(db *sql.DB, ms []Migration, setup []string) error {
+	pending, err := pendingMigrations(db, ms, setup)
+	if err != nil {
+		return err
+	}
+	for _, m := range pending {
+		if err := applyMigration(db, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applyMigration(db *sql.DB, m Migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, s := range m.Stmts {
+		if _, err := tx.Exec(s); err != nil {
+			return fmt.Errorf("migrate %d (%s): %w", m.Version, m.Name, err)
+		}
+	}
+	if m.Run != nil {
+		if err := m.Run(tx); err != nil {
+			return fmt.Errorf("migrate %d (%s): %w", m.Version, m.Name, err)
+		}
+	}
+	if _, err := tx.Exec(`INSERT INTO schema_migrations(version, name, applied_at) VALUES (?, ?, ?)`,
+		m.Version, m.Name, time.Now().UTC().Format(time.RFC3339)); err != nil {
+		return fmt.Errorf("migrate %d (%s): %w", m.Version, m.Name, err)
+	}
+	return tx.Commit()
+}
+
+// MigrateWithBackup is Migrate for an existing database file: if anything is pending and the
+// database already holds tables, it is first copied next to dbPath. backup is "" if no copy was made.
+func MigrateWithBackup(db *sql.DB, dbPath string) (backup string, err error) {
+	if err := recordBaseline(db); err != nil {
+		return "", err
+	}
+	pending, err := pendingMigrations(db, migrations, sqliteSetup)
+	if err != nil || len(pending) == 0 {
+		return "", err
+	}
+	var tables int
+	if err := db.QueryRow(`
+		SELECT COUNT(*) FROM sqlite_master
+		WHERE type = 'table' AND name NOT IN ('schema_migrations', 'sqlite_sequence')
+	`).Scan(&tables); err != nil {
+		return "", err
+	}
+	if tables > 0 {
+		backup = fmt.Sprintf("%s.pre-v%d-%s.bak", dbPath, pending[0].Version, time.Now().UTC().Format("20060102T150405Z"))
+		if err := BackupTo(db, backup); err != nil {
+			return "", fmt.Errorf("pre-migration backup: %w", err)
+		}
+	}
+	return backup, Migrate(db)
+}
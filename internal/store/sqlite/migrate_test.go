@@ -0,0 +1,156 @@
+package sqlite
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRunMigrations(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "aof.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var ran []int
+	step := func(v int, name string) Migration {
+		return Migration{Version: v, Name: name, Stmts: []string{`CREATE TABLE t` + name + ` (x INTEGER);`},
+			Run: func(tx *sql.Tx) error { ran = append(ran, v); return nil }}
+	}
+	// Listed out of order; version 2 is recorded as applied already.
+	ms := []Migration{step(3, "c"), step(1, "a"), step(2, "b")}
+	if _, err := db.Exec(`CREATE TABLE schema_migrations (version INTEGER PRIMARY KEY, name TEXT NOT NULL, applied_at TEXT NOT NULL);
+		INSERT INTO schema_migrations VALUES (2, 'b', '2024-01-02T00:00:00Z');`); err != nil {
+		t.Fatal(err)
+	}
+
+	status, err := MigrationStatusOf(db, ms, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []MigrationState{{Version: 1, Name: "a"}, {Version: 2, Name: "b", AppliedAt: "2024-01-02T00:00:00Z"}, {Version: 3, Name: "c"}}
+	if !reflect.DeepEqual(status, want) {
+		t.Fatalf("status = %+v, want %+v", status, want)
+	}
+
+	if err := RunMigrations(db, ms, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ran, []int{1, 3}) {
+		t.Fatalf("ran %v, want [1 3]", ran)
+	}
+	var tb int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name = 'tb'`).Scan(&tb); err != nil || tb != 0 {
+		t.Fatalf("skipped migration ran its statements: %d %v", tb, err)
+	}
+	status, err = MigrationStatusOf(db, ms, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, st := range status {
+		if st.AppliedAt == "" {
+			t.Fatalf("%+v still pending", st)
+		}
+	}
+
+	// Nothing is pending any more.
+	if err := RunMigrations(db, ms, nil); err != nil || len(ran) != 2 {
+		t.Fatalf("rerun ran %v err=%v", ran, err)
+	}
+}
+
+func TestMigrateWithBackup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "aof.db")
+	db, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	backups := func() []string {
+		t.Helper()
+		m, err := filepath.Glob(path + ".pre-*.bak")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return m
+	}
+
+	// A new database has nothing worth copying.
+	if bak, err := MigrateWithBackup(db, path); err != nil || bak != "" || len(backups()) != 0 {
+		t.Fatalf("fresh: backup=%q err=%v files=%v", bak, err, backups())
+	}
+	// Nothing pending: no copy either.
+	if bak, err := MigrateWithBackup(db, path); err != nil || bak != "" || len(backups()) != 0 {
+		t.Fatalf("current: backup=%q err=%v files=%v", bak, err, backups())
+	}
+
+	// Roll the last migration back by hand; the next run copies the database first.
+	last := migrations[len(migrations)-1]
+	if _, err := db.Exec(`DROP TABLE securities; DELETE FROM schema_migrations WHERE version = ?`, last.Version); err != nil {
+		t.Fatal(err)
+	}
+	bak, err := MigrateWithBackup(db, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if files := backups(); len(files) != 1 || files[0] != bak || !strings.Contains(bak, ".pre-v7-") {
+		t.Fatalf("backup=%q files=%v", bak, files)
+	}
+	if _, err := os.Stat(bak); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`SELECT COUNT(*) FROM securities`); err != nil {
+		t.Fatalf("migration 7 not reapplied: %v", err)
+	}
+}
+
+func TestMigrateBaselinesPreMigrationDB(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "aof.db")
+	db, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// A database from before versioned migrations: the baseline tables, no schema_migrations.
+	for _, s := range baselineSchema {
+		if _, err := db.Exec(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Dropped so a rerun of the baseline DDL would show: it would create the index again.
+	if _, err := db.Exec(`DROP INDEX idx_auction_rt_date_phase;
+		INSERT INTO northbound_rt(ts_utc, trade_date, sh_net_buy_amt) VALUES ('2024-01-02T02:00:00Z', '2024-01-02', 5);`); err != nil {
+		t.Fatal(err)
+	}
+
+	bak, err := MigrateWithBackup(db, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(bak, ".pre-v2-") {
+		t.Fatalf("backup %q, want one taken before migration 2", bak)
+	}
+	status, err := MigrationStatus(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, st := range status {
+		if st.AppliedAt == "" {
+			t.Fatalf("%+v still pending", st)
+		}
+	}
+	var idx int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name = 'idx_auction_rt_date_phase'`).Scan(&idx); err != nil || idx != 0 {
+		t.Fatalf("baseline DDL ran again: %d %v", idx, err)
+	}
+	var net float64
+	var remain sql.NullFloat64
+	if err := db.QueryRow(`SELECT sh_net_buy_amt, sh_day_amt_remain FROM northbound_rt`).Scan(&net, &remain); err != nil || net != 5 {
+		t.Fatalf("existing row: %v %v", net, err)
+	}
+}
@@ -0,0 +1,195 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Realtime board snapshots are stored in one table per Asia/Shanghai trade day (board_rt_YYYYMMDD,
+// or board_rt_blob_YYYYMMDD in compact mode), registered in rt_partitions. "board_rt" stays the
+// logical name used by callers, archives and rollups. Writes create the day's table on demand,
+// time-bounded queries only touch the days they overlap, and retention drops whole days instead
+// of deleting rows.
+
+// rtPartition is one day table of a partitioned realtime table; it holds rows with LoUTC <= ts_utc < HiUTC.
+type rtPartition struct {
+	Table string
+	Day   string // YYYY-MM-DD
+	LoUTC string
+	HiUTC string
+}
+
+// partitionDDL is the schema of one partition per partitioned table; %[1]s is the partition name.
+// Column types are spelled so both SQLite (REAL affinity) and Postgres accept them unchanged.
+var partitionDDL = map[string][]string{
+	"board_rt": {
+		`CREATE TABLE IF NOT EXISTS %[1]s (
+			ts_utc TEXT NOT NULL,
+			board_type TEXT NOT NULL, -- "industry" | "concept"
+			fid TEXT NOT NULL,
+			code TEXT NOT NULL,
+			name TEXT,
+			price DOUBLE PRECISION,
+			pct DOUBLE PRECISION,
+			value DOUBLE PRECISION,
+			PRIMARY KEY (ts_utc, board_type, fid, code)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_%[1]s_key_ts ON %[1]s(board_type, fid, ts_utc)`,
+		`CREATE INDEX IF NOT EXISTS idx_%[1]s_code_ts ON %[1]s(code, ts_utc)`,
+	},
+	// Compact board snapshots, see board_blob.go.
+	"board_rt_blob": {
+		`CREATE TABLE IF NOT EXISTS %[1]s (
+			ts_utc TEXT NOT NULL,
+			board_type TEXT NOT NULL,
+			fid TEXT NOT NULL,
+			n INTEGER NOT NULL, -- boards in data
+			data BYTEA NOT NULL, -- stored as a plain BLOB by SQLite
+			PRIMARY KEY (ts_utc, board_type, fid)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_%[1]s_key_ts ON %[1]s(board_type, fid, ts_utc)`,
+	},
+}
+
+// maxTSUTC sorts after every fixed-width timestamp; use it for an open-ended range.
+const maxTSUTC = "9999-12-31T23:59:59.999999999Z"
+
+// partitionZone is China Standard Time; a fixed offset (no DST) keeps partition bounds stable
+// even where tzdata isn't installed.
+var partitionZone = time.FixedZone("CST", 8*3600)
+
+func isPartitioned(table string) bool {
+	_, ok := partitionDDL[table]
+	return ok
+}
+
+// partitionFor returns the partition of base that holds rows stamped tsUTC.
+func partitionFor(base string, tsUTC time.Time) rtPartition {
+	t := tsUTC.In(partitionZone)
+	lo := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, partitionZone)
+	return rtPartition{
+		Table: base + "_" + lo.Format("20060102"),
+		Day:   lo.Format("2006-01-02"),
+		LoUTC: fixedRFC3339Nano(lo),
+		HiUTC: fixedRFC3339Nano(lo.AddDate(0, 0, 1)),
+	}
+}
+
+// ensurePartition creates p and registers it; it is a no-op if p exists.
+func ensurePartition(db execer, base string, p rtPartition) error {
+	for _, s := range partitionDDL[base] {
+		if _, err := db.Exec(fmt.Sprintf(s, p.Table)); err != nil {
+			return fmt.Errorf("partition %s: %w", p.Table, err)
+		}
+	}
+	_, err := db.Exec(`
+		INSERT INTO rt_partitions(base_table, day, table_name, lo_utc, hi_utc)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(base_table, day) DO NOTHING
+	`, base, p.Day, p.Table, p.LoUTC, p.HiUTC)
+	return err
+}
+
+// partitionsBetween lists the partitions of base holding rows with fromUTC <= ts_utc <= toUTC,
+// oldest first (newest first with desc).
+func partitionsBetween(db queryer, base, fromUTC, toUTC string, desc bool) ([]rtPartition, error) {
+	order := "ASC"
+	if desc {
+		order = "DESC"
+	}
+	rows, err := db.Query(`
+		SELECT table_name, day, lo_utc, hi_utc
+		FROM rt_partitions
+		WHERE base_table = ? AND hi_utc > ? AND lo_utc <= ?
+		ORDER BY day `+order, base, fromUTC, toUTC)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []rtPartition
+	for rows.Next() {
+		var p rtPartition
+		if err := rows.Scan(&p.Table, &p.Day, &p.LoUTC, &p.HiUTC); err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+// physicalTables resolves table to what holds its rows with fromUTC <= ts_utc <= toUTC:
+// the overlapping day partitions (oldest first) if it is partitioned, else table itself.
+func physicalTables(db queryer, table, fromUTC, toUTC string) ([]string, error) {
+	if !isPartitioned(table) {
+		return []string{table}, nil
+	}
+	ps, err := partitionsBetween(db, table, fromUTC, toUTC, false)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, 0, len(ps))
+	for _, p := range ps {
+		out = append(out, p.Table)
+	}
+	return out, nil
+}
+
+// partitionAt returns the partition of base holding tsUTC (a fixed-width timestamp), if it exists.
+func partitionAt(db queryer, base, tsUTC string) (rtPartition, bool, error) {
+	ps, err := partitionsBetween(db, base, tsUTC, tsUTC, false)
+	if err != nil || len(ps) == 0 {
+		return rtPartition{}, false, err
+	}
+	return ps[0], true, nil
+}
+
+// dropPartition removes p and its registration.
+func dropPartition(db *sql.DB, base string, p rtPartition) error {
+	return withTx(db, func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`DROP TABLE IF EXISTS ` + p.Table); err != nil {
+			return err
+		}
+		_, err := tx.Exec(`DELETE FROM rt_partitions WHERE base_table = ? AND day = ?`, base, p.Day)
+		return err
+	})
+}
+
+// splitIntoPartitions moves every row of the unpartitioned table base into day partitions and
+// drops it. It runs once, in the migration that introduced partitioning for base.
+func splitIntoPartitions(tx *sql.Tx, base, cols string) error {
+	var minTS, maxTS sql.NullString
+	if err := tx.QueryRow(fmt.Sprintf(`SELECT MIN(ts_utc), MAX(ts_utc) FROM %s`, base)).Scan(&minTS, &maxTS); err != nil {
+		return err
+	}
+	if minTS.Valid {
+		first, err := time.Parse(time.RFC3339Nano, minTS.String)
+		if err != nil {
+			return err
+		}
+		for p := partitionFor(base, first); p.LoUTC <= maxTS.String; {
+			var n int
+			if err := tx.QueryRow(fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE ts_utc >= ? AND ts_utc < ?`, base),
+				p.LoUTC, p.HiUTC).Scan(&n); err != nil {
+				return err
+			}
+			if n > 0 {
+				if err := ensurePartition(tx, base, p); err != nil {
+					return err
+				}
+				if _, err := tx.Exec(fmt.Sprintf(`INSERT INTO %s(%s) SELECT %s FROM %s WHERE ts_utc >= ? AND ts_utc < ?`,
+					p.Table, cols, cols, base), p.LoUTC, p.HiUTC); err != nil {
+					return err
+				}
+			}
+			hi, err := time.Parse(time.RFC3339Nano, p.HiUTC)
+			if err != nil {
+				return err
+			}
+			p = partitionFor(base, hi)
+		}
+	}
+	_, err := tx.Exec(`DROP TABLE ` + base)
+	return err
+}
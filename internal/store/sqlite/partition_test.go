@@ -0,0 +1,26 @@
+package sqlite
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPartitionFor(t *testing.T) {
+	// 17:00 UTC is already the next day in Asia/Shanghai.
+	p := partitionFor("board_rt", time.Date(2024, 1, 1, 17, 0, 0, 0, time.UTC))
+	want := rtPartition{
+		Table: "board_rt_20240102",
+		Day:   "2024-01-02",
+		LoUTC: "2024-01-01T16:00:00.000000000Z",
+		HiUTC: "2024-01-02T16:00:00.000000000Z",
+	}
+	if p != want {
+		t.Fatalf("partition = %+v, want %+v", p, want)
+	}
+	if q := partitionFor("board_rt", time.Date(2024, 1, 2, 15, 59, 59, 0, time.UTC)); q != want {
+		t.Fatalf("last second of the day = %+v", q)
+	}
+	if q := partitionFor("board_rt", time.Date(2024, 1, 2, 16, 0, 0, 0, time.UTC)); q.Day != "2024-01-03" {
+		t.Fatalf("next day = %+v", q)
+	}
+}
@@ -2,6 +2,7 @@ package sqlite
 
 import (
 	"database/sql"
+	"sort"
 )
 
 type MarketAggRTPoint struct {
@@ -20,18 +21,56 @@ type BoardRTPoint struct {
 }
 
 func QueryBoardRTLatestTimestampByCode(db *sql.DB, code string) (string, error) {
-	var ts sql.NullString
-	if err := db.QueryRow(`
-		SELECT MAX(ts_utc)
-		FROM board_rt
-		WHERE code = ?
-	`, code).Scan(&ts); err != nil {
+	ps, err := partitionsBetween(db, "board_rt", "", maxTSUTC, true)
+	if err != nil {
+		return "", err
+	}
+	var rowTS string
+	for _, p := range ps {
+		var ts sql.NullString
+		if err := db.QueryRow(`
+			SELECT MAX(ts_utc)
+			FROM `+p.Table+`
+			WHERE code = ?
+		`, code).Scan(&ts); err != nil {
+			return "", err
+		}
+		if ts.Valid && ts.String != "" {
+			rowTS = ts.String
+			break
+		}
+	}
+	blobTS, err := latestBoardBlobTSByCode(db, code, rowTS)
+	if err != nil || blobTS == "" {
+		return rowTS, err
+	}
+	return blobTS, nil
+}
+
+// latestBoardBlobTSByCode returns the newest compact snapshot after afterUTC that lists code.
+func latestBoardBlobTSByCode(db *sql.DB, code, afterUTC string) (string, error) {
+	if _, ok, err := boardDictID(db, code); err != nil || !ok {
+		return "", err
+	}
+	ps, err := partitionsBetween(db, "board_rt_blob", afterUTC, maxTSUTC, true)
+	if err != nil {
 		return "", err
 	}
-	if !ts.Valid || ts.String == "" {
-		return "", nil
+	var dict map[int64]boardDictEntry
+	for _, p := range ps {
+		snaps, err := queryBoardBlobs(db, &dict, p.Table, `ts_utc > ? ORDER BY ts_utc DESC`, afterUTC)
+		if err != nil {
+			return "", err
+		}
+		for _, s := range snaps {
+			for _, it := range s.Items {
+				if it.Code == code {
+					return s.TSUTC, nil
+				}
+			}
+		}
 	}
-	return ts.String, nil
+	return "", nil
 }
 
 func QueryMarketAggRT(db *sql.DB, source, fid string, limit int) ([]MarketAggRTPoint, error) {
@@ -100,28 +139,53 @@ func QueryBoardRTSeriesByCode(db *sql.DB, code, startUTC, endUTC string, limit i
 	if limit <= 0 {
 		limit = 1000
 	}
-	rows, err := db.Query(`
-		SELECT ts_utc, price
-		FROM board_rt
-		WHERE code = ? AND ts_utc >= ? AND ts_utc <= ?
-		ORDER BY ts_utc ASC
-		LIMIT ?
-	`, code, startUTC, endUTC, limit)
+	ps, err := partitionsBetween(db, "board_rt", startUTC, endUTC, false)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
 	out := make([]BoardRTPoint, 0, limit)
-	for rows.Next() {
-		var p BoardRTPoint
-		if err := rows.Scan(&p.TSUTC, &p.Value); err != nil {
+	for _, part := range ps {
+		if len(out) >= limit {
+			break
+		}
+		rows, err := db.Query(`
+			SELECT ts_utc, price
+			FROM `+part.Table+`
+			WHERE code = ? AND ts_utc >= ? AND ts_utc <= ?
+			ORDER BY ts_utc ASC
+			LIMIT ?
+		`, code, startUTC, endUTC, limit-len(out))
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var p BoardRTPoint
+			if err := rows.Scan(&p.TSUTC, &p.Value); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			out = append(out, p)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
 			return nil, err
 		}
-		out = append(out, p)
 	}
-	if err := rows.Err(); err != nil {
-		return nil, err
+
+	snaps, err := boardBlobsBetween(db, startUTC, endUTC, false, 0, "")
+	if err != nil || len(snaps) == 0 {
+		return out, err
+	}
+	for _, s := range snaps {
+		for _, it := range s.Items {
+			if it.Code == code {
+				out = append(out, BoardRTPoint{TSUTC: s.TSUTC, Value: it.Price})
+			}
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].TSUTC < out[j].TSUTC })
+	if len(out) > limit {
+		out = out[:limit]
 	}
 	return out, nil
 }
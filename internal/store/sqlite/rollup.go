@@ -0,0 +1,319 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Bar is one OHLC bar of a realtime series, rolled up from the raw *_rt tables.
+// Close is the last sample in the bar.
+type Bar struct {
+	BarUTC  string  `json:"bar_utc"` // bar start
+	Dataset string  `json:"dataset"`
+	Key     string  `json:"key"`
+	Field   string  `json:"field"`
+	Open    float64 `json:"open"`
+	High    float64 `json:"high"`
+	Low     float64 `json:"low"`
+	Close   float64 `json:"close"`
+	Samples int     `json:"samples"`
+}
+
+// BarPeriods maps a period name to its bar table and length.
+var BarPeriods = map[string]struct {
+	Table string
+	Len   time.Duration
+}{
+	"1m": {"rt_bars_1m", time.Minute},
+	"5m": {"rt_bars_5m", 5 * time.Minute},
+}
+
+// barSources lists the raw tables that are rolled up. Each query selects ts_utc, the series key
+// and one value per field for ?1 <= ts_utc < ?2 from table %[1]s (a day partition of a
+// partitioned table), oldest first.
+var barSources = []struct {
+	dataset string
+	table   string
+	fields  []string
+	query   string
+}{
+	{"board", "board_rt", []string{"value", "price"}, `
+		SELECT ts_utc, board_type || ':' || fid || ':' || code, value, price
+		FROM %[1]s WHERE ts_utc >= ?1 AND ts_utc < ?2 ORDER BY ts_utc`},
+	{"fundflow", "fundflow_rt", []string{"net_main", "net_xl", "net_l", "net_m", "net_s"}, `
+		SELECT ts_utc, code, net_main, net_xl, net_l, net_m, net_s
+		FROM %[1]s WHERE ts_utc >= ?1 AND ts_utc < ?2 ORDER BY ts_utc`},
+	{"agg", "market_agg_rt", []string{"value"}, `
+		SELECT ts_utc, source || ':' || fid, value
+		FROM %[1]s WHERE ts_utc >= ?1 AND ts_utc < ?2 ORDER BY ts_utc`},
+	{"northbound", "northbound_rt", []string{"day_net_amt_in"}, `
+		SELECT ts_utc, 'sh', sh_day_net_amt_in FROM %[1]s WHERE ts_utc >= ?1 AND ts_utc < ?2
+		UNION ALL
+		SELECT ts_utc, 'sz', sz_day_net_amt_in FROM %[1]s WHERE ts_utc >= ?1 AND ts_utc < ?2
+		ORDER BY 1`},
+}
+
+// rolledUpTables are the raw tables whose rows must be rolled up before retention deletes them.
+var rolledUpTables = []string{"board_rt", "board_rt_blob", "fundflow_rt", "market_agg_rt", "northbound_rt"}
+
+// barBuilder accumulates samples into bars. Samples of one series may come from two sources
+// (board_rt rows and compact blobs), so open and close follow the sample times, not arrival order.
+type barBuilder struct {
+	period time.Duration
+	bars   map[[4]string]*barAcc
+	order  [][4]string
+}
+
+type barAcc struct {
+	Bar
+	first, last time.Time
+}
+
+func newBarBuilder(period time.Duration) *barBuilder {
+	return &barBuilder{period: period, bars: make(map[[4]string]*barAcc)}
+}
+
+func (b *barBuilder) add(ts time.Time, dataset, key, field string, v float64) {
+	start := fixedRFC3339Nano(ts.UTC().Truncate(b.period))
+	k := [4]string{start, dataset, key, field}
+	bar, ok := b.bars[k]
+	if !ok {
+		b.bars[k] = &barAcc{
+			Bar:   Bar{BarUTC: start, Dataset: dataset, Key: key, Field: field, Open: v, High: v, Low: v, Close: v, Samples: 1},
+			first: ts,
+			last:  ts,
+		}
+		b.order = append(b.order, k)
+		return
+	}
+	if v > bar.High {
+		bar.High = v
+	}
+	if v < bar.Low {
+		bar.Low = v
+	}
+	if ts.Before(bar.first) {
+		bar.Open, bar.first = v, ts
+	}
+	if !ts.Before(bar.last) {
+		bar.Close, bar.last = v, ts
+	}
+	bar.Samples++
+}
+
+func (b *barBuilder) result() []Bar {
+	out := make([]Bar, 0, len(b.order))
+	for _, k := range b.order {
+		out = append(out, b.bars[k].Bar)
+	}
+	return out
+}
+
+// RollupRT rolls raw realtime rows into 1m and 5m bars up to the last complete bar before nowUTC.
+// Progress is kept per period in rollup_state, so it can run any time and only handles new rows.
+func RollupRT(db *sql.DB, nowUTC time.Time) error {
+	for _, name := range []string{"1m", "5m"} {
+		p := BarPeriods[name]
+		if err := rollupPeriod(db, name, p.Table, p.Len, nowUTC); err != nil {
+			return fmt.Errorf("rollup %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func rollupPeriod(db *sql.DB, name, table string, period time.Duration, nowUTC time.Time) error {
+	var done sql.NullString
+	if err := db.QueryRow(`SELECT done_utc FROM rollup_state WHERE period = ?`, name).Scan(&done); err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	var start time.Time
+	if done.Valid {
+		t, err := time.Parse(time.RFC3339Nano, done.String)
+		if err != nil {
+			return err
+		}
+		start = t
+	} else {
+		first, err := earliestRolledUpTS(db)
+		if err != nil || first.IsZero() {
+			return err
+		}
+		start = first.Truncate(period)
+	}
+	end := nowUTC.UTC().Truncate(period)
+
+	// Work in hour-sized windows to bound memory on catch-up runs.
+	for w := start; w.Before(end); {
+		next := w.Add(time.Hour)
+		if next.After(end) {
+			next = end
+		}
+		if err := rollupWindow(db, name, table, period, w, next); err != nil {
+			return err
+		}
+		w = next
+	}
+	return nil
+}
+
+func earliestRolledUpTS(db *sql.DB) (time.Time, error) {
+	var first string
+	for _, tbl := range rolledUpTables {
+		tables, err := physicalTables(db, tbl, "", maxTSUTC)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if len(tables) == 0 {
+			continue
+		}
+		var ts sql.NullString
+		if err := db.QueryRow(fmt.Sprintf(`SELECT MIN(ts_utc) FROM %s`, tables[0])).Scan(&ts); err != nil {
+			return time.Time{}, err
+		}
+		if ts.Valid && (first == "" || ts.String < first) {
+			first = ts.String
+		}
+	}
+	if first == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339Nano, first)
+}
+
+func rollupWindow(db *sql.DB, name, table string, period time.Duration, from, to time.Time) error {
+	b := newBarBuilder(period)
+	fromStr, toStr := fixedRFC3339Nano(from), fixedRFC3339Nano(to)
+	for _, src := range barSources {
+		tables, err := physicalTables(db, src.table, fromStr, toStr)
+		if err != nil {
+			return fmt.Errorf("%s: %w", src.dataset, err)
+		}
+		for _, t := range tables {
+			if err := scanBarSource(db, b, src.dataset, src.fields, fmt.Sprintf(src.query, t), fromStr, toStr); err != nil {
+				return fmt.Errorf("%s: %w", src.dataset, err)
+			}
+		}
+	}
+	snaps, err := boardBlobsBetween(db, fromStr, toStr, false, 0, `ts_utc < ?`, toStr)
+	if err != nil {
+		return fmt.Errorf("board: %w", err)
+	}
+	for _, s := range snaps {
+		ts, err := time.Parse(time.RFC3339Nano, s.TSUTC)
+		if err != nil {
+			continue
+		}
+		for _, it := range s.Items {
+			key := s.key() + ":" + it.Code
+			b.add(ts, "board", key, "value", it.Value)
+			b.add(ts, "board", key, "price", it.Price)
+		}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	stmt, err := tx.Prepare(fmt.Sprintf(`
+		INSERT INTO %s(bar_utc, dataset, key, field, open, high, low, close, samples)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(dataset, key, field, bar_utc) DO UPDATE SET
+			open=excluded.open,
+			high=excluded.high,
+			low=excluded.low,
+			close=excluded.close,
+			samples=excluded.samples
+	`, table))
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, bar := range b.result() {
+		if _, err := stmt.Exec(bar.BarUTC, bar.Dataset, bar.Key, bar.Field, bar.Open, bar.High, bar.Low, bar.Close, bar.Samples); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO rollup_state(period, done_utc) VALUES (?, ?)
+		ON CONFLICT(period) DO UPDATE SET done_utc=excluded.done_utc
+	`, name, toStr); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func scanBarSource(db *sql.DB, b *barBuilder, dataset string, fields []string, query, from, to string) error {
+	rows, err := db.Query(query, from, to)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var tsStr, key string
+	vals := make([]sql.NullFloat64, len(fields))
+	dest := []any{&tsStr, &key}
+	for i := range vals {
+		dest = append(dest, &vals[i])
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return err
+		}
+		ts, err := time.Parse(time.RFC3339Nano, tsStr)
+		if err != nil {
+			continue
+		}
+		for i, f := range fields {
+			if vals[i].Valid {
+				b.add(ts, dataset, key, f, vals[i].Float64)
+			}
+		}
+	}
+	return rows.Err()
+}
+
+// rollupDoneUTC returns the oldest rollup progress across periods, or "" if some period never ran.
+func rollupDoneUTC(db *sql.DB) (string, error) {
+	var n int
+	var minDone sql.NullString
+	if err := db.QueryRow(`SELECT COUNT(*), MIN(done_utc) FROM rollup_state`).Scan(&n, &minDone); err != nil {
+		return "", err
+	}
+	if n < len(BarPeriods) || !minDone.Valid {
+		return "", nil
+	}
+	return minDone.String, nil
+}
+
+// QueryBars returns the bars of one series within [startUTC, endUTC], oldest first.
+func QueryBars(db *sql.DB, period, dataset, key, field, startUTC, endUTC string) ([]Bar, error) {
+	p, ok := BarPeriods[period]
+	if !ok {
+		return nil, fmt.Errorf("unknown bar period: %q", period)
+	}
+	rows, err := db.Query(fmt.Sprintf(`
+		SELECT bar_utc, open, high, low, close, samples
+		FROM %s
+		WHERE dataset = ? AND key = ? AND field = ? AND bar_utc >= ? AND bar_utc <= ?
+		ORDER BY bar_utc ASC
+	`, p.Table), dataset, key, field, startUTC, endUTC)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]Bar, 0, 256)
+	for rows.Next() {
+		bar := Bar{Dataset: dataset, Key: key, Field: field}
+		if err := rows.Scan(&bar.BarUTC, &bar.Open, &bar.High, &bar.Low, &bar.Close, &bar.Samples); err != nil {
+			return nil, err
+		}
+		out = append(out, bar)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
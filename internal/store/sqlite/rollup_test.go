@@ -0,0 +1,42 @@
+package sqlite
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBarBuilder(t *testing.T) {
+	b := newBarBuilder(5 * time.Minute)
+	t0 := time.Date(2024, 1, 2, 1, 30, 10, 0, time.UTC)
+	for i, v := range []float64{3, 5, 1, 2} {
+		b.add(t0.Add(time.Duration(i)*time.Minute), "agg", "industry_sum:f62", "value", v)
+	}
+	b.add(t0.Add(5*time.Minute), "agg", "industry_sum:f62", "value", 9)
+
+	bars := b.result()
+	if len(bars) != 2 {
+		t.Fatalf("bars=%+v", bars)
+	}
+	want := Bar{BarUTC: "2024-01-02T01:30:00.000000000Z", Dataset: "agg", Key: "industry_sum:f62", Field: "value",
+		Open: 3, High: 5, Low: 1, Close: 2, Samples: 4}
+	if bars[0] != want {
+		t.Fatalf("bar=%+v want %+v", bars[0], want)
+	}
+	if bars[1].BarUTC != "2024-01-02T01:35:00.000000000Z" || bars[1].Open != 9 || bars[1].Samples != 1 {
+		t.Fatalf("second bar=%+v", bars[1])
+	}
+}
+
+func TestBarBuilderOutOfOrder(t *testing.T) {
+	// Rows and compact blobs of one series are scanned separately; open/close follow sample time.
+	b := newBarBuilder(time.Minute)
+	t0 := time.Date(2024, 1, 2, 1, 30, 0, 0, time.UTC)
+	b.add(t0.Add(20*time.Second), "board", "industry:f62:BK0477", "value", 2)
+	b.add(t0.Add(40*time.Second), "board", "industry:f62:BK0477", "value", 3)
+	b.add(t0, "board", "industry:f62:BK0477", "value", 1)
+
+	bars := b.result()
+	if len(bars) != 1 || bars[0].Open != 1 || bars[0].Close != 3 || bars[0].Samples != 3 {
+		t.Fatalf("bars=%+v", bars)
+	}
+}
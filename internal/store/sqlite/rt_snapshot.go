@@ -4,11 +4,16 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/pcdogyu/A-Stock-Order-Flow/internal/eastmoney"
 )
 
 type RTSnapshot struct {
+	ID    int64
 	TSUTC string
 
 	Northbound   *eastmoney.NorthboundRT
@@ -18,43 +23,130 @@ type RTSnapshot struct {
 	AggByKey     map[string]float64
 }
 
-// LoadLatestRTSnapshot returns the most recent persisted realtime snapshot.
-// ok is false when no rt tables have data.
+// RTWrite is one persist tick: only the datasets that changed since the previous tick are set.
+type RTWrite struct {
+	Northbound *eastmoney.NorthboundRT
+	Fundflow   []eastmoney.FundflowRT
+	TradeDate  string // of FlowDeltas
+	FlowDeltas []FundflowDelta
+	Toplist    map[string][]eastmoney.TopItem // by fid
+	Boards     map[string][]eastmoney.TopItem // by "board_type:fid"
+	// CompactBoards writes Boards to board_rt_blob (one blob per board_type+fid) instead of board_rt rows.
+	CompactBoards bool
+	Agg           map[string]float64 // by "source:fid"
+	// Datasets are the dataset keys written, recorded in rt_snapshots.
+	Datasets []string
+}
+
+func (w RTWrite) empty() bool {
+	return w.Northbound == nil && len(w.Fundflow) == 0 && len(w.FlowDeltas) == 0 &&
+		len(w.Toplist) == 0 && len(w.Boards) == 0 && len(w.Agg) == 0
+}
+
+// WriteRTSnapshot writes w under tsUTC in a single transaction and records it in rt_snapshots,
+// returning the snapshot id (0 if w is empty). A failed write keeps nothing but a "failed" row.
+func WriteRTSnapshot(db *sql.DB, tsUTC time.Time, w RTWrite) (int64, error) {
+	if w.empty() {
+		return 0, nil
+	}
+	ts := fixedRFC3339Nano(tsUTC)
+	datasets := strings.Join(w.Datasets, ",")
+	var id int64
+	err := withTx(db, func(tx *sql.Tx) error {
+		if w.Northbound != nil {
+			if err := upsertNorthboundRT(tx, tsUTC, *w.Northbound); err != nil {
+				return fmt.Errorf("northbound: %w", err)
+			}
+		}
+		if err := upsertFundflowRT(tx, tsUTC, w.Fundflow); err != nil {
+			return fmt.Errorf("fundflow: %w", err)
+		}
+		if err := upsertFundflowDelta(tx, tsUTC, w.TradeDate, w.FlowDeltas); err != nil {
+			return fmt.Errorf("fundflow delta: %w", err)
+		}
+		for fid, rows := range w.Toplist {
+			if err := upsertTopListRT(tx, tsUTC, fid, rows); err != nil {
+				return fmt.Errorf("toplist %s: %w", fid, err)
+			}
+		}
+		for key, rows := range w.Boards {
+			bt, fid, _ := strings.Cut(key, ":")
+			upsert := upsertBoardRT
+			if w.CompactBoards {
+				upsert = upsertBoardBlob
+			}
+			if err := upsert(tx, tsUTC, bt, fid, rows); err != nil {
+				return fmt.Errorf("board %s: %w", key, err)
+			}
+		}
+		for key, v := range w.Agg {
+			source, fid, _ := strings.Cut(key, ":")
+			if err := upsertMarketAggRT(tx, tsUTC, source, fid, v); err != nil {
+				return fmt.Errorf("agg %s: %w", key, err)
+			}
+		}
+		return tx.QueryRow(`
+			INSERT INTO rt_snapshots(ts_utc, datasets, status) VALUES (?, ?, 'complete')
+			RETURNING id
+		`, ts, datasets).Scan(&id)
+	})
+	if err != nil {
+		_, _ = db.Exec(`INSERT INTO rt_snapshots(ts_utc, datasets, status, error) VALUES (?, ?, 'failed', ?)`,
+			ts, datasets, err.Error())
+		return 0, err
+	}
+	return id, nil
+}
+
+// LoadLatestRTSnapshot returns the last complete persisted realtime snapshot.
+// Persist ticks only write datasets that changed, so each dataset key (toplist fid,
+// board type+fid, agg source+fid) is loaded from its own latest ts_utc at or before the snapshot.
+// Everything is read in one transaction, so a concurrent persist can't mix in newer rows.
 func LoadLatestRTSnapshot(db *sql.DB) (snap RTSnapshot, ok bool, err error) {
-	ts, err := latestRTTimestamp(db)
+	tx, err := db.Begin()
 	if err != nil {
 		return snap, false, err
 	}
-	if ts == "" {
-		return snap, false, nil
+	defer func() { _ = tx.Rollback() }()
+
+	if err := tx.QueryRow(`
+		SELECT id, ts_utc FROM rt_snapshots
+		WHERE status = 'complete'
+		ORDER BY id DESC
+		LIMIT 1
+	`).Scan(&snap.ID, &snap.TSUTC); err != nil {
+		if err == sql.ErrNoRows {
+			return snap, false, nil
+		}
+		return snap, false, err
 	}
-	snap.TSUTC = ts
+	ts := snap.TSUTC
 
-	nb, err := QueryNorthboundRTAt(db, ts)
+	nb, err := queryNorthboundRT(tx, `ts_utc = (SELECT MAX(ts_utc) FROM northbound_rt WHERE ts_utc <= ?)`, ts)
 	if err != nil {
 		return snap, false, err
 	}
 	snap.Northbound = nb
 
-	ff, err := QueryFundflowRTAt(db, ts)
+	ff, err := queryFundflowRT(tx, `ts_utc = (SELECT MAX(ts_utc) FROM fundflow_rt WHERE ts_utc <= ?)`, ts)
 	if err != nil {
 		return snap, false, err
 	}
 	snap.Fundflow = ff
 
-	top, err := QueryToplistRTAt(db, ts)
+	top, err := queryToplistRT(tx, `(fid, ts_utc) IN (SELECT fid, MAX(ts_utc) FROM toplist_rt WHERE ts_utc <= ? GROUP BY fid)`, ts)
 	if err != nil {
 		return snap, false, err
 	}
 	snap.ToplistByFID = top
 
-	boards, err := QueryBoardsRTAt(db, ts)
+	boards, err := latestBoardsRT(tx, ts)
 	if err != nil {
 		return snap, false, err
 	}
 	snap.BoardsByKey = boards
 
-	agg, err := QueryMarketAggRTAt(db, ts)
+	agg, err := queryMarketAggRT(tx, `(source, fid, ts_utc) IN (SELECT source, fid, MAX(ts_utc) FROM market_agg_rt WHERE ts_utc <= ? GROUP BY source, fid)`, ts)
 	if err != nil {
 		return snap, false, err
 	}
@@ -63,41 +155,111 @@ func LoadLatestRTSnapshot(db *sql.DB) (snap RTSnapshot, ok bool, err error) {
 	return snap, true, nil
 }
 
-func latestRTTimestamp(db *sql.DB) (string, error) {
-	tables := []string{
-		"northbound_rt",
-		"fundflow_rt",
-		"toplist_rt",
-		"board_rt",
-		"market_agg_rt",
+// execer and queryer are satisfied by both *sql.DB and *sql.Tx.
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+type queryer interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+func withTx(db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// rtTables are the tables written by a realtime persist tick.
+var rtTables = []string{
+	"northbound_rt",
+	"fundflow_rt",
+	"toplist_rt",
+	"board_rt",
+	"market_agg_rt",
+}
+
+// QueryRTTimestamps returns the distinct persist timestamps of an rt table within [startUTC, endUTC], oldest first.
+func QueryRTTimestamps(db *sql.DB, table, startUTC, endUTC string) ([]string, error) {
+	known := false
+	for _, t := range rtTables {
+		known = known || t == table
 	}
-	var maxTS string
-	for _, tbl := range tables {
-		var ts sql.NullString
-		if err := db.QueryRow(fmt.Sprintf(`SELECT MAX(ts_utc) FROM %s`, tbl)).Scan(&ts); err != nil {
-			return "", err
+	if !known {
+		return nil, fmt.Errorf("unknown rt table: %q", table)
+	}
+	tables, err := physicalTables(db, table, startUTC, endUTC)
+	if err != nil {
+		return nil, err
+	}
+	if table == "board_rt" {
+		blobs, err := physicalTables(db, "board_rt_blob", startUTC, endUTC)
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, blobs...)
+	}
+
+	var out []string
+	for _, t := range tables {
+		rows, err := db.Query(fmt.Sprintf(`
+			SELECT DISTINCT ts_utc
+			FROM %s
+			WHERE ts_utc >= ? AND ts_utc <= ?
+			ORDER BY ts_utc ASC
+		`, t), startUTC, endUTC)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var ts string
+			if err := rows.Scan(&ts); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			out = append(out, ts)
 		}
-		if ts.Valid && ts.String > maxTS {
-			maxTS = ts.String
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
 		}
 	}
-	return maxTS, nil
+	if table == "board_rt" {
+		sort.Strings(out)
+		out = slices.Compact(out)
+	}
+	return out, nil
 }
 
 func QueryNorthboundRTAt(db *sql.DB, tsUTC string) (*eastmoney.NorthboundRT, error) {
+	return queryNorthboundRT(db, `ts_utc = ?`, tsUTC)
+}
+
+func queryNorthboundRT(db queryer, where string, args ...any) (*eastmoney.NorthboundRT, error) {
 	row := db.QueryRow(`
 		SELECT trade_date,
 			sh_day_net_amt_in, sh_net_buy_amt, sh_buy_amt, sh_sell_amt,
-			sz_day_net_amt_in, sz_net_buy_amt, sz_buy_amt, sz_sell_amt
+			sz_day_net_amt_in, sz_net_buy_amt, sz_buy_amt, sz_sell_amt,
+			COALESCE(sh_day_amt_remain, 0), COALESCE(sh_day_amt_threshold, 0), COALESCE(sh_buy_sell_amt, 0),
+			COALESCE(sz_day_amt_remain, 0), COALESCE(sz_day_amt_threshold, 0), COALESCE(sz_buy_sell_amt, 0)
 		FROM northbound_rt
-		WHERE ts_utc = ?
+		WHERE `+where+`
 		LIMIT 1
-	`, tsUTC)
+	`, args...)
 
 	var tradeDate string
 	var shDay, shNet, shBuy, shSell float64
 	var szDay, szNet, szBuy, szSell float64
-	if err := row.Scan(&tradeDate, &shDay, &shNet, &shBuy, &shSell, &szDay, &szNet, &szBuy, &szSell); err != nil {
+	var shRemain, shThreshold, shTurnover, szRemain, szThreshold, szTurnover float64
+	if err := row.Scan(&tradeDate, &shDay, &shNet, &shBuy, &shSell, &szDay, &szNet, &szBuy, &szSell,
+		&shRemain, &shThreshold, &shTurnover, &szRemain, &szThreshold, &szTurnover); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
 		}
@@ -106,26 +268,35 @@ func QueryNorthboundRTAt(db *sql.DB, tsUTC string) (*eastmoney.NorthboundRT, err
 	return &eastmoney.NorthboundRT{
 		TradeDate: tradeDate,
 		SH: eastmoney.NorthboundLeg{
-			DayNetAmtIn: shDay,
-			NetBuyAmt:   shNet,
-			BuyAmt:      shBuy,
-			SellAmt:     shSell,
+			DayNetAmtIn:     shDay,
+			NetBuyAmt:       shNet,
+			BuyAmt:          shBuy,
+			SellAmt:         shSell,
+			DayAmtRemain:    shRemain,
+			DayAmtThreshold: shThreshold,
+			BuySellAmt:      shTurnover,
 		},
 		SZ: eastmoney.NorthboundLeg{
-			DayNetAmtIn: szDay,
-			NetBuyAmt:   szNet,
-			BuyAmt:      szBuy,
-			SellAmt:     szSell,
+			DayNetAmtIn:     szDay,
+			NetBuyAmt:       szNet,
+			BuyAmt:          szBuy,
+			SellAmt:         szSell,
+			DayAmtRemain:    szRemain,
+			DayAmtThreshold: szThreshold,
+			BuySellAmt:      szTurnover,
 		},
 	}, nil
 }
 
 func QueryFundflowRTAt(db *sql.DB, tsUTC string) ([]eastmoney.FundflowRT, error) {
+	return queryFundflowRT(db, `ts_utc = ?`, tsUTC)
+}
+
+func queryFundflowRT(db queryer, where string, args ...any) ([]eastmoney.FundflowRT, error) {
 	rows, err := db.Query(`
 		SELECT code, name, net_main, net_xl, net_l, net_m, net_s
 		FROM fundflow_rt
-		WHERE ts_utc = ?
-	`, tsUTC)
+		WHERE `+where, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -146,12 +317,16 @@ func QueryFundflowRTAt(db *sql.DB, tsUTC string) ([]eastmoney.FundflowRT, error)
 }
 
 func QueryToplistRTAt(db *sql.DB, tsUTC string) (map[string][]eastmoney.TopItem, error) {
+	return queryToplistRT(db, `ts_utc = ?`, tsUTC)
+}
+
+func queryToplistRT(db queryer, where string, args ...any) (map[string][]eastmoney.TopItem, error) {
 	rows, err := db.Query(`
 		SELECT fid, rank, code, name, price, pct, value
 		FROM toplist_rt
-		WHERE ts_utc = ?
+		WHERE `+where+`
 		ORDER BY fid, rank
-	`, tsUTC)
+	`, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -173,39 +348,170 @@ func QueryToplistRTAt(db *sql.DB, tsUTC string) (map[string][]eastmoney.TopItem,
 }
 
 func QueryBoardsRTAt(db *sql.DB, tsUTC string) (map[string][]eastmoney.TopItem, error) {
+	out := make(map[string][]eastmoney.TopItem)
+	for _, base := range boardBases {
+		p, ok, err := partitionAt(db, base, tsUTC)
+		if err != nil {
+			return out, err
+		}
+		if !ok {
+			continue
+		}
+		snaps, err := queryBoardSnaps(db, base, nil, p.Table, `ts_utc = ?`, tsUTC)
+		if err != nil {
+			return out, err
+		}
+		for _, s := range snaps {
+			out[s.key()] = s.Items
+		}
+	}
+	return out, nil
+}
+
+// latestBoardsRT loads every board key from its latest ts_utc at or before tsUTC. A key that
+// didn't change today has its latest rows in an older partition, so partitions of both formats
+// are read newest day first (one snapshot per key each) until every key recorded in rt_snapshots
+// has been seen; the newest snapshot per key wins.
+func latestBoardsRT(db queryer, tsUTC string) (map[string][]eastmoney.TopItem, error) {
+	want, known, err := boardKeysUpTo(db, tsUTC)
+	if err != nil {
+		return nil, err
+	}
+	type basePartition struct {
+		base string
+		rtPartition
+	}
+	var ps []basePartition
+	for _, base := range boardBases {
+		bps, err := partitionsBetween(db, base, "", tsUTC, true)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range bps {
+			ps = append(ps, basePartition{base, p})
+		}
+	}
+	// Both formats share day boundaries; a day is finished before checking whether to stop.
+	sort.SliceStable(ps, func(i, j int) bool { return ps[i].Day > ps[j].Day })
+
+	latest := make(map[string]boardSnap)
+	allSeen := func() bool {
+		for key := range want {
+			if _, ok := latest[key]; !ok {
+				return false
+			}
+		}
+		return true
+	}
+	dicts := make(map[string]*map[int64]boardDictEntry)
+	for i, p := range ps {
+		if known && i > 0 && p.Day != ps[i-1].Day && allSeen() {
+			break
+		}
+		if dicts[p.base] == nil {
+			dicts[p.base] = new(map[int64]boardDictEntry)
+		}
+		snaps, err := queryBoardSnaps(db, p.base, dicts[p.base], p.Table,
+			`(board_type, fid, ts_utc) IN (SELECT board_type, fid, MAX(ts_utc) FROM `+p.Table+` WHERE ts_utc <= ? GROUP BY board_type, fid)`,
+			tsUTC)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range snaps {
+			if cur, ok := latest[s.key()]; !ok || s.TSUTC > cur.TSUTC {
+				latest[s.key()] = s
+			}
+		}
+	}
+	out := make(map[string][]eastmoney.TopItem, len(latest))
+	for key, s := range latest {
+		out[key] = s.Items
+	}
+	return out, nil
+}
+
+// boardKeysUpTo returns the board keys ("board_type:fid") written by complete snapshots at or
+// before tsUTC. known is false while snapshots backfilled by migration 4 (no dataset list) remain.
+func boardKeysUpTo(db queryer, tsUTC string) (keys map[string]bool, known bool, err error) {
 	rows, err := db.Query(`
-		SELECT board_type, fid, code, name, price, pct, value
-		FROM board_rt
-		WHERE ts_utc = ?
-		ORDER BY board_type, fid, value DESC
+		SELECT DISTINCT datasets FROM rt_snapshots
+		WHERE status = 'complete' AND ts_utc <= ?
 	`, tsUTC)
+	if err != nil {
+		return nil, false, err
+	}
+	defer rows.Close()
+
+	keys, known = make(map[string]bool), true
+	for rows.Next() {
+		var datasets string
+		if err := rows.Scan(&datasets); err != nil {
+			return nil, false, err
+		}
+		if datasets == "" {
+			known = false
+			continue
+		}
+		for _, d := range strings.Split(datasets, ",") {
+			if key, ok := strings.CutPrefix(d, "board:"); ok {
+				keys[key] = true
+			}
+		}
+	}
+	return keys, known, rows.Err()
+}
+
+// boardBases are the partitioned tables holding board snapshots: rows and compact blobs.
+var boardBases = []string{"board_rt", "board_rt_blob"}
+
+// queryBoardSnaps reads the snapshots of one partition of base (board_rt or board_rt_blob) matching where.
+func queryBoardSnaps(db queryer, base string, dict *map[int64]boardDictEntry, table, where string, args ...any) ([]boardSnap, error) {
+	if base == "board_rt_blob" {
+		if dict == nil {
+			dict = new(map[int64]boardDictEntry)
+		}
+		return queryBoardBlobs(db, dict, table, where, args...)
+	}
+	return queryBoardsRT(db, table, where, args...)
+}
+
+// queryBoardsRT reads the rows of one board_rt partition matching where, grouped into snapshots.
+func queryBoardsRT(db queryer, table, where string, args ...any) ([]boardSnap, error) {
+	rows, err := db.Query(`
+		SELECT ts_utc, board_type, fid, code, name, price, pct, value
+		FROM `+table+`
+		WHERE `+where+`
+		ORDER BY ts_utc, board_type, fid, value DESC
+	`, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	out := make(map[string][]eastmoney.TopItem)
+	var out []boardSnap
 	for rows.Next() {
-		var bt, fid string
+		var ts, bt, fid string
 		var it eastmoney.TopItem
-		if err := rows.Scan(&bt, &fid, &it.Code, &it.Name, &it.Price, &it.Pct, &it.Value); err != nil {
+		if err := rows.Scan(&ts, &bt, &fid, &it.Code, &it.Name, &it.Price, &it.Pct, &it.Value); err != nil {
 			return nil, err
 		}
-		key := bt + ":" + fid
-		out[key] = append(out[key], it)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
+		if n := len(out); n == 0 || out[n-1].TSUTC != ts || out[n-1].BoardType != bt || out[n-1].FID != fid {
+			out = append(out, boardSnap{TSUTC: ts, BoardType: bt, FID: fid})
+		}
+		out[len(out)-1].Items = append(out[len(out)-1].Items, it)
 	}
-	return out, nil
+	return out, rows.Err()
 }
 
 func QueryMarketAggRTAt(db *sql.DB, tsUTC string) (map[string]float64, error) {
+	return queryMarketAggRT(db, `ts_utc = ?`, tsUTC)
+}
+
+func queryMarketAggRT(db queryer, where string, args ...any) (map[string]float64, error) {
 	rows, err := db.Query(`
 		SELECT source, fid, value
 		FROM market_agg_rt
-		WHERE ts_utc = ?
-	`, tsUTC)
+		WHERE `+where, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -231,43 +537,48 @@ func QueryBoardRTLatest(db *sql.DB, boardType, fid string, limit int) (string, [
 	if limit <= 0 {
 		limit = 50
 	}
-	var ts sql.NullString
-	if err := db.QueryRow(`
-		SELECT MAX(ts_utc)
-		FROM board_rt
-		WHERE board_type = ? AND fid = ?
-	`, boardType, fid).Scan(&ts); err != nil {
-		return "", nil, err
+	var ts, base, table string
+	for _, b := range boardBases {
+		t, tbl, err := latestBoardTS(db, b, `board_type = ? AND fid = ?`, boardType, fid)
+		if err != nil {
+			return "", nil, err
+		}
+		if t > ts {
+			ts, base, table = t, b, tbl
+		}
 	}
-	if !ts.Valid || ts.String == "" {
+	if ts == "" {
 		return "", nil, nil
 	}
 
-	rows, err := db.Query(`
-		SELECT code, name, price, pct, value
-		FROM board_rt
-		WHERE board_type = ? AND fid = ? AND ts_utc = ?
-		ORDER BY value DESC
-		LIMIT ?
-	`, boardType, fid, ts.String, limit)
-	if err != nil {
+	snaps, err := queryBoardSnaps(db, base, nil, table, `board_type = ? AND fid = ? AND ts_utc = ?`, boardType, fid, ts)
+	if err != nil || len(snaps) == 0 {
 		return "", nil, err
 	}
-	defer rows.Close()
+	out := snaps[0].Items
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	for i := range out {
+		out[i].Rank = i + 1
+	}
+	return ts, out, nil
+}
 
-	out := make([]eastmoney.TopItem, 0, limit)
-	rank := 1
-	for rows.Next() {
-		var it eastmoney.TopItem
-		if err := rows.Scan(&it.Code, &it.Name, &it.Price, &it.Pct, &it.Value); err != nil {
-			return "", nil, err
-		}
-		it.Rank = rank
-		rank++
-		out = append(out, it)
+// latestBoardTS returns the newest ts_utc in the partitions of base matching where, and its partition.
+func latestBoardTS(db queryer, base, where string, args ...any) (ts, table string, err error) {
+	ps, err := partitionsBetween(db, base, "", maxTSUTC, true)
+	if err != nil {
+		return "", "", err
 	}
-	if err := rows.Err(); err != nil {
-		return "", nil, err
+	for _, p := range ps {
+		var t sql.NullString
+		if err := db.QueryRow(`SELECT MAX(ts_utc) FROM `+p.Table+` WHERE `+where, args...).Scan(&t); err != nil {
+			return "", "", err
+		}
+		if t.Valid && t.String != "" {
+			return t.String, p.Table, nil
+		}
 	}
-	return ts.String, out, nil
+	return "", "", nil
 }
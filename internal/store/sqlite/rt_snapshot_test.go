@@ -0,0 +1,121 @@
+package sqlite
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/eastmoney"
+)
+
+func TestWriteRTSnapshotRollback(t *testing.T) {
+	db := openTestDB(t)
+	t1 := time.Date(2024, 1, 2, 2, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Minute)
+
+	good := RTWrite{
+		Fundflow: []eastmoney.FundflowRT{{Code: "600519", Name: "贵州茅台", NetMain: 1}},
+		Agg:      map[string]float64{"industry_sum:f62": 10},
+		Datasets: []string{"fundflow", "agg:industry_sum:f62"},
+	}
+	id1, err := WriteRTSnapshot(db, t1, good)
+	if err != nil || id1 == 0 {
+		t.Fatalf("first write id=%d err=%v", id1, err)
+	}
+
+	// The agg insert runs after fundflow, so fundflow has already been written when it fails.
+	if _, err := db.Exec(`
+		CREATE TRIGGER fail_agg BEFORE INSERT ON market_agg_rt WHEN NEW.fid = 'boom'
+		BEGIN SELECT RAISE(ABORT, 'forced failure'); END
+	`); err != nil {
+		t.Fatal(err)
+	}
+	bad := RTWrite{
+		Northbound: &eastmoney.NorthboundRT{TradeDate: "2024-01-02", SH: eastmoney.NorthboundLeg{NetBuyAmt: 5}},
+		Fundflow:   []eastmoney.FundflowRT{{Code: "600519", Name: "贵州茅台", NetMain: 2}},
+		Agg:        map[string]float64{"industry_sum:boom": 20},
+		Datasets:   []string{"northbound", "fundflow", "agg:industry_sum:boom"},
+	}
+	id2, err := WriteRTSnapshot(db, t2, bad)
+	if err == nil || id2 != 0 || !strings.Contains(err.Error(), "forced failure") {
+		t.Fatalf("failed write id=%d err=%v", id2, err)
+	}
+
+	ts2 := fixedRFC3339Nano(t2)
+	for _, table := range []string{"northbound_rt", "fundflow_rt", "market_agg_rt"} {
+		var n int
+		if err := db.QueryRow(`SELECT COUNT(*) FROM `+table+` WHERE ts_utc = ?`, ts2).Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		if n != 0 {
+			t.Errorf("%s kept %d rows of the failed write", table, n)
+		}
+	}
+
+	var status, datasets, msg string
+	var n int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM rt_snapshots WHERE ts_utc = ?`, ts2).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.QueryRow(`SELECT status, datasets, COALESCE(error, '') FROM rt_snapshots WHERE ts_utc = ?`, ts2).
+		Scan(&status, &datasets, &msg); err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 || status != "failed" || datasets != "northbound,fundflow,agg:industry_sum:boom" || !strings.Contains(msg, "forced failure") {
+		t.Fatalf("rows=%d status=%q datasets=%q error=%q", n, status, datasets, msg)
+	}
+
+	snap, ok, err := LoadLatestRTSnapshot(db)
+	if err != nil || !ok {
+		t.Fatalf("load ok=%v err=%v", ok, err)
+	}
+	if snap.ID != id1 || snap.TSUTC != fixedRFC3339Nano(t1) {
+		t.Fatalf("latest id=%d ts=%s, want %d", snap.ID, snap.TSUTC, id1)
+	}
+	if snap.Northbound != nil || len(snap.Fundflow) != 1 || snap.Fundflow[0].NetMain != 1 {
+		t.Fatalf("northbound=%v fundflow=%+v", snap.Northbound, snap.Fundflow)
+	}
+	if _, ok := snap.AggByKey["industry_sum:boom"]; ok || snap.AggByKey["industry_sum:f62"] != 10 {
+		t.Fatalf("agg=%v", snap.AggByKey)
+	}
+}
+
+func TestLoadLatestBoardsStopsOnceKeysSeen(t *testing.T) {
+	db := openTestDB(t)
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 2, 0, 0, 0, time.UTC) }
+	write := func(ts time.Time, key string, compact bool, value float64) {
+		t.Helper()
+		_, err := WriteRTSnapshot(db, ts, RTWrite{
+			Boards:        map[string][]eastmoney.TopItem{key: {{Code: "BK0001", Name: "b", Value: value}}},
+			CompactBoards: compact,
+			Datasets:      []string{"board:" + key},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(day(2), "industry:f62", false, 1)
+	write(day(3), "concept:f62", false, 2)
+	write(day(4), "industry:f62", true, 3)
+
+	// Both keys are found by day 3, so day 2 must not be read.
+	if _, err := db.Exec(`DROP TABLE board_rt_20240102`); err != nil {
+		t.Fatal(err)
+	}
+	snap, ok, err := LoadLatestRTSnapshot(db)
+	if err != nil || !ok {
+		t.Fatalf("load ok=%v err=%v", ok, err)
+	}
+	ind, con := snap.BoardsByKey["industry:f62"], snap.BoardsByKey["concept:f62"]
+	if len(snap.BoardsByKey) != 2 || len(ind) != 1 || ind[0].Value != 3 || len(con) != 1 || con[0].Value != 2 {
+		t.Fatalf("boards=%+v", snap.BoardsByKey)
+	}
+
+	// Backfilled snapshots carry no dataset list, so every partition is read again.
+	if _, err := db.Exec(`UPDATE rt_snapshots SET datasets = '' WHERE ts_utc = ?`, fixedRFC3339Nano(day(2))); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := LoadLatestRTSnapshot(db); err == nil {
+		t.Fatal("dropped partition was not read with an unknown key set")
+	}
+}
@@ -0,0 +1,137 @@
+package sqlite
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Security is one row of the security master (securities), used by /api/search.
+type Security struct {
+	SecID  string `json:"secid"`
+	Code   string `json:"code"`
+	Name   string `json:"name"`
+	Market string `json:"market"` // SH | SZ | BJ | BK
+	Kind   string `json:"kind"`   // stock | index | etf | industry | concept
+	Pinyin string `json:"pinyin"`
+}
+
+// Symbol is the watchlist form of a stock, index or ETF (600519.SH); boards have none.
+func (s Security) Symbol() string {
+	if s.Market == "BK" || s.Market == "" {
+		return ""
+	}
+	return s.Code + "." + s.Market
+}
+
+// ReplaceSecurities upserts rows and deletes the other rows of the given kinds, so securities that
+// left a refreshed list (delistings, merged boards) disappear. Kinds not listed are left alone.
+func ReplaceSecurities(db *sql.DB, kinds []string, rows []Security, updatedUTC time.Time) error {
+	ts := fixedRFC3339Nano(updatedUTC)
+	return withTx(db, func(tx *sql.Tx) error {
+		stmt, err := tx.Prepare(`
+			INSERT INTO securities(secid, code, name, market, kind, pinyin, updated_utc)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT(secid) DO UPDATE SET
+				code=excluded.code,
+				name=excluded.name,
+				market=excluded.market,
+				kind=excluded.kind,
+				pinyin=excluded.pinyin,
+				updated_utc=excluded.updated_utc
+		`)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+		for _, r := range rows {
+			if _, err := stmt.Exec(r.SecID, r.Code, r.Name, r.Market, r.Kind, r.Pinyin, ts); err != nil {
+				return err
+			}
+		}
+		for _, k := range kinds {
+			if _, err := tx.Exec(`DELETE FROM securities WHERE kind = ? AND updated_utc < ?`, k, ts); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// QuerySecuritiesUpdated returns the number of securities and the newest updated_utc ("" when empty).
+func QuerySecuritiesUpdated(db *sql.DB) (int, string, error) {
+	var n int
+	var ts sql.NullString
+	if err := db.QueryRow(`SELECT COUNT(*), MAX(updated_utc) FROM securities`).Scan(&n, &ts); err != nil {
+		return 0, "", err
+	}
+	return n, ts.String, nil
+}
+
+// SearchSecurities matches q against code prefixes, pinyin initials and names. Exact codes come
+// first, then code prefixes, pinyin prefixes, name prefixes and finally substrings; within a rank
+// stocks come before ETFs, indices and boards. kinds restricts the result when not empty.
+func SearchSecurities(db *sql.DB, q string, kinds []string, limit int) ([]Security, error) {
+	q = strings.TrimSpace(q)
+	if q == "" {
+		return nil, nil
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+	upper := strings.ToUpper(q)
+	args := []any{
+		upper,                         // ?1 exact code
+		likeEscape(upper) + "%",       // ?2 code / pinyin prefix
+		likeEscape(q) + "%",           // ?3 name prefix
+		"%" + likeEscape(upper) + "%", // ?4 pinyin substring
+		"%" + likeEscape(q) + "%",     // ?5 name substring
+	}
+	query := `
+		SELECT secid, code, name, market, kind, pinyin
+		FROM securities
+		WHERE (code LIKE ?2 ESCAPE '\' OR pinyin LIKE ?4 ESCAPE '\' OR name LIKE ?5 ESCAPE '\')`
+	if len(kinds) > 0 {
+		ph := make([]string, len(kinds))
+		for i, k := range kinds {
+			args = append(args, k)
+			ph[i] = "?" + strconv.Itoa(len(args))
+		}
+		query += ` AND kind IN (` + strings.Join(ph, ", ") + `)`
+	}
+	args = append(args, limit)
+	query += `
+		ORDER BY
+			CASE
+				WHEN code = ?1 THEN 0
+				WHEN code LIKE ?2 ESCAPE '\' THEN 1
+				WHEN pinyin LIKE ?2 ESCAPE '\' THEN 2
+				WHEN name LIKE ?3 ESCAPE '\' THEN 3
+				ELSE 4
+			END,
+			CASE kind WHEN 'stock' THEN 0 WHEN 'etf' THEN 1 WHEN 'index' THEN 2 ELSE 3 END,
+			code
+		LIMIT ?` + strconv.Itoa(len(args))
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Security
+	for rows.Next() {
+		var s Security
+		if err := rows.Scan(&s.SecID, &s.Code, &s.Name, &s.Market, &s.Kind, &s.Pinyin); err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+// likeEscape escapes LIKE wildcards with a backslash (the queries use ESCAPE '\').
+func likeEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
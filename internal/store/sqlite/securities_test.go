@@ -0,0 +1,76 @@
+package sqlite
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSearchSecurities(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "aof.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+
+	t0 := time.Date(2024, 1, 2, 1, 0, 0, 0, time.UTC)
+	rows := []Security{
+		{SecID: "1.600519", Code: "600519", Name: "贵州茅台", Market: "SH", Kind: "stock", Pinyin: "GZMT"},
+		{SecID: "0.000001", Code: "000001", Name: "平安银行", Market: "SZ", Kind: "stock", Pinyin: "PAYH"},
+		{SecID: "1.000001", Code: "000001", Name: "上证指数", Market: "SH", Kind: "index", Pinyin: "SZZS"},
+		{SecID: "0.000002", Code: "000002", Name: "万科Ａ", Market: "SZ", Kind: "stock", Pinyin: "WKA"},
+		{SecID: "1.510050", Code: "510050", Name: "上证50ETF", Market: "SH", Kind: "etf", Pinyin: "SZ50ETF"},
+		{SecID: "90.BK0477", Code: "BK0477", Name: "酿酒行业", Market: "BK", Kind: "industry", Pinyin: "NJHY"},
+	}
+	if err := ReplaceSecurities(db, []string{"stock", "index", "etf", "industry"}, rows, t0); err != nil {
+		t.Fatal(err)
+	}
+	// A later stock refresh without 万科Ａ drops it; other kinds are untouched.
+	if err := ReplaceSecurities(db, []string{"stock"}, rows[:2], t0.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	secids := func(q string, kinds ...string) []string {
+		t.Helper()
+		got, err := SearchSecurities(db, q, kinds, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out []string
+		for _, s := range got {
+			out = append(out, s.SecID)
+		}
+		return out
+	}
+	for _, c := range []struct {
+		q     string
+		kinds []string
+		want  []string
+	}{
+		{"000001", nil, []string{"0.000001", "1.000001"}}, // exact code: stock before index
+		{"00000", nil, []string{"0.000001", "1.000001"}},
+		{"gzmt", nil, []string{"1.600519"}},
+		{"sz", nil, []string{"1.510050", "1.000001"}}, // pinyin prefix: etf before index
+		{"茅台", nil, []string{"1.600519"}},
+		{"bk04", nil, []string{"90.BK0477"}},
+		{"万科", nil, nil},
+		{"000001", []string{"index"}, []string{"1.000001"}},
+		{"%", nil, nil},
+	} {
+		got := secids(c.q, c.kinds...)
+		if len(got) != len(c.want) {
+			t.Fatalf("search %q %v = %v, want %v", c.q, c.kinds, got, c.want)
+		}
+		for i := range got {
+			if got[i] != c.want[i] {
+				t.Fatalf("search %q %v = %v, want %v", c.q, c.kinds, got, c.want)
+			}
+		}
+	}
+	if n, ts, err := QuerySecuritiesUpdated(db); err != nil || n != 5 || ts != FixedRFC3339Nano(t0.Add(time.Hour)) {
+		t.Fatalf("updated = %d %q %v", n, ts, err)
+	}
+}
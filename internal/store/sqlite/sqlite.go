@@ -2,19 +2,28 @@ package sqlite
 
 import (
 	"database/sql"
-	"fmt"
 	"os"
 	"path/filepath"
 
 	_ "modernc.org/sqlite"
 )
 
+// busyTimeout is how long a connection waits for a lock held by another process
+// (e.g. `aof daily` next to `aof web`) before failing with SQLITE_BUSY.
+const busyTimeout = "_pragma=busy_timeout(5000)"
+
+// readerConns is the size of the OpenReader pool.
+const readerConns = 4
+
+// Open opens the single writer connection to path. Every write goes through it, so writes
+// within the process queue in database/sql instead of failing on the lock; transactions take
+// the write lock on BEGIN (_txlock=immediate) so the busy timeout applies to them too.
 func Open(path string) (*sql.DB, error) {
 	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
 		return nil, err
 	}
-	// modernc.org/sqlite uses a file path DSN.
-	db, err := sql.Open("sqlite", path)
+	// modernc.org/sqlite uses a file path DSN; query parameters set per-connection pragmas.
+	db, err := sql.Open("sqlite", path+"?"+busyTimeout+"&_txlock=immediate")
 	if err != nil {
 		return nil, err
 	}
@@ -23,135 +32,218 @@ func Open(path string) (*sql.DB, error) {
 	return db, nil
 }
 
-func Migrate(db *sql.DB) error {
-	stmts := []string{
-		`PRAGMA journal_mode=WAL;`,
-		`PRAGMA synchronous=NORMAL;`,
-
-		`CREATE TABLE IF NOT EXISTS northbound_rt (
-			ts_utc TEXT PRIMARY KEY,
-			trade_date TEXT,
-			sh_day_net_amt_in REAL,
-			sh_net_buy_amt REAL,
-			sh_buy_amt REAL,
-			sh_sell_amt REAL,
-			sz_day_net_amt_in REAL,
-			sz_net_buy_amt REAL,
-			sz_buy_amt REAL,
-			sz_sell_amt REAL
-		);`,
-
-		`CREATE TABLE IF NOT EXISTS northbound_daily (
-			trade_date TEXT PRIMARY KEY,
-			sh_day_net_amt_in REAL,
-			sh_net_buy_amt REAL,
-			sh_buy_amt REAL,
-			sh_sell_amt REAL,
-			sz_day_net_amt_in REAL,
-			sz_net_buy_amt REAL,
-			sz_buy_amt REAL,
-			sz_sell_amt REAL
-		);`,
-
-		`CREATE TABLE IF NOT EXISTS fundflow_rt (
-			ts_utc TEXT NOT NULL,
-			code TEXT NOT NULL,
-			name TEXT,
-			net_main REAL,
-			net_xl REAL,
-			net_l REAL,
-			net_m REAL,
-			net_s REAL,
-			PRIMARY KEY (ts_utc, code)
-		);`,
-
-		`CREATE TABLE IF NOT EXISTS fundflow_daily (
-			trade_date TEXT NOT NULL,
-			secid TEXT NOT NULL,
-			code TEXT,
-			name TEXT,
-			net_main REAL,
-			net_xl REAL,
-			net_l REAL,
-			net_m REAL,
-			net_s REAL,
-			PRIMARY KEY (trade_date, secid)
-		);`,
-
-		`CREATE TABLE IF NOT EXISTS toplist_rt (
-			ts_utc TEXT NOT NULL,
-			fid TEXT NOT NULL,
-			rank INTEGER NOT NULL,
-			code TEXT NOT NULL,
-			name TEXT,
-			price REAL,
-			pct REAL,
-			value REAL,
-			PRIMARY KEY (ts_utc, fid, rank)
-		);`,
-
-		`CREATE TABLE IF NOT EXISTS board_rt (
-			ts_utc TEXT NOT NULL,
-			board_type TEXT NOT NULL, -- "industry" | "concept"
-			fid TEXT NOT NULL,
-			code TEXT NOT NULL,
-			name TEXT,
-			price REAL,
-			pct REAL,
-			value REAL,
-			PRIMARY KEY (ts_utc, board_type, fid, code)
-		);`,
-
-		`CREATE TABLE IF NOT EXISTS board_daily (
-			trade_date TEXT NOT NULL,
-			board_type TEXT NOT NULL,
-			fid TEXT NOT NULL,
-			code TEXT NOT NULL,
-			name TEXT,
-			price REAL,
-			pct REAL,
-			value REAL,
-			PRIMARY KEY (trade_date, board_type, fid, code)
-		);`,
-
-		`CREATE TABLE IF NOT EXISTS market_agg_rt (
-			ts_utc TEXT NOT NULL,
-			source TEXT NOT NULL, -- e.g. "industry_sum"
-			fid TEXT NOT NULL,
-			value REAL,
-			PRIMARY KEY (ts_utc, source, fid)
-		);`,
-
-		`CREATE TABLE IF NOT EXISTS market_agg_daily (
-			trade_date TEXT NOT NULL,
-			source TEXT NOT NULL,
-			fid TEXT NOT NULL,
-			value REAL,
-			PRIMARY KEY (trade_date, source, fid)
-		);`,
-
-		`CREATE TABLE IF NOT EXISTS margin_daily (
-			trade_date TEXT NOT NULL,
-			code TEXT NOT NULL,
-			name TEXT,
-			market TEXT,
-			rzye REAL,
-			rzmre REAL,
-			rzche REAL,
-			rzjme REAL,
-			rqye REAL,
-			rqmcl REAL,
-			rqchl REAL,
-			rqjmg REAL,
-			rzrqye REAL,
-			PRIMARY KEY (trade_date, code)
-		);`,
+// OpenReader opens a pool of query-only connections to path for reads. In WAL mode readers
+// see the last committed state without waiting for the writer, so queries don't queue behind
+// persist transactions or daily upserts.
+func OpenReader(path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", path+"?"+busyTimeout+"&_pragma=query_only(1)")
+	if err != nil {
+		return nil, err
 	}
+	db.SetMaxOpenConns(readerConns)
+	db.SetMaxIdleConns(readerConns)
+	return db, nil
+}
 
-	for _, s := range stmts {
-		if _, err := db.Exec(s); err != nil {
-			return fmt.Errorf("migrate: %w", err)
-		}
-	}
-	return nil
+// baselineSchema is migration 1: the schema as it was before versioned migrations.
+// Every statement is idempotent so it also applies cleanly to databases created back then.
+// Never edit it; add a new migration in migrate.go instead.
+var baselineSchema = []string{
+	`CREATE TABLE IF NOT EXISTS northbound_rt (
+		ts_utc TEXT PRIMARY KEY,
+		trade_date TEXT,
+		sh_day_net_amt_in REAL,
+		sh_net_buy_amt REAL,
+		sh_buy_amt REAL,
+		sh_sell_amt REAL,
+		sz_day_net_amt_in REAL,
+		sz_net_buy_amt REAL,
+		sz_buy_amt REAL,
+		sz_sell_amt REAL
+	);`,
+
+	`CREATE TABLE IF NOT EXISTS northbound_daily (
+		trade_date TEXT PRIMARY KEY,
+		sh_day_net_amt_in REAL,
+		sh_net_buy_amt REAL,
+		sh_buy_amt REAL,
+		sh_sell_amt REAL,
+		sz_day_net_amt_in REAL,
+		sz_net_buy_amt REAL,
+		sz_buy_amt REAL,
+		sz_sell_amt REAL
+	);`,
+
+	`CREATE TABLE IF NOT EXISTS fundflow_rt (
+		ts_utc TEXT NOT NULL,
+		code TEXT NOT NULL,
+		name TEXT,
+		net_main REAL,
+		net_xl REAL,
+		net_l REAL,
+		net_m REAL,
+		net_s REAL,
+		PRIMARY KEY (ts_utc, code)
+	);`,
+
+	`CREATE TABLE IF NOT EXISTS fundflow_daily (
+		trade_date TEXT NOT NULL,
+		secid TEXT NOT NULL,
+		code TEXT,
+		name TEXT,
+		net_main REAL,
+		net_xl REAL,
+		net_l REAL,
+		net_m REAL,
+		net_s REAL,
+		PRIMARY KEY (trade_date, secid)
+	);`,
+
+	`CREATE TABLE IF NOT EXISTS toplist_rt (
+		ts_utc TEXT NOT NULL,
+		fid TEXT NOT NULL,
+		rank INTEGER NOT NULL,
+		code TEXT NOT NULL,
+		name TEXT,
+		price REAL,
+		pct REAL,
+		value REAL,
+		PRIMARY KEY (ts_utc, fid, rank)
+	);`,
+
+	`CREATE TABLE IF NOT EXISTS board_rt (
+		ts_utc TEXT NOT NULL,
+		board_type TEXT NOT NULL, -- "industry" | "concept"
+		fid TEXT NOT NULL,
+		code TEXT NOT NULL,
+		name TEXT,
+		price REAL,
+		pct REAL,
+		value REAL,
+		PRIMARY KEY (ts_utc, board_type, fid, code)
+	);`,
+	`CREATE INDEX IF NOT EXISTS idx_board_rt_key_ts ON board_rt(board_type, fid, ts_utc);`,
+
+	`CREATE TABLE IF NOT EXISTS board_daily (
+		trade_date TEXT NOT NULL,
+		board_type TEXT NOT NULL,
+		fid TEXT NOT NULL,
+		code TEXT NOT NULL,
+		name TEXT,
+		price REAL,
+		pct REAL,
+		value REAL,
+		PRIMARY KEY (trade_date, board_type, fid, code)
+	);`,
+
+	`CREATE TABLE IF NOT EXISTS market_agg_rt (
+		ts_utc TEXT NOT NULL,
+		source TEXT NOT NULL, -- e.g. "industry_sum"
+		fid TEXT NOT NULL,
+		value REAL,
+		PRIMARY KEY (ts_utc, source, fid)
+	);`,
+
+	`CREATE TABLE IF NOT EXISTS market_agg_daily (
+		trade_date TEXT NOT NULL,
+		source TEXT NOT NULL,
+		fid TEXT NOT NULL,
+		value REAL,
+		PRIMARY KEY (trade_date, source, fid)
+	);`,
+
+	`CREATE TABLE IF NOT EXISTS margin_daily (
+		trade_date TEXT NOT NULL,
+		code TEXT NOT NULL,
+		name TEXT,
+		market TEXT,
+		rzye REAL,
+		rzmre REAL,
+		rzche REAL,
+		rzjme REAL,
+		rqye REAL,
+		rqmcl REAL,
+		rqchl REAL,
+		rqjmg REAL,
+		rzrqye REAL,
+		PRIMARY KEY (trade_date, code)
+	);`,
+
+	`CREATE TABLE IF NOT EXISTS auction_rt (
+		ts_utc TEXT NOT NULL,
+		trade_date TEXT NOT NULL,
+		phase TEXT NOT NULL, -- "open" | "close"
+		source TEXT NOT NULL, -- "watchlist" | "toplist"
+		code TEXT NOT NULL,
+		name TEXT,
+		rank INTEGER,
+		price REAL, -- indicative price
+		pct REAL,
+		volume REAL, -- matched volume (lots)
+		amount REAL, -- matched amount
+		bid_vol REAL,
+		ask_vol REAL,
+		unmatched REAL, -- bid_vol - ask_vol (lots)
+		unmatched_amt REAL,
+		PRIMARY KEY (ts_utc, source, code)
+	);`,
+	`CREATE INDEX IF NOT EXISTS idx_auction_rt_date_phase ON auction_rt(trade_date, phase, ts_utc);`,
+
+	`CREATE TABLE IF NOT EXISTS daily_runs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		trade_date TEXT NOT NULL,
+		trigger TEXT, -- "cli" | "schedule" | "manual" | "retry-failed"
+		status TEXT NOT NULL, -- "running" | "ok" | "partial" | "failed"
+		started_at TEXT,
+		finished_at TEXT,
+		total INTEGER,
+		ok INTEGER,
+		failed INTEGER
+	);`,
+	`CREATE INDEX IF NOT EXISTS idx_daily_runs_trade_date ON daily_runs(trade_date, id);`,
+
+	`CREATE TABLE IF NOT EXISTS daily_run_items (
+		run_id INTEGER NOT NULL,
+		dataset TEXT NOT NULL,
+		key TEXT NOT NULL, -- watchlist symbol for per-symbol datasets, else ""
+		status TEXT NOT NULL, -- "ok" | "failed"
+		error TEXT,
+		attempts INTEGER NOT NULL DEFAULT 1,
+		updated_at TEXT,
+		PRIMARY KEY (run_id, dataset, key)
+	);`,
+
+	`CREATE TABLE IF NOT EXISTS fundflow_delta (
+		ts_utc TEXT NOT NULL,
+		code TEXT NOT NULL,
+		trade_date TEXT NOT NULL,
+		name TEXT,
+		source TEXT NOT NULL, -- "watchlist" | "toplist"
+		prev_ts_utc TEXT, -- NULL on the first sample of a trade date (delta = raw value)
+		dt_sec REAL,
+		d_main REAL,
+		d_xl REAL,
+		d_l REAL,
+		d_m REAL,
+		d_s REAL,
+		net_main REAL,
+		PRIMARY KEY (ts_utc, code)
+	);`,
+	`CREATE INDEX IF NOT EXISTS idx_fundflow_delta_date_ts ON fundflow_delta(trade_date, ts_utc);`,
+
+	`CREATE TABLE IF NOT EXISTS watch_groups (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL UNIQUE,
+		tags TEXT, -- comma separated
+		note TEXT,
+		created_at TEXT NOT NULL,
+		updated_at TEXT NOT NULL
+	);`,
+
+	`CREATE TABLE IF NOT EXISTS watch_group_members (
+		group_id INTEGER NOT NULL,
+		symbol TEXT NOT NULL, -- "600519.SH"
+		pos INTEGER NOT NULL,
+		PRIMARY KEY (group_id, symbol)
+	);`,
 }
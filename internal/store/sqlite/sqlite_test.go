@@ -0,0 +1,63 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOpenReader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "aof.db")
+	db, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO northbound_rt(ts_utc, trade_date) VALUES ('t1', '2024-01-02')`); err != nil {
+		t.Fatal(err)
+	}
+
+	rdb, err := OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rdb.Close()
+	if _, err := rdb.Exec(`INSERT INTO northbound_rt(ts_utc, trade_date) VALUES ('t2', '2024-01-02')`); err == nil || !strings.Contains(err.Error(), "readonly") {
+		t.Fatalf("reader pool write: %v, want a readonly error", err)
+	}
+
+	count := func() int {
+		t.Helper()
+		// Well under busy_timeout: a reader waiting on the writer's lock fails here instead of after 5s.
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		var n int
+		if err := rdb.QueryRowContext(ctx, `SELECT COUNT(*) FROM northbound_rt`).Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+
+	// _txlock=immediate takes the write lock on BEGIN; readers keep seeing the last commit.
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec(`INSERT INTO northbound_rt(ts_utc, trade_date) VALUES ('t2', '2024-01-02')`); err != nil {
+		t.Fatal(err)
+	}
+	if n := count(); n != 1 {
+		t.Fatalf("read during write transaction: %d rows, want 1", n)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if n := count(); n != 2 {
+		t.Fatalf("read after commit: %d rows, want 2", n)
+	}
+}
@@ -0,0 +1,185 @@
+package sqlite
+
+import (
+	"database/sql"
+	"sort"
+	"time"
+)
+
+// Per-stock history for /api/stock/detail.
+
+type FundflowDailyPoint struct {
+	TradeDate string  `json:"trade_date"`
+	NetMain   float64 `json:"net_main"`
+	NetXL     float64 `json:"net_xl"`
+	NetL      float64 `json:"net_l"`
+	NetM      float64 `json:"net_m"`
+	NetS      float64 `json:"net_s"`
+}
+
+type MarginDailyPoint struct {
+	TradeDate string  `json:"trade_date"`
+	RZYE      float64 `json:"rzye"`   // 融资余额
+	RZMRE     float64 `json:"rzmre"`  // 融资买入额
+	RZCHE     float64 `json:"rzche"`  // 融资偿还额
+	RZJME     float64 `json:"rzjme"`  // 融资净买入
+	RQYE      float64 `json:"rqye"`   // 融券余额
+	RQMCL     float64 `json:"rqmcl"`  // 融券卖出量
+	RQCHL     float64 `json:"rqchl"`  // 融券偿还量
+	RQJMG     float64 `json:"rqjmg"`  // 融券净卖出
+	RZRQYE    float64 `json:"rzrqye"` // 融资融券余额
+}
+
+// ToplistAppearance summarizes the toplist_rt samples of one stock for one trade date and fid.
+type ToplistAppearance struct {
+	TradeDate  string  `json:"trade_date"`
+	FID        string  `json:"fid"`
+	Samples    int     `json:"samples"`
+	BestRank   int     `json:"best_rank"`
+	LastRank   int     `json:"last_rank"`
+	LastValue  float64 `json:"last_value"`
+	FirstTSUTC string  `json:"first_ts_utc"`
+	LastTSUTC  string  `json:"last_ts_utc"`
+}
+
+// QueryFundflowDailyByCode returns the last limit days of fundflow_daily for a stock code, oldest
+// first, and the latest stored name.
+func QueryFundflowDailyByCode(db *sql.DB, code string, limit int) ([]FundflowDailyPoint, string, error) {
+	if limit <= 0 {
+		limit = 60
+	}
+	rows, err := db.Query(`
+		SELECT trade_date, name, net_main, net_xl, net_l, net_m, net_s
+		FROM fundflow_daily
+		WHERE code = ?
+		ORDER BY trade_date DESC
+		LIMIT ?
+	`, code, limit)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	var name string
+	out := make([]FundflowDailyPoint, 0, limit)
+	for rows.Next() {
+		var p FundflowDailyPoint
+		var n sql.NullString
+		var vals [5]sql.NullFloat64
+		if err := rows.Scan(&p.TradeDate, &n, &vals[0], &vals[1], &vals[2], &vals[3], &vals[4]); err != nil {
+			return nil, "", err
+		}
+		if name == "" {
+			name = n.String
+		}
+		p.NetMain, p.NetXL, p.NetL, p.NetM, p.NetS = vals[0].Float64, vals[1].Float64, vals[2].Float64, vals[3].Float64, vals[4].Float64
+		out = append(out, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+	reverse(out)
+	return out, name, nil
+}
+
+// QueryMarginDailyByCode returns the last limit days of margin_daily for a stock code, oldest first.
+func QueryMarginDailyByCode(db *sql.DB, code string, limit int) ([]MarginDailyPoint, error) {
+	if limit <= 0 {
+		limit = 60
+	}
+	rows, err := db.Query(`
+		SELECT trade_date, rzye, rzmre, rzche, rzjme, rqye, rqmcl, rqchl, rqjmg, rzrqye
+		FROM margin_daily
+		WHERE code = ?
+		ORDER BY trade_date DESC
+		LIMIT ?
+	`, code, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]MarginDailyPoint, 0, limit)
+	for rows.Next() {
+		var p MarginDailyPoint
+		var v [9]sql.NullFloat64
+		if err := rows.Scan(&p.TradeDate, &v[0], &v[1], &v[2], &v[3], &v[4], &v[5], &v[6], &v[7], &v[8]); err != nil {
+			return nil, err
+		}
+		p.RZYE, p.RZMRE, p.RZCHE, p.RZJME = v[0].Float64, v[1].Float64, v[2].Float64, v[3].Float64
+		p.RQYE, p.RQMCL, p.RQCHL, p.RQJMG, p.RZRQYE = v[4].Float64, v[5].Float64, v[6].Float64, v[7].Float64, v[8].Float64
+		out = append(out, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	reverse(out)
+	return out, nil
+}
+
+// QueryToplistAppearances groups the toplist_rt rows of a stock code since sinceUTC by Asia/Shanghai
+// trade date and fid, newest date first.
+func QueryToplistAppearances(db *sql.DB, code, sinceUTC string) ([]ToplistAppearance, error) {
+	rows, err := db.Query(`
+		SELECT ts_utc, fid, rank, value
+		FROM toplist_rt
+		WHERE code = ? AND ts_utc >= ?
+		ORDER BY ts_utc
+	`, code, sinceUTC)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var samples []toplistSample
+	for rows.Next() {
+		var s toplistSample
+		var v sql.NullFloat64
+		if err := rows.Scan(&s.tsUTC, &s.fid, &s.rank, &v); err != nil {
+			return nil, err
+		}
+		s.value = v.Float64
+		samples = append(samples, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return groupToplistSamples(samples), nil
+}
+
+type toplistSample struct {
+	tsUTC string
+	fid   string
+	rank  int
+	value float64
+}
+
+// groupToplistSamples folds samples (ordered by ts_utc) into one appearance per trade date and fid.
+func groupToplistSamples(samples []toplistSample) []ToplistAppearance {
+	var out []ToplistAppearance
+	idx := make(map[[2]string]int)
+	for _, s := range samples {
+		day := s.tsUTC
+		if t, err := time.Parse(time.RFC3339Nano, s.tsUTC); err == nil {
+			day = t.In(partitionZone).Format("2006-01-02")
+		} else if len(day) >= 10 {
+			day = day[:10]
+		}
+		k := [2]string{day, s.fid}
+		i, ok := idx[k]
+		if !ok {
+			i = len(out)
+			idx[k] = i
+			out = append(out, ToplistAppearance{TradeDate: day, FID: s.fid, BestRank: s.rank, FirstTSUTC: s.tsUTC})
+		}
+		a := &out[i]
+		a.Samples++
+		if s.rank < a.BestRank {
+			a.BestRank = s.rank
+		}
+		a.LastRank, a.LastValue, a.LastTSUTC = s.rank, s.value, s.tsUTC
+	}
+	// Newest trade date first; fids keep their first-seen order within a day.
+	sort.SliceStable(out, func(i, j int) bool { return out[i].TradeDate > out[j].TradeDate })
+	return out
+}
@@ -0,0 +1,30 @@
+package sqlite
+
+import "testing"
+
+func TestGroupToplistSamples(t *testing.T) {
+	got := groupToplistSamples([]toplistSample{
+		// 2024-01-02 09:31 and 14:59 Shanghai.
+		{tsUTC: "2024-01-02T01:31:00.000000000Z", fid: "f62", rank: 5, value: 1},
+		{tsUTC: "2024-01-02T01:31:00.000000000Z", fid: "f3", rank: 2, value: 9},
+		{tsUTC: "2024-01-02T06:59:00.000000000Z", fid: "f62", rank: 3, value: 2},
+		// 16:10 UTC is 00:10 on the 3rd in Shanghai.
+		{tsUTC: "2024-01-02T16:10:00.000000000Z", fid: "f62", rank: 7, value: 3},
+	})
+	want := []ToplistAppearance{
+		{TradeDate: "2024-01-03", FID: "f62", Samples: 1, BestRank: 7, LastRank: 7, LastValue: 3,
+			FirstTSUTC: "2024-01-02T16:10:00.000000000Z", LastTSUTC: "2024-01-02T16:10:00.000000000Z"},
+		{TradeDate: "2024-01-02", FID: "f62", Samples: 2, BestRank: 3, LastRank: 3, LastValue: 2,
+			FirstTSUTC: "2024-01-02T01:31:00.000000000Z", LastTSUTC: "2024-01-02T06:59:00.000000000Z"},
+		{TradeDate: "2024-01-02", FID: "f3", Samples: 1, BestRank: 2, LastRank: 2, LastValue: 9,
+			FirstTSUTC: "2024-01-02T01:31:00.000000000Z", LastTSUTC: "2024-01-02T01:31:00.000000000Z"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d appearances, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("appearance %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
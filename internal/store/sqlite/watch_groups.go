@@ -0,0 +1,190 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned when a row addressed by id does not exist.
+var ErrNotFound = errors.New("not found")
+
+// WatchGroup is a named watchlist kept in SQLite (holdings, candidates, themes ...).
+type WatchGroup struct {
+	ID        int64    `json:"id"`
+	Name      string   `json:"name"`
+	Tags      []string `json:"tags"`
+	Note      string   `json:"note"`
+	Symbols   []string `json:"symbols"`
+	CreatedAt string   `json:"created_at"`
+	UpdatedAt string   `json:"updated_at"`
+}
+
+// CreateWatchGroup inserts g with its members and returns the new id.
+func CreateWatchGroup(db *sql.DB, g WatchGroup) (int64, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	now := fixedRFC3339Nano(time.Now().UTC())
+	var id int64
+	if err := tx.QueryRow(`
+		INSERT INTO watch_groups(name, tags, note, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		RETURNING id
+	`, g.Name, strings.Join(g.Tags, ","), g.Note, now, now).Scan(&id); err != nil {
+		return 0, err
+	}
+	if err := replaceWatchGroupMembers(tx, id, g.Symbols); err != nil {
+		return 0, err
+	}
+	return id, tx.Commit()
+}
+
+// UpdateWatchGroup replaces name, tags, note and members of group g.ID.
+func UpdateWatchGroup(db *sql.DB, g WatchGroup) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.Exec(`
+		UPDATE watch_groups SET name = ?, tags = ?, note = ?, updated_at = ?
+		WHERE id = ?
+	`, g.Name, strings.Join(g.Tags, ","), g.Note, fixedRFC3339Nano(time.Now().UTC()), g.ID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	if err := replaceWatchGroupMembers(tx, g.ID, g.Symbols); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func replaceWatchGroupMembers(tx *sql.Tx, groupID int64, symbols []string) error {
+	if _, err := tx.Exec(`DELETE FROM watch_group_members WHERE group_id = ?`, groupID); err != nil {
+		return err
+	}
+	for i, sym := range symbols {
+		if _, err := tx.Exec(`
+			INSERT INTO watch_group_members(group_id, symbol, pos) VALUES (?, ?, ?)
+			ON CONFLICT(group_id, symbol) DO NOTHING
+		`, groupID, sym, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func DeleteWatchGroup(db *sql.DB, id int64) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.Exec(`DELETE FROM watch_groups WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	if _, err := tx.Exec(`DELETE FROM watch_group_members WHERE group_id = ?`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// QueryWatchGroups returns all groups with members, ordered by name.
+func QueryWatchGroups(db *sql.DB) ([]WatchGroup, error) {
+	rows, err := db.Query(`
+		SELECT id, name, tags, note, created_at, updated_at
+		FROM watch_groups
+		ORDER BY name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []WatchGroup
+	byID := make(map[int64]int)
+	for rows.Next() {
+		var g WatchGroup
+		var tags, note sql.NullString
+		if err := rows.Scan(&g.ID, &g.Name, &tags, &note, &g.CreatedAt, &g.UpdatedAt); err != nil {
+			return nil, err
+		}
+		g.Tags = splitTags(tags.String)
+		g.Note = note.String
+		g.Symbols = []string{}
+		byID[g.ID] = len(out)
+		out = append(out, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	mrows, err := db.Query(`
+		SELECT group_id, symbol
+		FROM watch_group_members
+		ORDER BY group_id, pos
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer mrows.Close()
+	for mrows.Next() {
+		var id int64
+		var sym string
+		if err := mrows.Scan(&id, &sym); err != nil {
+			return nil, err
+		}
+		if i, ok := byID[id]; ok {
+			out[i].Symbols = append(out[i].Symbols, sym)
+		}
+	}
+	return out, mrows.Err()
+}
+
+// QueryWatchGroupSymbols returns the distinct members of all groups.
+func QueryWatchGroupSymbols(db *sql.DB) ([]string, error) {
+	rows, err := db.Query(`
+		SELECT DISTINCT symbol
+		FROM watch_group_members
+		ORDER BY symbol
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []string
+	for rows.Next() {
+		var sym string
+		if err := rows.Scan(&sym); err != nil {
+			return nil, err
+		}
+		out = append(out, sym)
+	}
+	return out, rows.Err()
+}
+
+func splitTags(s string) []string {
+	out := []string{}
+	for _, t := range strings.Split(s, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			out = append(out, t)
+		}
+	}
+	return out
+}
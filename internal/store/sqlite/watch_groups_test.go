@@ -0,0 +1,70 @@
+package sqlite
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestWatchGroupsCRUD(t *testing.T) {
+	db := openTestDB(t)
+
+	hold, err := CreateWatchGroup(db, WatchGroup{Name: "holdings", Tags: []string{"core", "long"}, Note: "n",
+		Symbols: []string{"600519.SH", "000001.SZ", "600519.SH"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	theme, err := CreateWatchGroup(db, WatchGroup{Name: "ai", Symbols: []string{"000001.SH", "000001.SZ"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CreateWatchGroup(db, WatchGroup{Name: "holdings"}); err == nil {
+		t.Fatal("duplicate name accepted")
+	}
+
+	groups, err := QueryWatchGroups(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 2 || groups[0].ID != theme || groups[1].ID != hold {
+		t.Fatalf("groups not ordered by name: %+v", groups)
+	}
+	g := groups[1]
+	if !reflect.DeepEqual(g.Tags, []string{"core", "long"}) || g.Note != "n" || g.CreatedAt == "" || g.UpdatedAt != g.CreatedAt {
+		t.Fatalf("holdings=%+v", g)
+	}
+	// Members keep their order; the repeated symbol is stored once.
+	if !reflect.DeepEqual(g.Symbols, []string{"600519.SH", "000001.SZ"}) {
+		t.Fatalf("symbols=%v", g.Symbols)
+	}
+
+	syms, err := QueryWatchGroupSymbols(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"000001.SH", "000001.SZ", "600519.SH"}; !reflect.DeepEqual(syms, want) {
+		t.Fatalf("symbols=%v, want %v", syms, want)
+	}
+
+	if err := UpdateWatchGroup(db, WatchGroup{ID: hold, Name: "holdings", Symbols: []string{"300750.SZ"}}); err != nil {
+		t.Fatal(err)
+	}
+	groups, _ = QueryWatchGroups(db)
+	if g := groups[1]; len(g.Tags) != 0 || g.Note != "" || !reflect.DeepEqual(g.Symbols, []string{"300750.SZ"}) {
+		t.Fatalf("updated=%+v", g)
+	}
+
+	if err := DeleteWatchGroup(db, theme); err != nil {
+		t.Fatal(err)
+	}
+	syms, _ = QueryWatchGroupSymbols(db)
+	if !reflect.DeepEqual(syms, []string{"300750.SZ"}) {
+		t.Fatalf("members of the deleted group left: %v", syms)
+	}
+	if err := DeleteWatchGroup(db, theme); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("delete missing: %v", err)
+	}
+	if err := UpdateWatchGroup(db, WatchGroup{ID: theme, Name: "x"}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("update missing: %v", err)
+	}
+}
@@ -8,12 +8,18 @@ import (
 )
 
 func UpsertNorthboundRT(db *sql.DB, tsUTC time.Time, nb eastmoney.NorthboundRT) error {
+	return upsertNorthboundRT(db, tsUTC, nb)
+}
+
+func upsertNorthboundRT(db execer, tsUTC time.Time, nb eastmoney.NorthboundRT) error {
 	_, err := db.Exec(`
 		INSERT INTO northbound_rt(
 			ts_utc, trade_date,
 			sh_day_net_amt_in, sh_net_buy_amt, sh_buy_amt, sh_sell_amt,
-			sz_day_net_amt_in, sz_net_buy_amt, sz_buy_amt, sz_sell_amt
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			sz_day_net_amt_in, sz_net_buy_amt, sz_buy_amt, sz_sell_amt,
+			sh_day_amt_remain, sh_day_amt_threshold, sh_buy_sell_amt,
+			sz_day_amt_remain, sz_day_amt_threshold, sz_buy_sell_amt
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(ts_utc) DO UPDATE SET
 			trade_date=excluded.trade_date,
 			sh_day_net_amt_in=excluded.sh_day_net_amt_in,
@@ -23,10 +29,18 @@ func UpsertNorthboundRT(db *sql.DB, tsUTC time.Time, nb eastmoney.NorthboundRT)
 			sz_day_net_amt_in=excluded.sz_day_net_amt_in,
 			sz_net_buy_amt=excluded.sz_net_buy_amt,
 			sz_buy_amt=excluded.sz_buy_amt,
-			sz_sell_amt=excluded.sz_sell_amt
+			sz_sell_amt=excluded.sz_sell_amt,
+			sh_day_amt_remain=excluded.sh_day_amt_remain,
+			sh_day_amt_threshold=excluded.sh_day_amt_threshold,
+			sh_buy_sell_amt=excluded.sh_buy_sell_amt,
+			sz_day_amt_remain=excluded.sz_day_amt_remain,
+			sz_day_amt_threshold=excluded.sz_day_amt_threshold,
+			sz_buy_sell_amt=excluded.sz_buy_sell_amt
 	`, fixedRFC3339Nano(tsUTC), nb.TradeDate,
 		nb.SH.DayNetAmtIn, nb.SH.NetBuyAmt, nb.SH.BuyAmt, nb.SH.SellAmt,
-		nb.SZ.DayNetAmtIn, nb.SZ.NetBuyAmt, nb.SZ.BuyAmt, nb.SZ.SellAmt)
+		nb.SZ.DayNetAmtIn, nb.SZ.NetBuyAmt, nb.SZ.BuyAmt, nb.SZ.SellAmt,
+		nb.SH.DayAmtRemain, nb.SH.DayAmtThreshold, nb.SH.BuySellAmt,
+		nb.SZ.DayAmtRemain, nb.SZ.DayAmtThreshold, nb.SZ.BuySellAmt)
 	return err
 }
 
@@ -35,8 +49,9 @@ func UpsertNorthboundDaily(db *sql.DB, tradeDate string, nb eastmoney.Northbound
 		INSERT INTO northbound_daily(
 			trade_date,
 			sh_day_net_amt_in, sh_net_buy_amt, sh_buy_amt, sh_sell_amt,
-			sz_day_net_amt_in, sz_net_buy_amt, sz_buy_amt, sz_sell_amt
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+			sz_day_net_amt_in, sz_net_buy_amt, sz_buy_amt, sz_sell_amt,
+			sh_buy_sell_amt, sz_buy_sell_amt
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(trade_date) DO UPDATE SET
 			sh_day_net_amt_in=excluded.sh_day_net_amt_in,
 			sh_net_buy_amt=excluded.sh_net_buy_amt,
@@ -45,23 +60,24 @@ func UpsertNorthboundDaily(db *sql.DB, tradeDate string, nb eastmoney.Northbound
 			sz_day_net_amt_in=excluded.sz_day_net_amt_in,
 			sz_net_buy_amt=excluded.sz_net_buy_amt,
 			sz_buy_amt=excluded.sz_buy_amt,
-			sz_sell_amt=excluded.sz_sell_amt
+			sz_sell_amt=excluded.sz_sell_amt,
+			sh_buy_sell_amt=excluded.sh_buy_sell_amt,
+			sz_buy_sell_amt=excluded.sz_buy_sell_amt
 	`, tradeDate,
 		nb.SH.DayNetAmtIn, nb.SH.NetBuyAmt, nb.SH.BuyAmt, nb.SH.SellAmt,
-		nb.SZ.DayNetAmtIn, nb.SZ.NetBuyAmt, nb.SZ.BuyAmt, nb.SZ.SellAmt)
+		nb.SZ.DayNetAmtIn, nb.SZ.NetBuyAmt, nb.SZ.BuyAmt, nb.SZ.SellAmt,
+		nb.SH.BuySellAmt, nb.SZ.BuySellAmt)
 	return err
 }
 
 func UpsertFundflowRT(db *sql.DB, tsUTC time.Time, rows []eastmoney.FundflowRT) error {
+	return withTx(db, func(tx *sql.Tx) error { return upsertFundflowRT(tx, tsUTC, rows) })
+}
+
+func upsertFundflowRT(tx *sql.Tx, tsUTC time.Time, rows []eastmoney.FundflowRT) error {
 	if len(rows) == 0 {
 		return nil
 	}
-	tx, err := db.Begin()
-	if err != nil {
-		return err
-	}
-	defer func() { _ = tx.Rollback() }()
-
 	stmt, err := tx.Prepare(`
 		INSERT INTO fundflow_rt(ts_utc, code, name, net_main, net_xl, net_l, net_m, net_s)
 		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
@@ -84,7 +100,7 @@ func UpsertFundflowRT(db *sql.DB, tsUTC time.Time, rows []eastmoney.FundflowRT)
 			return err
 		}
 	}
-	return tx.Commit()
+	return nil
 }
 
 func UpsertFundflowDaily(db *sql.DB, tradeDate string, row eastmoney.FundflowDaily) error {
@@ -104,15 +120,13 @@ func UpsertFundflowDaily(db *sql.DB, tradeDate string, row eastmoney.FundflowDai
 }
 
 func UpsertTopListRT(db *sql.DB, tsUTC time.Time, fid string, rows []eastmoney.TopItem) error {
+	return withTx(db, func(tx *sql.Tx) error { return upsertTopListRT(tx, tsUTC, fid, rows) })
+}
+
+func upsertTopListRT(tx *sql.Tx, tsUTC time.Time, fid string, rows []eastmoney.TopItem) error {
 	if len(rows) == 0 {
 		return nil
 	}
-	tx, err := db.Begin()
-	if err != nil {
-		return err
-	}
-	defer func() { _ = tx.Rollback() }()
-
 	stmt, err := tx.Prepare(`
 		INSERT INTO toplist_rt(ts_utc, fid, rank, code, name, price, pct, value)
 		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
@@ -134,21 +148,23 @@ func UpsertTopListRT(db *sql.DB, tsUTC time.Time, fid string, rows []eastmoney.T
 			return err
 		}
 	}
-	return tx.Commit()
+	return nil
 }
 
 func UpsertBoardRT(db *sql.DB, tsUTC time.Time, boardType, fid string, rows []eastmoney.TopItem) error {
+	return withTx(db, func(tx *sql.Tx) error { return upsertBoardRT(tx, tsUTC, boardType, fid, rows) })
+}
+
+func upsertBoardRT(tx *sql.Tx, tsUTC time.Time, boardType, fid string, rows []eastmoney.TopItem) error {
 	if len(rows) == 0 {
 		return nil
 	}
-	tx, err := db.Begin()
-	if err != nil {
+	p := partitionFor("board_rt", tsUTC)
+	if err := ensurePartition(tx, "board_rt", p); err != nil {
 		return err
 	}
-	defer func() { _ = tx.Rollback() }()
-
 	stmt, err := tx.Prepare(`
-		INSERT INTO board_rt(ts_utc, board_type, fid, code, name, price, pct, value)
+		INSERT INTO ` + p.Table + `(ts_utc, board_type, fid, code, name, price, pct, value)
 		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(ts_utc, board_type, fid, code) DO UPDATE SET
 			name=excluded.name,
@@ -167,7 +183,7 @@ func UpsertBoardRT(db *sql.DB, tsUTC time.Time, boardType, fid string, rows []ea
 			return err
 		}
 	}
-	return tx.Commit()
+	return nil
 }
 
 func UpsertBoardDaily(db *sql.DB, tradeDate, boardType, fid string, rows []eastmoney.TopItem) error {
@@ -203,6 +219,10 @@ func UpsertBoardDaily(db *sql.DB, tradeDate, boardType, fid string, rows []eastm
 }
 
 func UpsertMarketAggRT(db *sql.DB, tsUTC time.Time, source, fid string, value float64) error {
+	return upsertMarketAggRT(db, tsUTC, source, fid, value)
+}
+
+func upsertMarketAggRT(db execer, tsUTC time.Time, source, fid string, value float64) error {
 	_, err := db.Exec(`
 		INSERT INTO market_agg_rt(ts_utc, source, fid, value)
 		VALUES (?, ?, ?, ?)
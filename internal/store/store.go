@@ -0,0 +1,359 @@
+// Package store is the persistence boundary of the collector and the web server.
+//
+// The SQL itself lives in internal/store/sqlite as package functions on *sql.DB and is kept
+// portable; Store binds those functions to one database and adds what differs per backend
+// (opening, migrations, backups). SQLite is the default; Postgres lets several analysts
+// query one shared database.
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/config"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/eastmoney"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/store/postgres"
+	"github.com/pcdogyu/A-Stock-Order-Flow/internal/store/sqlite"
+)
+
+// Store covers every write and query used by the collector and cmd/aof.
+// Methods mirror the sqlite package functions of the same name.
+type Store interface {
+	// Driver is "sqlite" or "postgres".
+	Driver() string
+	// Migrate applies pending schema migrations. backup is the copy made first, if any.
+	Migrate() (backup string, err error)
+	MigrationStatus() ([]sqlite.MigrationState, error)
+	// Backup writes a consistent copy of the live database to path (which must not exist yet).
+	Backup(path string) error
+	Close() error
+
+	// Realtime snapshots.
+	WriteRTSnapshot(tsUTC time.Time, w sqlite.RTWrite) (id int64, err error)
+	UpsertAuctionRT(tsUTC time.Time, tradeDate, phase, source string, rows []eastmoney.AuctionItem) error
+
+	LoadLatestRTSnapshot() (snap sqlite.RTSnapshot, ok bool, err error)
+	QueryRTTimestamps(table, startUTC, endUTC string) ([]string, error)
+	QueryNorthboundRTAt(tsUTC string) (*eastmoney.NorthboundRT, error)
+	QueryFundflowRTAt(tsUTC string) ([]eastmoney.FundflowRT, error)
+	QueryBoardsRTAt(tsUTC string) (map[string][]eastmoney.TopItem, error)
+	QueryMarketAggRTAt(tsUTC string) (map[string]float64, error)
+	QueryBoardRTLatest(boardType, fid string, limit int) (string, []eastmoney.TopItem, error)
+	QueryBoardRTLatestTimestampByCode(code string) (string, error)
+	QueryBoardRTSeriesByCode(code, startUTC, endUTC string, limit int) ([]sqlite.BoardRTPoint, error)
+	QueryBoardSumRT(boardType, fid string, limit int) ([]sqlite.BoardSumRTPoint, error)
+	QueryBoardPriceSumRT(boardType, fid, startUTC, endUTC string, limit int) ([]sqlite.BoardSumRTPoint, error)
+	QueryMarketAggRT(source, fid string, limit int) ([]sqlite.MarketAggRTPoint, error)
+	QueryFundflowVelocity(window time.Duration, source, orderBy string, limit int) (string, []sqlite.FlowVelocity, error)
+	QueryAuctionRank(tradeDate, phase, source, orderBy string, limit int) (string, []sqlite.AuctionRow, error)
+	QueryAuctionLatestTradeDate(phase string) (string, error)
+	QueryBars(period, dataset, key, field, startUTC, endUTC string) ([]sqlite.Bar, error)
+
+	// Daily snapshots.
+	UpsertNorthboundDaily(tradeDate string, nb eastmoney.NorthboundRT) error
+	UpsertFundflowDaily(tradeDate string, row eastmoney.FundflowDaily) error
+	UpsertBoardDaily(tradeDate, boardType, fid string, rows []eastmoney.TopItem) error
+	UpsertBoardDailySeries(boardType, fid string, points []sqlite.BoardDailySeriesPoint) error
+	UpsertMarketAggDaily(tradeDate, source, fid string, value float64) error
+	UpsertMarginDaily(tradeDate string, row eastmoney.MarginDaily) error
+
+	QueryBoardDailyByCode(boardType, fid, code string, limit int) ([]sqlite.BoardDailyPoint, string, error)
+	QueryBoardSumDaily(boardType, fid string, limit int) ([]sqlite.BoardSumDailyPoint, error)
+	QueryMarketAggDaily(source, fid string, limit int) ([]sqlite.MarketAggDailyPoint, error)
+	QueryExport(q sqlite.ExportQuery) (sqlite.ExportTable, error)
+	QueryFundflowDailyByCode(code string, limit int) ([]sqlite.FundflowDailyPoint, string, error)
+	QueryMarginDailyByCode(code string, limit int) ([]sqlite.MarginDailyPoint, error)
+	QueryToplistAppearances(code, sinceUTC string) ([]sqlite.ToplistAppearance, error)
+
+	// Daily job runs.
+	CreateDailyRun(tradeDate, trigger string, startedAt time.Time, total int) (int64, error)
+	UpsertDailyRunItem(runID int64, dataset, key, status, errMsg string, at time.Time) error
+	FinishDailyRun(runID int64, finishedAt time.Time) error
+	QueryDailyRuns(tradeDate string, limit int) ([]sqlite.DailyRun, error)
+	QueryDailyRunItems(runID int64) ([]sqlite.DailyRunItem, error)
+	QueryDailyItemStatus(tradeDate string) ([]sqlite.DailyRunItem, error)
+
+	// Watch groups.
+	CreateWatchGroup(g sqlite.WatchGroup) (int64, error)
+	UpdateWatchGroup(g sqlite.WatchGroup) error
+	DeleteWatchGroup(id int64) error
+	QueryWatchGroups() ([]sqlite.WatchGroup, error)
+	QueryWatchGroupSymbols() ([]string, error)
+
+	// Security master (search).
+	ReplaceSecurities(kinds []string, rows []sqlite.Security, updatedUTC time.Time) error
+	QuerySecuritiesUpdated() (int, string, error)
+	SearchSecurities(q string, kinds []string, limit int) ([]sqlite.Security, error)
+
+	// Housekeeping.
+	RollupRT(nowUTC time.Time) error
+	CleanupOldData(nowUTC time.Time, ret sqlite.Retention) error
+}
+
+// Open connects to the backend selected by cfg.DBDriver. Call Migrate before use.
+func Open(cfg config.Config) (Store, error) {
+	switch cfg.DBDriver {
+	case "", "sqlite":
+		db, err := sqlite.Open(cfg.DBPath)
+		if err != nil {
+			return nil, err
+		}
+		rdb, err := sqlite.OpenReader(cfg.DBPath)
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+		return &sqliteStore{sqlStore{db, rdb}, cfg.DBPath}, nil
+	case "postgres":
+		db, err := postgres.Open(cfg.DBDSN)
+		if err != nil {
+			return nil, err
+		}
+		if err := db.Ping(); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("postgres: %w", err)
+		}
+		return &postgresStore{sqlStore{db, db}}, nil
+	default:
+		return nil, fmt.Errorf("unknown db_driver: %q", cfg.DBDriver)
+	}
+}
+
+type sqliteStore struct {
+	sqlStore
+	path string
+}
+
+func (s *sqliteStore) Driver() string { return "sqlite" }
+
+func (s *sqliteStore) Migrate() (string, error) { return sqlite.MigrateWithBackup(s.db, s.path) }
+
+func (s *sqliteStore) MigrationStatus() ([]sqlite.MigrationState, error) {
+	return sqlite.MigrationStatus(s.db)
+}
+
+func (s *sqliteStore) Backup(path string) error { return sqlite.BackupTo(s.db, path) }
+
+// postgresStore leaves backups to pg_dump; migrations run in transactions, so a failed one rolls back.
+type postgresStore struct {
+	sqlStore
+}
+
+func (s *postgresStore) Driver() string { return "postgres" }
+
+func (s *postgresStore) Migrate() (string, error) { return "", postgres.Migrate(s.db) }
+
+func (s *postgresStore) MigrationStatus() ([]sqlite.MigrationState, error) {
+	return postgres.MigrationStatus(s.db)
+}
+
+func (s *postgresStore) Backup(path string) error {
+	return fmt.Errorf("backup is not supported for postgres; use pg_dump")
+}
+
+// sqlStore binds the shared sqlite package queries to one database.
+// Writes go through db and queries through rdb; for SQLite these are the single writer
+// connection and a query-only pool, for Postgres the same pool.
+type sqlStore struct {
+	db  *sql.DB
+	rdb *sql.DB
+}
+
+func (s sqlStore) Close() error {
+	err := s.db.Close()
+	if s.rdb != s.db {
+		if rerr := s.rdb.Close(); err == nil {
+			err = rerr
+		}
+	}
+	return err
+}
+
+func (s sqlStore) WriteRTSnapshot(tsUTC time.Time, w sqlite.RTWrite) (int64, error) {
+	return sqlite.WriteRTSnapshot(s.db, tsUTC, w)
+}
+
+func (s sqlStore) UpsertAuctionRT(tsUTC time.Time, tradeDate, phase, source string, rows []eastmoney.AuctionItem) error {
+	return sqlite.UpsertAuctionRT(s.db, tsUTC, tradeDate, phase, source, rows)
+}
+
+func (s sqlStore) LoadLatestRTSnapshot() (sqlite.RTSnapshot, bool, error) {
+	return sqlite.LoadLatestRTSnapshot(s.rdb)
+}
+
+func (s sqlStore) QueryRTTimestamps(table, startUTC, endUTC string) ([]string, error) {
+	return sqlite.QueryRTTimestamps(s.rdb, table, startUTC, endUTC)
+}
+
+func (s sqlStore) QueryNorthboundRTAt(tsUTC string) (*eastmoney.NorthboundRT, error) {
+	return sqlite.QueryNorthboundRTAt(s.rdb, tsUTC)
+}
+
+func (s sqlStore) QueryFundflowRTAt(tsUTC string) ([]eastmoney.FundflowRT, error) {
+	return sqlite.QueryFundflowRTAt(s.rdb, tsUTC)
+}
+
+func (s sqlStore) QueryBoardsRTAt(tsUTC string) (map[string][]eastmoney.TopItem, error) {
+	return sqlite.QueryBoardsRTAt(s.rdb, tsUTC)
+}
+
+func (s sqlStore) QueryMarketAggRTAt(tsUTC string) (map[string]float64, error) {
+	return sqlite.QueryMarketAggRTAt(s.rdb, tsUTC)
+}
+
+func (s sqlStore) QueryBoardRTLatest(boardType, fid string, limit int) (string, []eastmoney.TopItem, error) {
+	return sqlite.QueryBoardRTLatest(s.rdb, boardType, fid, limit)
+}
+
+func (s sqlStore) QueryBoardRTLatestTimestampByCode(code string) (string, error) {
+	return sqlite.QueryBoardRTLatestTimestampByCode(s.rdb, code)
+}
+
+func (s sqlStore) QueryBoardRTSeriesByCode(code, startUTC, endUTC string, limit int) ([]sqlite.BoardRTPoint, error) {
+	return sqlite.QueryBoardRTSeriesByCode(s.rdb, code, startUTC, endUTC, limit)
+}
+
+func (s sqlStore) QueryBoardSumRT(boardType, fid string, limit int) ([]sqlite.BoardSumRTPoint, error) {
+	return sqlite.QueryBoardSumRT(s.rdb, boardType, fid, limit)
+}
+
+func (s sqlStore) QueryBoardPriceSumRT(boardType, fid, startUTC, endUTC string, limit int) ([]sqlite.BoardSumRTPoint, error) {
+	return sqlite.QueryBoardPriceSumRT(s.rdb, boardType, fid, startUTC, endUTC, limit)
+}
+
+func (s sqlStore) QueryMarketAggRT(source, fid string, limit int) ([]sqlite.MarketAggRTPoint, error) {
+	return sqlite.QueryMarketAggRT(s.rdb, source, fid, limit)
+}
+
+func (s sqlStore) QueryFundflowVelocity(window time.Duration, source, orderBy string, limit int) (string, []sqlite.FlowVelocity, error) {
+	return sqlite.QueryFundflowVelocity(s.rdb, window, source, orderBy, limit)
+}
+
+func (s sqlStore) QueryAuctionRank(tradeDate, phase, source, orderBy string, limit int) (string, []sqlite.AuctionRow, error) {
+	return sqlite.QueryAuctionRank(s.rdb, tradeDate, phase, source, orderBy, limit)
+}
+
+func (s sqlStore) QueryAuctionLatestTradeDate(phase string) (string, error) {
+	return sqlite.QueryAuctionLatestTradeDate(s.rdb, phase)
+}
+
+func (s sqlStore) QueryBars(period, dataset, key, field, startUTC, endUTC string) ([]sqlite.Bar, error) {
+	return sqlite.QueryBars(s.rdb, period, dataset, key, field, startUTC, endUTC)
+}
+
+func (s sqlStore) UpsertNorthboundDaily(tradeDate string, nb eastmoney.NorthboundRT) error {
+	return sqlite.UpsertNorthboundDaily(s.db, tradeDate, nb)
+}
+
+func (s sqlStore) UpsertFundflowDaily(tradeDate string, row eastmoney.FundflowDaily) error {
+	return sqlite.UpsertFundflowDaily(s.db, tradeDate, row)
+}
+
+func (s sqlStore) UpsertBoardDaily(tradeDate, boardType, fid string, rows []eastmoney.TopItem) error {
+	return sqlite.UpsertBoardDaily(s.db, tradeDate, boardType, fid, rows)
+}
+
+func (s sqlStore) UpsertBoardDailySeries(boardType, fid string, points []sqlite.BoardDailySeriesPoint) error {
+	return sqlite.UpsertBoardDailySeries(s.db, boardType, fid, points)
+}
+
+func (s sqlStore) UpsertMarketAggDaily(tradeDate, source, fid string, value float64) error {
+	return sqlite.UpsertMarketAggDaily(s.db, tradeDate, source, fid, value)
+}
+
+func (s sqlStore) UpsertMarginDaily(tradeDate string, row eastmoney.MarginDaily) error {
+	return sqlite.UpsertMarginDaily(s.db, tradeDate, row)
+}
+
+func (s sqlStore) QueryBoardDailyByCode(boardType, fid, code string, limit int) ([]sqlite.BoardDailyPoint, string, error) {
+	return sqlite.QueryBoardDailyByCode(s.rdb, boardType, fid, code, limit)
+}
+
+func (s sqlStore) QueryBoardSumDaily(boardType, fid string, limit int) ([]sqlite.BoardSumDailyPoint, error) {
+	return sqlite.QueryBoardSumDaily(s.rdb, boardType, fid, limit)
+}
+
+func (s sqlStore) QueryMarketAggDaily(source, fid string, limit int) ([]sqlite.MarketAggDailyPoint, error) {
+	return sqlite.QueryMarketAggDaily(s.rdb, source, fid, limit)
+}
+
+func (s sqlStore) QueryExport(q sqlite.ExportQuery) (sqlite.ExportTable, error) {
+	return sqlite.QueryExport(s.rdb, q)
+}
+
+func (s sqlStore) QueryFundflowDailyByCode(code string, limit int) ([]sqlite.FundflowDailyPoint, string, error) {
+	return sqlite.QueryFundflowDailyByCode(s.rdb, code, limit)
+}
+
+func (s sqlStore) QueryMarginDailyByCode(code string, limit int) ([]sqlite.MarginDailyPoint, error) {
+	return sqlite.QueryMarginDailyByCode(s.rdb, code, limit)
+}
+
+func (s sqlStore) QueryToplistAppearances(code, sinceUTC string) ([]sqlite.ToplistAppearance, error) {
+	return sqlite.QueryToplistAppearances(s.rdb, code, sinceUTC)
+}
+
+func (s sqlStore) CreateDailyRun(tradeDate, trigger string, startedAt time.Time, total int) (int64, error) {
+	return sqlite.CreateDailyRun(s.db, tradeDate, trigger, startedAt, total)
+}
+
+func (s sqlStore) UpsertDailyRunItem(runID int64, dataset, key, status, errMsg string, at time.Time) error {
+	return sqlite.UpsertDailyRunItem(s.db, runID, dataset, key, status, errMsg, at)
+}
+
+func (s sqlStore) FinishDailyRun(runID int64, finishedAt time.Time) error {
+	return sqlite.FinishDailyRun(s.db, runID, finishedAt)
+}
+
+func (s sqlStore) QueryDailyRuns(tradeDate string, limit int) ([]sqlite.DailyRun, error) {
+	return sqlite.QueryDailyRuns(s.rdb, tradeDate, limit)
+}
+
+func (s sqlStore) QueryDailyRunItems(runID int64) ([]sqlite.DailyRunItem, error) {
+	return sqlite.QueryDailyRunItems(s.rdb, runID)
+}
+
+func (s sqlStore) QueryDailyItemStatus(tradeDate string) ([]sqlite.DailyRunItem, error) {
+	return sqlite.QueryDailyItemStatus(s.rdb, tradeDate)
+}
+
+func (s sqlStore) CreateWatchGroup(g sqlite.WatchGroup) (int64, error) {
+	return sqlite.CreateWatchGroup(s.db, g)
+}
+
+func (s sqlStore) UpdateWatchGroup(g sqlite.WatchGroup) error {
+	return sqlite.UpdateWatchGroup(s.db, g)
+}
+
+func (s sqlStore) DeleteWatchGroup(id int64) error {
+	return sqlite.DeleteWatchGroup(s.db, id)
+}
+
+func (s sqlStore) QueryWatchGroups() ([]sqlite.WatchGroup, error) {
+	return sqlite.QueryWatchGroups(s.rdb)
+}
+
+func (s sqlStore) QueryWatchGroupSymbols() ([]string, error) {
+	return sqlite.QueryWatchGroupSymbols(s.rdb)
+}
+
+func (s sqlStore) ReplaceSecurities(kinds []string, rows []sqlite.Security, updatedUTC time.Time) error {
+	return sqlite.ReplaceSecurities(s.db, kinds, rows, updatedUTC)
+}
+
+func (s sqlStore) QuerySecuritiesUpdated() (int, string, error) {
+	return sqlite.QuerySecuritiesUpdated(s.rdb)
+}
+
+func (s sqlStore) SearchSecurities(q string, kinds []string, limit int) ([]sqlite.Security, error) {
+	return sqlite.SearchSecurities(s.rdb, q, kinds, limit)
+}
+
+func (s sqlStore) RollupRT(nowUTC time.Time) error {
+	return sqlite.RollupRT(s.db, nowUTC)
+}
+
+func (s sqlStore) CleanupOldData(nowUTC time.Time, ret sqlite.Retention) error {
+	return sqlite.CleanupOldData(s.db, nowUTC, ret)
+}
@@ -58,6 +58,33 @@ func ToEastmoneySecIDs(symbols []string) ([]string, error) {
 	return out, nil
 }
 
+// InvalidSymbol is a watchlist entry that could not be mapped to a secid.
+type InvalidSymbol struct {
+	Symbol string `json:"symbol"`
+	Err    string `json:"error"`
+}
+
+// SplitEastmoneySecIDs maps symbols like ToEastmoneySecIDs, but skips and reports bad entries
+// instead of failing the whole list. Duplicate symbols are collapsed.
+func SplitEastmoneySecIDs(symbols []string) ([]string, []InvalidSymbol) {
+	out := make([]string, 0, len(symbols))
+	var invalid []InvalidSymbol
+	seen := make(map[string]struct{}, len(symbols))
+	for _, s := range symbols {
+		secid, err := ToEastmoneySecID(s)
+		if err != nil {
+			invalid = append(invalid, InvalidSymbol{Symbol: s, Err: err.Error()})
+			continue
+		}
+		if _, dup := seen[secid]; dup {
+			continue
+		}
+		seen[secid] = struct{}{}
+		out = append(out, secid)
+	}
+	return out, invalid
+}
+
 // ToEastmoneySecIDFromCode infers market from a bare stock code and returns Eastmoney secid.
 // Heuristic is intended for A-share board constituents.
 func ToEastmoneySecIDFromCode(code string) (string, error) {
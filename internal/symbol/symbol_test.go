@@ -23,3 +23,12 @@ func TestToEastmoneySecIDFromCode(t *testing.T) {
 	}
 }
 
+func TestSplitEastmoneySecIDs(t *testing.T) {
+	secids, invalid := SplitEastmoneySecIDs([]string{"600519.SH", "bad", "000001.SZ", "600519.SH", "1.XX"})
+	if len(secids) != 2 || secids[0] != "1.600519" || secids[1] != "0.000001" {
+		t.Fatalf("secids=%v", secids)
+	}
+	if len(invalid) != 2 || invalid[0].Symbol != "bad" || invalid[1].Symbol != "1.XX" {
+		t.Fatalf("invalid=%v", invalid)
+	}
+}